@@ -0,0 +1,89 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"arkana/config"
+	"arkana/features/posts/services"
+)
+
+// runCommand executes a one-off maintenance command against the database.
+func runCommand(cfg *config.Config, db *sql.DB, name string, args []string) error {
+	switch name {
+	case "sync-posts":
+		return runSyncPosts(cfg, db, args)
+	case "reconcile":
+		return runReconcile(db, args)
+	case "fold-locales":
+		return runFoldLocales(cfg, db, args)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// runSyncPosts ingests the content manifest and prints the sync report.
+func runSyncPosts(cfg *config.Config, db *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("sync-posts", flag.ExitOnError)
+	source := flags.String("source", cfg.ContentManifest, "manifest source: JSON file, sitemap.xml, content directory or URL")
+	dryRun := flags.Bool("dry-run", false, "report changes without writing them")
+	flags.Parse(args)
+
+	syncService := services.NewSyncService(db, *source, cfg.ContentPathPrefix)
+
+	report, err := syncService.SyncFromSource(*dryRun)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Sync complete: %d added, %d changed, %d orphaned, %d unchanged, %d conflicts, %d invalid series parts",
+		len(report.Added), len(report.Changed), len(report.Orphaned), report.Unchanged, len(report.Conflicts), len(report.InvalidParts))
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// runReconcile checks denormalized counters and prints the integrity report.
+func runReconcile(db *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	fix := flags.Bool("fix", false, "correct the discrepancies found")
+	flags.Parse(args)
+
+	report, err := services.NewReconcileService(db).Run(*fix)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Reconcile complete: %d discrepancies (fixed=%v)", len(report.Discrepancies), report.Fixed)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// runFoldLocales folds posts stored under localized paths, which predate
+// shared canonical posts, into their canonical post and prints the report.
+func runFoldLocales(cfg *config.Config, db *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("fold-locales", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report the posts to fold without folding them")
+	flags.Parse(args)
+
+	postService := services.NewPostService(db)
+	postService.SetSupportedLocales(cfg.SupportedLocales)
+
+	report, err := postService.FoldLocalePaths(*dryRun)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Fold complete: %d localized posts folded (dry_run=%v)", len(report.Folded), report.DryRun)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
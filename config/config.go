@@ -2,14 +2,52 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config holds application configuration
 type Config struct {
-	DatabasePath      string `validate:"required" env:"DATABASE_PATH"`
-	CORSAllowedOrigin string `env:"CORS_ALLOWED_ORIGIN"`
+	DatabasePath      string   `validate:"required" env:"DATABASE_PATH"`
+	CORSAllowedOrigin string   `env:"CORS_ALLOWED_ORIGIN"`
+	AdminWallets      []string `env:"ADMIN_WALLETS"`
+	ContentManifest   string   `env:"CONTENT_MANIFEST"`
+	ContentPathPrefix string   `env:"CONTENT_PATH_PREFIX"`
+	SupportedLocales  []string `env:"SUPPORTED_LOCALES"`
+	// TrustProxy takes client IPs from X-Forwarded-For; enable only behind
+	// a reverse proxy that sets the header
+	TrustProxy bool `env:"TRUST_PROXY"`
+	// AutoCreatePaths are glob patterns (e.g. "wtf-is/*") of unknown post
+	// paths that are registered on their first like, comment or info request
+	AutoCreatePaths        []string `env:"AUTO_CREATE_PATHS"`
+	AutoCreateFromManifest bool     `env:"AUTO_CREATE_FROM_MANIFEST"`
+	// Reactions is the set of emoji readers may react to posts with
+	Reactions []string `env:"REACTIONS"`
+	// CommentEditWindow is how long authors may edit their comments; zero
+	// disables editing
+	CommentEditWindow time.Duration `env:"COMMENT_EDIT_WINDOW"`
+	// CommentReportThreshold is the number of wallets whose reports hide a
+	// comment until a moderator reviews it; zero disables automatic hiding
+	CommentReportThreshold int `env:"COMMENT_REPORT_THRESHOLD"`
+	// ENSRPCURL is an Ethereum JSON-RPC endpoint used to resolve ENS names
+	// mentioned in comments; empty disables ENS mentions
+	ENSRPCURL string `env:"ENS_RPC_URL"`
+	// ReconcileInterval is how often denormalized counters are checked
+	// against their source tables; zero disables the scheduled check
+	ReconcileInterval time.Duration `env:"RECONCILE_INTERVAL"`
+	ReconcileFix      bool          `env:"RECONCILE_FIX"`
+	// RollupInterval is how often engagement rollups are refreshed
+	RollupInterval time.Duration `env:"ROLLUP_INTERVAL"`
+	// TrendingHalfLife is the age at which a like or comment counts half as
+	// much towards the trending score
+	TrendingHalfLife time.Duration `env:"TRENDING_HALF_LIFE"`
+	TrendingRefresh  time.Duration `env:"TRENDING_REFRESH"`
+	// RelatedMinSupport is the number of wallets that must have engaged with
+	// two posts before one is recommended from the other
+	RelatedMinSupport int           `env:"RELATED_MIN_SUPPORT"`
+	RelatedPerSeries  int           `env:"RELATED_PER_SERIES"`
+	RelatedRefresh    time.Duration `env:"RELATED_REFRESH"`
 }
 
 // Load loads configuration from environment variables
@@ -17,6 +55,29 @@ func Load() *Config {
 	return &Config{
 		DatabasePath:      getEnv("DATABASE_PATH", "blog.db"),
 		CORSAllowedOrigin: getEnv("CORS_ALLOWED_ORIGIN", "*"),
+		AdminWallets:      getEnvList("ADMIN_WALLETS", ""),
+		ContentManifest:   getEnv("CONTENT_MANIFEST", ""),
+		ContentPathPrefix: getEnv("CONTENT_PATH_PREFIX", "blog/"),
+		SupportedLocales:  getEnvList("SUPPORTED_LOCALES", "en,es"),
+		TrustProxy:        getEnvBool("TRUST_PROXY", false),
+
+		AutoCreatePaths:        getEnvList("AUTO_CREATE_PATHS", ""),
+		AutoCreateFromManifest: getEnvBool("AUTO_CREATE_FROM_MANIFEST", false),
+
+		Reactions: getEnvList("REACTIONS", "👍,🤯,🧠,❓"),
+
+		CommentEditWindow:      getEnvDuration("COMMENT_EDIT_WINDOW", 15*time.Minute),
+		CommentReportThreshold: getEnvInt("COMMENT_REPORT_THRESHOLD", 3),
+		ENSRPCURL:              getEnv("ENS_RPC_URL", ""),
+
+		ReconcileInterval: getEnvDuration("RECONCILE_INTERVAL", time.Hour),
+		ReconcileFix:      getEnvBool("RECONCILE_FIX", false),
+		RollupInterval:    getEnvDuration("ROLLUP_INTERVAL", 15*time.Minute),
+		TrendingHalfLife:  getEnvDuration("TRENDING_HALF_LIFE", 24*time.Hour),
+		TrendingRefresh:   getEnvDuration("TRENDING_REFRESH", 10*time.Minute),
+		RelatedMinSupport: getEnvInt("RELATED_MIN_SUPPORT", 2),
+		RelatedPerSeries:  getEnvInt("RELATED_PER_SERIES", 2),
+		RelatedRefresh:    getEnvDuration("RELATED_REFRESH", time.Hour),
 	}
 }
 
@@ -1,6 +1,11 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // getEnv gets an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
@@ -9,3 +14,45 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvList splits a comma-separated environment variable into trimmed,
+// non-empty values, falling back to defaultValue when unset
+func getEnvList(key, defaultValue string) []string {
+	var values []string
+	for _, v := range strings.Split(getEnv(key, defaultValue), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// getEnvBool parses a boolean environment variable, falling back to
+// defaultValue when unset or invalid
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvDuration parses a duration environment variable such as "30m",
+// falling back to defaultValue when unset or invalid. "0" is a valid value.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvInt parses an integer environment variable, falling back to
+// defaultValue when unset or invalid
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
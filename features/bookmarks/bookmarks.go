@@ -0,0 +1,17 @@
+package bookmarks
+
+import (
+	"arkana/features/bookmarks/handlers"
+	"arkana/features/bookmarks/services"
+	postservices "arkana/features/posts/services"
+	"arkana/features/wallet/middlewares"
+	"database/sql"
+
+	"github.com/gorilla/mux"
+)
+
+func Initialize(router *mux.Router, db *sql.DB, ps *postservices.PostService, auth *middlewares.AuthMiddleware) {
+	bookmarkService := services.NewBookmarkService(db)
+
+	handlers.RegisterRoutes(router, ps, bookmarkService, auth)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"arkana/features/bookmarks/models"
+	"arkana/features/bookmarks/services"
+	postmodels "arkana/features/posts/models"
+	postservices "arkana/features/posts/services"
+	"arkana/features/wallet/middlewares"
+	"arkana/shared/httputil"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type BookmarkHandler struct {
+	postService     *postservices.PostService
+	bookmarkService *services.BookmarkService
+}
+
+func NewBookmarkHandler(ps *postservices.PostService, bs *services.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{postService: ps, bookmarkService: bs}
+}
+
+// AddBookmark handles PUT /api/posts/{path}/bookmark, signed with the
+// BOOKMARK action.
+func (h *BookmarkHandler) AddBookmark(w http.ResponseWriter, r *http.Request) {
+	h.setBookmark(w, r, true)
+}
+
+// RemoveBookmark handles DELETE /api/posts/{path}/bookmark, signed with the
+// REMOVE_BOOKMARK action.
+func (h *BookmarkHandler) RemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	h.setBookmark(w, r, false)
+}
+
+func (h *BookmarkHandler) setBookmark(w http.ResponseWriter, r *http.Request, bookmarked bool) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	action := "REMOVE_BOOKMARK"
+	if bookmarked {
+		action = "BOOKMARK"
+	}
+	if vr.Action != action {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	find := h.postService.GetByPath
+	if bookmarked {
+		find = h.postService.GetOrAutoCreate
+	}
+	post, ok := resolvePost(w, find, mux.Vars(r)["path"])
+	if !ok {
+		return
+	}
+
+	var err error
+	if bookmarked {
+		err = h.bookmarkService.Add(vr.WalletID, post.ID)
+	} else {
+		err = h.bookmarkService.Remove(vr.WalletID, post.ID)
+	}
+	if err != nil {
+		log.Printf("[Bookmarks] Failed to update bookmark of wallet %d on post %d: %v", vr.WalletID, post.ID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to update bookmark")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, models.BookmarkStatusResponse{
+		Path:       post.PathIdentifier,
+		Bookmarked: bookmarked,
+	})
+}
+
+// ListBookmarks handles GET /api/me/bookmarks?limit=&offset=, signed with the
+// GET_BOOKMARKS action.
+func (h *BookmarkHandler) ListBookmarks(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "GET_BOOKMARKS" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	limit, offset, err := httputil.ParsePagination(r, 50, 200)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bookmarks, err := h.bookmarkService.List(vr.WalletID, limit, offset)
+	if err != nil {
+		log.Printf("[Bookmarks] Failed to list bookmarks of wallet %d: %v", vr.WalletID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to list bookmarks")
+		return
+	}
+	lists, err := h.bookmarkService.Lists(vr.WalletID)
+	if err != nil {
+		log.Printf("[Bookmarks] Failed to list reading lists of wallet %d: %v", vr.WalletID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to list bookmarks")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, models.BookmarksResponse{Bookmarks: bookmarks, Lists: lists})
+}
+
+// resolvePost looks up a post with find, writing an error response and
+// returning false when it cannot be resolved.
+func resolvePost(w http.ResponseWriter, find func(string) (*postmodels.Post, error), path string) (*postmodels.Post, bool) {
+	post, err := find(path)
+	if err != nil {
+		if errors.Is(err, postservices.ErrPostNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "post not found")
+			return nil, false
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to resolve post")
+		return nil, false
+	}
+	return post, true
+}
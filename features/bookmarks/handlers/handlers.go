@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"arkana/features/bookmarks/services"
+	postservices "arkana/features/posts/services"
+	"arkana/features/wallet/middlewares"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterRoutes(router *mux.Router, ps *postservices.PostService, bs *services.BookmarkService, auth *middlewares.AuthMiddleware) {
+	bookmarkHandler := NewBookmarkHandler(ps, bs)
+	listHandler := NewListHandler(ps, bs)
+
+	signed := func(h http.HandlerFunc) http.Handler {
+		return auth.RequireAuth(h)
+	}
+
+	router.Handle("/api/me/bookmarks", signed(bookmarkHandler.ListBookmarks)).Methods("GET", "OPTIONS")
+	router.Handle("/api/posts/{path:.*}/bookmark", signed(bookmarkHandler.AddBookmark)).Methods("PUT", "OPTIONS")
+	router.Handle("/api/posts/{path:.*}/bookmark", signed(bookmarkHandler.RemoveBookmark)).Methods("DELETE")
+
+	router.Handle("/api/me/lists", signed(listHandler.CreateList)).Methods("POST", "OPTIONS")
+	router.Handle("/api/me/lists/{id:[0-9]+}", signed(listHandler.GetList)).Methods("GET", "OPTIONS")
+	router.Handle("/api/me/lists/{id:[0-9]+}", signed(listHandler.UpdateList)).Methods("PUT")
+	router.Handle("/api/me/lists/{id:[0-9]+}", signed(listHandler.DeleteList)).Methods("DELETE")
+	router.Handle("/api/me/lists/{id:[0-9]+}/items/{path:.*}", signed(listHandler.AddItem)).Methods("PUT", "OPTIONS")
+	router.Handle("/api/me/lists/{id:[0-9]+}/items/{path:.*}", signed(listHandler.RemoveItem)).Methods("DELETE")
+	router.HandleFunc("/api/lists/{token}", listHandler.GetSharedList).Methods("GET", "OPTIONS")
+}
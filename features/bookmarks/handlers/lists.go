@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"arkana/features/bookmarks/services"
+	postservices "arkana/features/posts/services"
+	"arkana/features/wallet/middlewares"
+	"arkana/shared/httputil"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type ListHandler struct {
+	postService     *postservices.PostService
+	bookmarkService *services.BookmarkService
+}
+
+func NewListHandler(ps *postservices.PostService, bs *services.BookmarkService) *ListHandler {
+	return &ListHandler{postService: ps, bookmarkService: bs}
+}
+
+// CreateList handles POST /api/me/lists, signed with the CREATE_LIST action.
+func (h *ListHandler) CreateList(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "CREATE_LIST" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	list, err := h.bookmarkService.CreateList(vr.WalletID, payload.Name)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusCreated, list)
+}
+
+// GetList handles GET /api/me/lists/{id}, signed with the GET_LIST action.
+func (h *ListHandler) GetList(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "GET_LIST" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	list, err := h.bookmarkService.GetList(vr.WalletID, listID(r))
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, list)
+}
+
+// UpdateList handles PUT /api/me/lists/{id}, signed with the UPDATE_LIST
+// action. The payload may rename the list, share it ("public") and reorder
+// it ("order", every path once).
+func (h *ListHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "UPDATE_LIST" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		Name   *string  `json:"name,omitempty"`
+		Public *bool    `json:"public,omitempty"`
+		Order  []string `json:"order,omitempty"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	update := services.ListUpdate{Name: payload.Name, Public: payload.Public}
+	if payload.Order != nil {
+		update.Order = make([]int, 0, len(payload.Order))
+		for _, path := range payload.Order {
+			post, err := h.postService.GetByPath(path)
+			if err != nil {
+				writeListError(w, services.ErrInvalidOrder)
+				return
+			}
+			update.Order = append(update.Order, post.ID)
+		}
+	}
+
+	list, err := h.bookmarkService.UpdateList(vr.WalletID, listID(r), update)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, list)
+}
+
+// DeleteList handles DELETE /api/me/lists/{id}, signed with the DELETE_LIST
+// action.
+func (h *ListHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "DELETE_LIST" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	if err := h.bookmarkService.DeleteList(vr.WalletID, listID(r)); err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddItem handles PUT /api/me/lists/{id}/items/{path}, signed with the
+// ADD_TO_LIST action.
+func (h *ListHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "ADD_TO_LIST" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	post, ok := resolvePost(w, h.postService.GetOrAutoCreate, mux.Vars(r)["path"])
+	if !ok {
+		return
+	}
+
+	list, err := h.bookmarkService.AddToList(vr.WalletID, listID(r), post.ID)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, list)
+}
+
+// RemoveItem handles DELETE /api/me/lists/{id}/items/{path}, signed with the
+// REMOVE_FROM_LIST action.
+func (h *ListHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "REMOVE_FROM_LIST" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	post, ok := resolvePost(w, h.postService.GetByPath, mux.Vars(r)["path"])
+	if !ok {
+		return
+	}
+
+	list, err := h.bookmarkService.RemoveFromList(vr.WalletID, listID(r), post.ID)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, list)
+}
+
+// GetSharedList handles GET /api/lists/{token}, the public URL of a shared list
+func (h *ListHandler) GetSharedList(w http.ResponseWriter, r *http.Request) {
+	list, err := h.bookmarkService.GetShared(mux.Vars(r)["token"])
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, list)
+}
+
+// listID reads the list ID from the URL; the route only matches digits.
+func listID(r *http.Request) int {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	return id
+}
+
+func writeListError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrListNotFound):
+		httputil.WriteError(w, http.StatusNotFound, "reading list not found")
+	case errors.Is(err, services.ErrNotInList):
+		httputil.WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrListNameInvalid):
+		httputil.WriteError(w, http.StatusBadRequest, fmt.Sprintf("list name must be 1 to %d characters", services.MaxListNameLength))
+	case errors.Is(err, services.ErrListNameTaken):
+		httputil.WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrTooManyLists):
+		httputil.WriteError(w, http.StatusConflict, fmt.Sprintf("a wallet may have at most %d reading lists", services.MaxListsPerWallet))
+	case errors.Is(err, services.ErrListFull):
+		httputil.WriteError(w, http.StatusConflict, fmt.Sprintf("a reading list may hold at most %d posts", services.MaxListItems))
+	case errors.Is(err, services.ErrInvalidOrder):
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("[Lists] Failed to handle reading list request: %v", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to update reading list")
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+type Bookmark struct {
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BookmarkStatusResponse struct {
+	Path       string `json:"path"`
+	Bookmarked bool   `json:"bookmarked"`
+}
+
+// ReadingList is a named, ordered list of posts owned by a wallet. A list
+// is public when it has a share token, which is part of its shared URL.
+type ReadingList struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	Public     bool      `json:"public"`
+	ShareToken string    `json:"share_token,omitempty"`
+	ItemCount  int       `json:"item_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ReadingListItem struct {
+	Path     string    `json:"path"`
+	Position int       `json:"position"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+type ReadingListResponse struct {
+	ReadingList
+	Items []ReadingListItem `json:"items"`
+}
+
+// SharedListResponse is a public list as seen through its share URL,
+// without anything identifying its owner.
+type SharedListResponse struct {
+	Name  string            `json:"name"`
+	Items []ReadingListItem `json:"items"`
+}
+
+type BookmarksResponse struct {
+	Bookmarks []Bookmark    `json:"bookmarks"`
+	Lists     []ReadingList `json:"lists"`
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"arkana/features/bookmarks/models"
+	"database/sql"
+)
+
+// BookmarkService stores the bookmarks and reading lists of wallets.
+type BookmarkService struct {
+	db *sql.DB
+}
+
+func NewBookmarkService(db *sql.DB) *BookmarkService {
+	return &BookmarkService{db: db}
+}
+
+// Add bookmarks a post for a wallet. Bookmarking twice is a no-op.
+func (s *BookmarkService) Add(walletID, postID int) error {
+	_, err := s.db.Exec("INSERT OR IGNORE INTO bookmarks (wallet_id, post_id) VALUES (?, ?)", walletID, postID)
+	return err
+}
+
+// Remove deletes a bookmark. Removing a missing bookmark is a no-op.
+func (s *BookmarkService) Remove(walletID, postID int) error {
+	_, err := s.db.Exec("DELETE FROM bookmarks WHERE wallet_id = ? AND post_id = ?", walletID, postID)
+	return err
+}
+
+// List returns a page of a wallet's bookmarks, most recent first.
+func (s *BookmarkService) List(walletID, limit, offset int) ([]models.Bookmark, error) {
+	rows, err := s.db.Query(`
+		SELECT p.path_identifier, b.created_at FROM bookmarks b
+		JOIN posts p ON p.id = b.post_id
+		WHERE b.wallet_id = ?
+		ORDER BY b.created_at DESC, b.rowid DESC
+		LIMIT ? OFFSET ?
+	`, walletID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookmarks := []models.Bookmark{}
+	for rows.Next() {
+		var b models.Bookmark
+		if err := rows.Scan(&b.Path, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		bookmarks = append(bookmarks, b)
+	}
+	return bookmarks, rows.Err()
+}
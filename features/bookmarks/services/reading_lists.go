@@ -0,0 +1,367 @@
+package services
+
+import (
+	"arkana/features/bookmarks/models"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxListNameLength = 100
+	MaxListsPerWallet = 50
+	MaxListItems      = 500
+)
+
+var (
+	ErrListNotFound    = errors.New("reading list not found")
+	ErrListNameInvalid = errors.New("invalid list name")
+	ErrListNameTaken   = errors.New("a list with this name already exists")
+	ErrTooManyLists    = errors.New("too many reading lists")
+	ErrListFull        = errors.New("reading list is full")
+	ErrInvalidOrder    = errors.New("order must list every post of the list exactly once")
+	ErrNotInList       = errors.New("post is not in the list")
+)
+
+// CreateList creates an empty private reading list.
+func (s *BookmarkService) CreateList(walletID int, name string) (*models.ReadingList, error) {
+	name, err := checkListName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM reading_lists WHERE wallet_id = ?", walletID).Scan(&count); err != nil {
+		return nil, err
+	}
+	if count >= MaxListsPerWallet {
+		return nil, ErrTooManyLists
+	}
+	if taken, err := s.nameTaken(walletID, name, 0); err != nil {
+		return nil, err
+	} else if taken {
+		return nil, ErrListNameTaken
+	}
+
+	result, err := s.db.Exec("INSERT INTO reading_lists (wallet_id, name) VALUES (?, ?)", walletID, name)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return s.getList("l.id = ?", id)
+}
+
+func checkListName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxListNameLength {
+		return "", ErrListNameInvalid
+	}
+	return name, nil
+}
+
+func (s *BookmarkService) nameTaken(walletID int, name string, exceptID int) (bool, error) {
+	var exists int
+	err := s.db.QueryRow(
+		"SELECT 1 FROM reading_lists WHERE wallet_id = ? AND name = ? AND id != ?",
+		walletID, name, exceptID,
+	).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+const listColumns = `l.id, l.wallet_id, l.name, COALESCE(l.share_token, ''), l.created_at, l.updated_at,
+	(SELECT COUNT(*) FROM reading_list_items i WHERE i.list_id = l.id)`
+
+func scanList(scan func(...any) error) (*models.ReadingList, int, error) {
+	var l models.ReadingList
+	var walletID int
+	if err := scan(&l.ID, &walletID, &l.Name, &l.ShareToken, &l.CreatedAt, &l.UpdatedAt, &l.ItemCount); err != nil {
+		return nil, 0, err
+	}
+	l.Public = l.ShareToken != ""
+	return &l, walletID, nil
+}
+
+func (s *BookmarkService) getList(where string, args ...any) (*models.ReadingList, error) {
+	l, _, err := scanList(s.db.QueryRow("SELECT "+listColumns+" FROM reading_lists l WHERE "+where, args...).Scan)
+	if err == sql.ErrNoRows {
+		return nil, ErrListNotFound
+	}
+	return l, err
+}
+
+// ownList returns a list owned by the wallet. Lists of other wallets are
+// reported as not found.
+func (s *BookmarkService) ownList(walletID, listID int) (*models.ReadingList, error) {
+	return s.getList("l.id = ? AND l.wallet_id = ?", listID, walletID)
+}
+
+// Lists returns every reading list of a wallet, in creation order.
+func (s *BookmarkService) Lists(walletID int) ([]models.ReadingList, error) {
+	rows, err := s.db.Query("SELECT "+listColumns+" FROM reading_lists l WHERE l.wallet_id = ? ORDER BY l.id", walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := []models.ReadingList{}
+	for rows.Next() {
+		l, _, err := scanList(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, *l)
+	}
+	return lists, rows.Err()
+}
+
+// GetList returns a wallet's reading list with its items in order.
+// Returns ErrListNotFound if the wallet does not own the list.
+func (s *BookmarkService) GetList(walletID, listID int) (*models.ReadingListResponse, error) {
+	l, err := s.ownList(walletID, listID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.items(l.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ReadingListResponse{ReadingList: *l, Items: items}, nil
+}
+
+// GetShared returns a public list by its share token.
+// Returns ErrListNotFound if no public list has the token.
+func (s *BookmarkService) GetShared(token string) (*models.SharedListResponse, error) {
+	if token == "" {
+		return nil, ErrListNotFound
+	}
+	l, err := s.getList("l.share_token = ?", token)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.items(l.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SharedListResponse{Name: l.Name, Items: items}, nil
+}
+
+func (s *BookmarkService) items(listID int) ([]models.ReadingListItem, error) {
+	rows, err := s.db.Query(`
+		SELECT p.path_identifier, i.created_at FROM reading_list_items i
+		JOIN posts p ON p.id = i.post_id
+		WHERE i.list_id = ?
+		ORDER BY i.position, i.created_at
+	`, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.ReadingListItem{}
+	for rows.Next() {
+		var item models.ReadingListItem
+		if err := rows.Scan(&item.Path, &item.AddedAt); err != nil {
+			return nil, err
+		}
+		item.Position = len(items) + 1
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// ListUpdate changes a reading list. Nil fields are left unchanged; Order
+// reorders the list and must contain exactly its current posts.
+type ListUpdate struct {
+	Name   *string
+	Public *bool
+	Order  []int
+}
+
+// UpdateList renames, shares or reorders a list. Making a list public
+// gives it a new share token; making it private revokes the token.
+// Returns ErrListNotFound if the wallet does not own the list.
+// Returns ErrInvalidOrder if Order is not a permutation of the list.
+func (s *BookmarkService) UpdateList(walletID, listID int, update ListUpdate) (*models.ReadingListResponse, error) {
+	l, err := s.ownList(walletID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Order != nil {
+		if err := s.checkOrder(l.ID, update.Order); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if update.Name != nil {
+		name, err := checkListName(*update.Name)
+		if err != nil {
+			return nil, err
+		}
+		if taken, err := s.nameTaken(walletID, name, l.ID); err != nil {
+			return nil, err
+		} else if taken {
+			return nil, ErrListNameTaken
+		}
+		if _, err := tx.Exec("UPDATE reading_lists SET name = ? WHERE id = ?", name, l.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if update.Public != nil && *update.Public != l.Public {
+		var token any
+		if *update.Public {
+			if token, err = newShareToken(); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := tx.Exec("UPDATE reading_lists SET share_token = ? WHERE id = ?", token, l.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, postID := range update.Order {
+		_, err := tx.Exec("UPDATE reading_list_items SET position = ? WHERE list_id = ? AND post_id = ?", i+1, l.ID, postID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE reading_lists SET updated_at = CURRENT_TIMESTAMP WHERE id = ?", l.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetList(walletID, l.ID)
+}
+
+// checkOrder verifies that order contains exactly the posts of the list.
+func (s *BookmarkService) checkOrder(listID int, order []int) error {
+	current := map[int]bool{}
+	rows, err := s.db.Query("SELECT post_id FROM reading_list_items WHERE list_id = ?", listID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		current[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(order) != len(current) {
+		return ErrInvalidOrder
+	}
+	for _, id := range order {
+		if !current[id] {
+			return ErrInvalidOrder
+		}
+		delete(current, id)
+	}
+	return nil
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// DeleteList deletes a reading list and its items.
+// Returns ErrListNotFound if the wallet does not own the list.
+func (s *BookmarkService) DeleteList(walletID, listID int) error {
+	l, err := s.ownList(walletID, listID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM reading_list_items WHERE list_id = ?", l.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM reading_lists WHERE id = ?", l.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddToList appends a post to a list. Adding a post already in the list
+// keeps its position.
+// Returns ErrListNotFound if the wallet does not own the list.
+func (s *BookmarkService) AddToList(walletID, listID, postID int) (*models.ReadingListResponse, error) {
+	l, err := s.ownList(walletID, listID)
+	if err != nil {
+		return nil, err
+	}
+	if l.ItemCount >= MaxListItems {
+		return nil, ErrListFull
+	}
+
+	_, err = s.db.Exec(`
+		INSERT OR IGNORE INTO reading_list_items (list_id, post_id, position)
+		SELECT ?1, ?2, COALESCE(MAX(position), 0) + 1 FROM reading_list_items WHERE list_id = ?1
+	`, l.ID, postID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Exec("UPDATE reading_lists SET updated_at = CURRENT_TIMESTAMP WHERE id = ?", l.ID); err != nil {
+		return nil, err
+	}
+
+	return s.GetList(walletID, l.ID)
+}
+
+// RemoveFromList removes a post from a list.
+// Returns ErrListNotFound if the wallet does not own the list and
+// ErrNotInList if the post is not in it.
+func (s *BookmarkService) RemoveFromList(walletID, listID, postID int) (*models.ReadingListResponse, error) {
+	l, err := s.ownList(walletID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Exec("DELETE FROM reading_list_items WHERE list_id = ? AND post_id = ?", l.ID, postID)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrNotInList
+	}
+	if _, err := s.db.Exec("UPDATE reading_lists SET updated_at = CURRENT_TIMESTAMP WHERE id = ?", l.ID); err != nil {
+		return nil, err
+	}
+
+	return s.GetList(walletID, l.ID)
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"arkana/features/bookmarks/services"
+	"errors"
+	"testing"
+)
+
+func TestBookmarks(t *testing.T) {
+	db := setupTestDB(t)
+	svc := services.NewBookmarkService(db)
+	walletID := insertTestWallet(t, db, "0xabc")
+	first := insertTestPost(t, db, "wtf-is/risc-v")
+	second := insertTestPost(t, db, "wtf-is/the-internet")
+
+	svc.Add(walletID, first)
+	svc.Add(walletID, second)
+	if err := svc.Add(walletID, second); err != nil {
+		t.Fatalf("bookmarking twice: %v", err)
+	}
+
+	bookmarks, err := svc.List(walletID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bookmarks) != 2 || bookmarks[0].Path != "wtf-is/the-internet" {
+		t.Errorf("bookmarks = %+v, want the latest first", bookmarks)
+	}
+
+	svc.Remove(walletID, first)
+	if err := svc.Remove(walletID, first); err != nil {
+		t.Fatalf("removing twice: %v", err)
+	}
+	bookmarks, _ = svc.List(walletID, 10, 0)
+	if len(bookmarks) != 1 {
+		t.Errorf("bookmarks = %+v, want 1", bookmarks)
+	}
+}
+
+func TestReadingLists(t *testing.T) {
+	db := setupTestDB(t)
+	svc := services.NewBookmarkService(db)
+	owner := insertTestWallet(t, db, "0xabc")
+	other := insertTestWallet(t, db, "0xdef")
+	posts := []int{
+		insertTestPost(t, db, "a"),
+		insertTestPost(t, db, "b"),
+		insertTestPost(t, db, "c"),
+	}
+
+	list, err := svc.CreateList(owner, " Weekend ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Name != "Weekend" || list.Public {
+		t.Errorf("list = %+v, want a private list named Weekend", list)
+	}
+
+	t.Run("rejects invalid and duplicate names", func(t *testing.T) {
+		if _, err := svc.CreateList(owner, "  "); !errors.Is(err, services.ErrListNameInvalid) {
+			t.Errorf("err = %v, want ErrListNameInvalid", err)
+		}
+		if _, err := svc.CreateList(owner, "Weekend"); !errors.Is(err, services.ErrListNameTaken) {
+			t.Errorf("err = %v, want ErrListNameTaken", err)
+		}
+		if _, err := svc.CreateList(other, "Weekend"); err != nil {
+			t.Errorf("other wallet: %v", err)
+		}
+	})
+
+	t.Run("keeps items in order", func(t *testing.T) {
+		for _, id := range posts {
+			svc.AddToList(owner, list.ID, id)
+		}
+		resp, _ := svc.AddToList(owner, list.ID, posts[0])
+		if len(resp.Items) != 3 || resp.Items[0].Path != "a" || resp.Items[2].Path != "c" {
+			t.Errorf("items = %+v", resp.Items)
+		}
+
+		resp, err := svc.UpdateList(owner, list.ID, services.ListUpdate{Order: []int{posts[2], posts[0], posts[1]}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Items[0].Path != "c" || resp.Items[0].Position != 1 || resp.Items[2].Path != "b" {
+			t.Errorf("items = %+v, want c, a, b", resp.Items)
+		}
+
+		_, err = svc.UpdateList(owner, list.ID, services.ListUpdate{Order: []int{posts[0], posts[0], posts[1]}})
+		if !errors.Is(err, services.ErrInvalidOrder) {
+			t.Errorf("err = %v, want ErrInvalidOrder", err)
+		}
+	})
+
+	t.Run("removes items", func(t *testing.T) {
+		resp, err := svc.RemoveFromList(owner, list.ID, posts[0])
+		if err != nil || len(resp.Items) != 2 || resp.Items[1].Position != 2 {
+			t.Errorf("resp = %+v, err = %v", resp, err)
+		}
+		if _, err := svc.RemoveFromList(owner, list.ID, posts[0]); !errors.Is(err, services.ErrNotInList) {
+			t.Errorf("err = %v, want ErrNotInList", err)
+		}
+	})
+
+	t.Run("shares by token and revokes", func(t *testing.T) {
+		public := true
+		resp, _ := svc.UpdateList(owner, list.ID, services.ListUpdate{Public: &public})
+		if !resp.Public || resp.ShareToken == "" {
+			t.Fatalf("list = %+v, want a share token", resp.ReadingList)
+		}
+		shared, err := svc.GetShared(resp.ShareToken)
+		if err != nil || shared.Name != "Weekend" || len(shared.Items) != 2 {
+			t.Errorf("shared = %+v, err = %v", shared, err)
+		}
+
+		public = false
+		svc.UpdateList(owner, list.ID, services.ListUpdate{Public: &public})
+		if _, err := svc.GetShared(resp.ShareToken); !errors.Is(err, services.ErrListNotFound) {
+			t.Errorf("err = %v, want ErrListNotFound after revoking", err)
+		}
+	})
+
+	t.Run("hides lists of other wallets", func(t *testing.T) {
+		if _, err := svc.GetList(other, list.ID); !errors.Is(err, services.ErrListNotFound) {
+			t.Errorf("err = %v, want ErrListNotFound", err)
+		}
+		if err := svc.DeleteList(other, list.ID); !errors.Is(err, services.ErrListNotFound) {
+			t.Errorf("err = %v, want ErrListNotFound", err)
+		}
+	})
+
+	t.Run("deletes lists with their items", func(t *testing.T) {
+		if err := svc.DeleteList(owner, list.ID); err != nil {
+			t.Fatal(err)
+		}
+		var n int
+		db.QueryRow("SELECT COUNT(*) FROM reading_list_items WHERE list_id = ?", list.ID).Scan(&n)
+		if n != 0 {
+			t.Errorf("items = %d, want 0", n)
+		}
+	})
+}
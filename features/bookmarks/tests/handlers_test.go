@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"arkana/features/bookmarks/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBookmarkHandlers(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	key, addr := generateTestKey(t)
+	insertTestWallet(t, db, addr)
+	insertTestPost(t, db, "first-post")
+	insertTestPost(t, db, "second-post")
+
+	signed := func(method, url string, payload map[string]any) *httptest.ResponseRecorder {
+		jws := signJWS(t, key, payload)
+		var req *http.Request
+		if method == "GET" {
+			req = httptest.NewRequest(method, url, nil)
+			req.Header.Set("Authorization", "Bearer "+jws)
+		} else {
+			req = httptest.NewRequest(method, url, strings.NewReader(jws))
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("bookmarks posts", func(t *testing.T) {
+		rec := signed("PUT", "/api/posts/first-post/bookmark", map[string]any{"action": "BOOKMARK"})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body: %s", rec.Code, rec.Body.String())
+		}
+		if rec := signed("PUT", "/api/posts/missing/bookmark", map[string]any{"action": "BOOKMARK"}); rec.Code != http.StatusNotFound {
+			t.Errorf("unknown post: status = %d, want 404", rec.Code)
+		}
+	})
+
+	var list models.ReadingListResponse
+	t.Run("creates and fills a reading list", func(t *testing.T) {
+		rec := signed("POST", "/api/me/lists", map[string]any{"action": "CREATE_LIST", "name": "Later"})
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status = %d, body: %s", rec.Code, rec.Body.String())
+		}
+		json.NewDecoder(rec.Body).Decode(&list)
+
+		base := fmt.Sprintf("/api/me/lists/%d", list.ID)
+		signed("PUT", base+"/items/first-post", map[string]any{"action": "ADD_TO_LIST"})
+		signed("PUT", base+"/items/second-post", map[string]any{"action": "ADD_TO_LIST"})
+
+		rec = signed("PUT", base, map[string]any{"action": "UPDATE_LIST", "public": true, "order": []string{"second-post", "first-post"}})
+		json.NewDecoder(rec.Body).Decode(&list)
+		if rec.Code != http.StatusOK || len(list.Items) != 2 || list.Items[0].Path != "second-post" || list.ShareToken == "" {
+			t.Errorf("status = %d, list = %+v", rec.Code, list)
+		}
+	})
+
+	t.Run("serves shared lists without auth", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/lists/"+list.ShareToken, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var shared models.SharedListResponse
+		json.NewDecoder(rec.Body).Decode(&shared)
+		if rec.Code != http.StatusOK || shared.Name != "Later" || len(shared.Items) != 2 {
+			t.Errorf("status = %d, shared = %+v", rec.Code, shared)
+		}
+	})
+
+	t.Run("lists bookmarks and reading lists", func(t *testing.T) {
+		rec := signed("GET", "/api/me/bookmarks", map[string]any{"action": "GET_BOOKMARKS"})
+
+		var resp models.BookmarksResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || len(resp.Bookmarks) != 1 || len(resp.Lists) != 1 || resp.Lists[0].ItemCount != 2 {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("rejects orders that do not match the list", func(t *testing.T) {
+		rec := signed("PUT", fmt.Sprintf("/api/me/lists/%d", list.ID), map[string]any{"action": "UPDATE_LIST", "order": []string{"first-post"}})
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("returns 404 for lists of other wallets", func(t *testing.T) {
+		otherKey, otherAddr := generateTestKey(t)
+		insertTestWallet(t, db, otherAddr)
+
+		req := httptest.NewRequest("DELETE", fmt.Sprintf("/api/me/lists/%d", list.ID), strings.NewReader(signJWS(t, otherKey, map[string]any{"action": "DELETE_LIST"})))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+
+	t.Run("rejects other actions", func(t *testing.T) {
+		if rec := signed("DELETE", "/api/posts/first-post/bookmark", map[string]any{"action": "BOOKMARK"}); rec.Code != http.StatusBadRequest {
+			t.Errorf("remove bookmark: status = %d, want 400", rec.Code)
+		}
+		if rec := signed("GET", "/api/me/bookmarks", map[string]any{"action": "GET_LIST"}); rec.Code != http.StatusBadRequest {
+			t.Errorf("list bookmarks: status = %d, want 400", rec.Code)
+		}
+		if rec := signed("DELETE", fmt.Sprintf("/api/me/lists/%d", list.ID), map[string]any{"action": "UPDATE_LIST"}); rec.Code != http.StatusBadRequest {
+			t.Errorf("delete list: status = %d, want 400", rec.Code)
+		}
+	})
+}
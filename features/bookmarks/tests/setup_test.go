@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"arkana/features/bookmarks/handlers"
+	"arkana/features/bookmarks/services"
+	postservices "arkana/features/posts/services"
+	walletmw "arkana/features/wallet/middlewares"
+	walletsvc "arkana/features/wallet/services"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/mux"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE wallets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			address TEXT UNIQUE NOT NULL,
+			system TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			display_name TEXT NOT NULL DEFAULT '',
+			hide_likes INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE TABLE series (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			slug TEXT UNIQUE NOT NULL,
+			title TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE posts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			path_identifier TEXT UNIQUE NOT NULL,
+			like_count INTEGER NOT NULL DEFAULT 0,
+			series_id INTEGER REFERENCES series(id),
+			series_position INTEGER,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE post_aliases (
+			path_identifier TEXT PRIMARY KEY,
+			post_id INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (post_id) REFERENCES posts(id)
+		);
+		CREATE TABLE bookmarks (
+			wallet_id INTEGER NOT NULL,
+			post_id INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (wallet_id, post_id),
+			FOREIGN KEY (wallet_id) REFERENCES wallets(id),
+			FOREIGN KEY (post_id) REFERENCES posts(id)
+		);
+		CREATE TABLE reading_lists (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			wallet_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			share_token TEXT UNIQUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (wallet_id, name),
+			FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+		);
+		CREATE TABLE reading_list_items (
+			list_id INTEGER NOT NULL,
+			post_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (list_id, post_id),
+			FOREIGN KEY (list_id) REFERENCES reading_lists(id),
+			FOREIGN KEY (post_id) REFERENCES posts(id)
+		);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func insertTestWallet(t *testing.T, db *sql.DB, address string) int {
+	t.Helper()
+	result, err := db.Exec(
+		"INSERT INTO wallets (address, system) VALUES (?, 'ethereum')", strings.ToLower(address),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := result.LastInsertId()
+	return int(id)
+}
+
+func insertTestPost(t *testing.T, db *sql.DB, path string) int {
+	t.Helper()
+	result, err := db.Exec("INSERT INTO posts (path_identifier) VALUES (?)", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := result.LastInsertId()
+	return int(id)
+}
+
+func setupRouter(t *testing.T, db *sql.DB) *mux.Router {
+	t.Helper()
+	router := mux.NewRouter()
+	auth := walletmw.NewAuthMiddleware(walletsvc.NewWalletService(db), nil)
+	handlers.RegisterRoutes(router, postservices.NewPostService(db), services.NewBookmarkService(db), auth)
+	return router
+}
+
+// generateTestKey creates a new Ethereum private key and returns it with its address.
+func generateTestKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+// signJWS creates a compact JWS string (header.payload.signature) signed by the given key.
+func signJWS(t *testing.T, key *ecdsa.PrivateKey, payload map[string]any) string {
+	t.Helper()
+
+	headerJSON, _ := json.Marshal(map[string]string{"system": "ethereum"})
+	protectedB64 := base64.RawURLEncoding.EncodeToString(headerJSON)
+
+	payload["address"] = crypto.PubkeyToAddress(key.PublicKey).Hex()
+	payload["timestamp"] = time.Now().Unix()
+
+	payloadJSON, _ := json.Marshal(payload)
+	payloadB64 := base64.RawURLEncoding.EncodeToString(payloadJSON)
+
+	signingInput := string(payloadJSON)
+	prefixed := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(signingInput), signingInput)
+	hash := crypto.Keccak256Hash([]byte(prefixed))
+
+	sig, err := crypto.Sign(hash.Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27 // EIP-191 recovery id
+
+	return protectedB64 + "." + payloadB64 + "." + hex.EncodeToString(sig)
+}
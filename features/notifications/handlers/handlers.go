@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"arkana/features/notifications/services"
+	"arkana/features/wallet/middlewares"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterRoutes(router *mux.Router, ns *services.NotificationService, auth *middlewares.AuthMiddleware) {
+	notificationHandler := NewNotificationHandler(ns)
+
+	signed := func(h http.HandlerFunc) http.Handler {
+		return auth.RequireAuth(h)
+	}
+
+	router.Handle("/api/me/notifications", signed(notificationHandler.ListNotifications)).Methods("GET", "OPTIONS")
+	router.Handle("/api/me/notifications/read", signed(notificationHandler.MarkAllRead)).Methods("PUT", "OPTIONS")
+	router.Handle("/api/me/notifications/preferences", signed(notificationHandler.GetPreferences)).Methods("GET", "OPTIONS")
+	router.Handle("/api/me/notifications/preferences", signed(notificationHandler.SetPreferences)).Methods("PUT")
+	router.Handle("/api/me/notifications/{id:[0-9]+}/read", signed(notificationHandler.MarkRead)).Methods("PUT", "OPTIONS")
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"arkana/features/notifications/models"
+	"arkana/features/notifications/services"
+	"arkana/features/wallet/middlewares"
+	"arkana/shared/httputil"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler(ns *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: ns}
+}
+
+// ListNotifications handles GET /api/me/notifications?limit=&offset=&unread=,
+// signed with the GET_NOTIFICATIONS action.
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "GET_NOTIFICATIONS" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	limit, offset, err := httputil.ParsePagination(r, 50, 200)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	unreadOnly := false
+	if v := r.URL.Query().Get("unread"); v != "" {
+		if unreadOnly, err = strconv.ParseBool(v); err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "invalid unread")
+			return
+		}
+	}
+
+	notifications, err := h.notificationService.List(vr.WalletID, limit, offset, unreadOnly)
+	if err != nil {
+		log.Printf("[Notifications] Failed to list notifications of wallet %d: %v", vr.WalletID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to list notifications")
+		return
+	}
+	unread, err := h.notificationService.Unread(vr.WalletID)
+	if err != nil {
+		log.Printf("[Notifications] Failed to count notifications of wallet %d: %v", vr.WalletID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to list notifications")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, models.NotificationsResponse{Notifications: notifications, Unread: unread})
+}
+
+// MarkRead handles PUT /api/me/notifications/{id}/read, signed with the
+// READ_NOTIFICATION action.
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "READ_NOTIFICATION" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	err := h.notificationService.MarkRead(vr.WalletID, id)
+	if errors.Is(err, services.ErrNotificationNotFound) {
+		httputil.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("[Notifications] Failed to mark notification %d as read: %v", id, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to mark notification as read")
+		return
+	}
+
+	h.writeMarked(w, vr.WalletID, 1)
+}
+
+// MarkAllRead handles PUT /api/me/notifications/read, signed with the
+// READ_NOTIFICATIONS action. The optional "up_to" payload field is the
+// newest notification ID to mark.
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "READ_NOTIFICATIONS" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		UpTo int `json:"up_to,omitempty"`
+	}
+	if len(vr.Payload) > 0 {
+		if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+
+	marked, err := h.notificationService.MarkAllRead(vr.WalletID, payload.UpTo)
+	if err != nil {
+		log.Printf("[Notifications] Failed to mark notifications of wallet %d as read: %v", vr.WalletID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to mark notifications as read")
+		return
+	}
+
+	h.writeMarked(w, vr.WalletID, marked)
+}
+
+func (h *NotificationHandler) writeMarked(w http.ResponseWriter, walletID, marked int) {
+	unread, err := h.notificationService.Unread(walletID)
+	if err != nil {
+		log.Printf("[Notifications] Failed to count notifications of wallet %d: %v", walletID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to mark notifications as read")
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, models.MarkReadResponse{Marked: marked, Unread: unread})
+}
+
+// GetPreferences handles GET /api/me/notifications/preferences, signed with
+// the GET_NOTIFICATION_PREFERENCES action.
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "GET_NOTIFICATION_PREFERENCES" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	h.writePreferences(w, vr.WalletID)
+}
+
+// SetPreferences handles PUT /api/me/notifications/preferences, signed with
+// the SET_NOTIFICATION_PREFERENCES action. The "preferences" payload field
+// maps kinds to whether to receive them.
+func (h *NotificationHandler) SetPreferences(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "SET_NOTIFICATION_PREFERENCES" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		Preferences map[string]bool `json:"preferences"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	err := h.notificationService.SetPreferences(vr.WalletID, payload.Preferences)
+	if errors.Is(err, services.ErrUnknownKind) {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("[Notifications] Failed to set preferences of wallet %d: %v", vr.WalletID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to set preferences")
+		return
+	}
+
+	h.writePreferences(w, vr.WalletID)
+}
+
+func (h *NotificationHandler) writePreferences(w http.ResponseWriter, walletID int) {
+	prefs, err := h.notificationService.Preferences(walletID)
+	if err != nil {
+		log.Printf("[Notifications] Failed to load preferences of wallet %d: %v", walletID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to load preferences")
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, models.PreferencesResponse{Preferences: prefs})
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+type Actor struct {
+	Address     string `json:"address"`
+	DisplayName string `json:"display_name"`
+}
+
+// Notification tells a wallet that the actor replied to its comment,
+// mentioned it, or upvoted its comment. CommentID is the reply, the
+// mentioning comment, or the upvoted comment, respectively.
+type Notification struct {
+	ID        int       `json:"id"`
+	Kind      string    `json:"kind"`
+	Actor     Actor     `json:"actor"`
+	PostPath  string    `json:"post_path"`
+	CommentID int       `json:"comment_id"`
+	Excerpt   string    `json:"excerpt"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type NotificationsResponse struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        int            `json:"unread"`
+}
+
+type MarkReadResponse struct {
+	Marked int `json:"marked"`
+	Unread int `json:"unread"`
+}
+
+// PreferencesResponse maps every notification kind to whether the wallet
+// receives it.
+type PreferencesResponse struct {
+	Preferences map[string]bool `json:"preferences"`
+}
@@ -0,0 +1,20 @@
+package notifications
+
+import (
+	"arkana/features/notifications/handlers"
+	"arkana/features/notifications/services"
+	postservices "arkana/features/posts/services"
+	"arkana/features/wallet/middlewares"
+	"database/sql"
+
+	"github.com/gorilla/mux"
+)
+
+// Initialize registers the notification routes and subscribes to the
+// events of the comment service.
+func Initialize(router *mux.Router, db *sql.DB, cs *postservices.CommentService, auth *middlewares.AuthMiddleware) {
+	notificationService := services.NewNotificationService(db)
+	cs.SetNotifier(notificationService)
+
+	handlers.RegisterRoutes(router, notificationService, auth)
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"arkana/features/notifications/models"
+	postservices "arkana/features/posts/services"
+	"database/sql"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrUnknownKind          = errors.New("unknown notification kind")
+)
+
+// Kinds are the kinds of notifications, which wallets can turn off one by
+// one.
+var Kinds = []string{postservices.EventReply, postservices.EventMention, postservices.EventReaction}
+
+// ExcerptLength is the number of characters of the comment body quoted in
+// a notification.
+const ExcerptLength = 140
+
+// NotificationService stores the notifications of wallets. It receives the
+// events of the comment service.
+type NotificationService struct {
+	db *sql.DB
+}
+
+func NewNotificationService(db *sql.DB) *NotificationService {
+	return &NotificationService{db: db}
+}
+
+// Notify stores a notification for every recipient of an event that
+// didn't turn its kind off. Failures are logged, as the event has already
+// happened.
+func (s *NotificationService) Notify(event postservices.CommentEvent) {
+	for _, walletID := range event.Recipients {
+		_, err := s.db.Exec(`
+			INSERT OR IGNORE INTO notifications (wallet_id, kind, actor_id, comment_id)
+			SELECT ?1, ?2, ?3, ?4
+			WHERE NOT EXISTS (
+				SELECT 1 FROM notification_preferences
+				WHERE wallet_id = ?1 AND kind = ?2 AND enabled = 0
+			)
+		`, walletID, event.Kind, event.ActorID, event.CommentID)
+		if err != nil {
+			log.Printf("[Notifications] Failed to notify wallet %d of %s on comment %d: %v", walletID, event.Kind, event.CommentID, err)
+		}
+	}
+}
+
+// List returns a page of a wallet's notifications, most recent first,
+// optionally only the unread ones. Notifications about comments hidden by
+// moderation are left out while they are hidden.
+func (s *NotificationService) List(walletID, limit, offset int, unreadOnly bool) ([]models.Notification, error) {
+	rows, err := s.db.Query(`
+		SELECT n.id, n.kind, a.address, a.display_name, p.path_identifier,
+			n.comment_id, c.body, n.read_at IS NOT NULL, n.created_at
+		FROM notifications n
+		JOIN wallets a ON a.id = n.actor_id
+		JOIN comments c ON c.id = n.comment_id
+		JOIN posts p ON p.id = c.post_id
+		WHERE n.wallet_id = ? AND (? = 0 OR n.read_at IS NULL) AND c.status = 'visible'
+		ORDER BY n.id DESC
+		LIMIT ? OFFSET ?
+	`, walletID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		var body string
+		err := rows.Scan(&n.ID, &n.Kind, &n.Actor.Address, &n.Actor.DisplayName, &n.PostPath,
+			&n.CommentID, &body, &n.Read, &n.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		n.Excerpt = excerpt(body)
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// Unread counts a wallet's unread notifications, like List.
+func (s *NotificationService) Unread(walletID int) (int, error) {
+	var n int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM notifications n
+		JOIN comments c ON c.id = n.comment_id
+		WHERE n.wallet_id = ? AND n.read_at IS NULL AND c.status = 'visible'
+	`, walletID).Scan(&n)
+	return n, err
+}
+
+// MarkRead marks one of a wallet's notifications as read. Marking it again
+// is a no-op.
+// Returns ErrNotificationNotFound if the wallet has no such notification.
+func (s *NotificationService) MarkRead(walletID, id int) error {
+	result, err := s.db.Exec(`
+		UPDATE notifications SET read_at = COALESCE(read_at, ?)
+		WHERE id = ? AND wallet_id = ?
+	`, time.Now().UTC(), id, walletID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks a wallet's unread notifications as read and returns
+// how many there were. A positive upTo leaves out notifications newer than
+// it, so that those that arrived after the client's last fetch stay unread.
+func (s *NotificationService) MarkAllRead(walletID, upTo int) (int, error) {
+	result, err := s.db.Exec(`
+		UPDATE notifications SET read_at = ?
+		WHERE wallet_id = ? AND read_at IS NULL AND (? <= 0 OR id <= ?)
+	`, time.Now().UTC(), walletID, upTo, upTo)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// Preferences returns whether a wallet receives each kind of notification.
+func (s *NotificationService) Preferences(walletID int) (map[string]bool, error) {
+	prefs := make(map[string]bool, len(Kinds))
+	for _, kind := range Kinds {
+		prefs[kind] = true
+	}
+
+	rows, err := s.db.Query("SELECT kind, enabled FROM notification_preferences WHERE wallet_id = ?", walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind string
+		var enabled bool
+		if err := rows.Scan(&kind, &enabled); err != nil {
+			return nil, err
+		}
+		if _, ok := prefs[kind]; ok {
+			prefs[kind] = enabled
+		}
+	}
+	return prefs, rows.Err()
+}
+
+// SetPreferences turns kinds of notifications on or off for a wallet.
+// Kinds left out keep their setting. Turning a kind off doesn't delete
+// the notifications already received.
+// Returns ErrUnknownKind if a kind doesn't exist.
+func (s *NotificationService) SetPreferences(walletID int, prefs map[string]bool) error {
+	for kind := range prefs {
+		if !slices.Contains(Kinds, kind) {
+			return ErrUnknownKind
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for kind, enabled := range prefs {
+		_, err := tx.Exec(`
+			INSERT INTO notification_preferences (wallet_id, kind, enabled) VALUES (?, ?, ?)
+			ON CONFLICT (wallet_id, kind) DO UPDATE SET enabled = excluded.enabled
+		`, walletID, kind, enabled)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// excerpt shortens a comment body to ExcerptLength characters on one line.
+func excerpt(body string) string {
+	body = strings.Join(strings.Fields(body), " ")
+	if utf8.RuneCountInString(body) <= ExcerptLength {
+		return body
+	}
+	runes := []rune(body)
+	return strings.TrimRight(string(runes[:ExcerptLength-1]), " ") + "…"
+}
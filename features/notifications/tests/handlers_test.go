@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"arkana/features/notifications/models"
+	postservices "arkana/features/posts/services"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNotificationHandlers(t *testing.T) {
+	db := setupTestDB(t)
+	comments, svc := setupServices(t, db)
+	router := setupRouter(t, db, svc)
+	key, addr := generateTestKey(t)
+	owner := insertTestWallet(t, db, addr)
+	other := insertTestWallet(t, db, "0xbbbb000000000000000000000000000000000002")
+	post := insertTestPost(t, db, "first-post")
+
+	root, _ := comments.Create(post, owner, "first", nil, "")
+	comments.Create(post, other, "a reply", &root.ID, "")
+	comments.Vote(root.ID, postservices.CommentViewer{WalletID: other}, 1)
+
+	signed := func(method, url string, payload map[string]any) *httptest.ResponseRecorder {
+		jws := signJWS(t, key, payload)
+		var req *http.Request
+		if method == "GET" {
+			req = httptest.NewRequest(method, url, nil)
+			req.Header.Set("Authorization", "Bearer "+jws)
+		} else {
+			req = httptest.NewRequest(method, url, strings.NewReader(jws))
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	var list models.NotificationsResponse
+	t.Run("lists notifications", func(t *testing.T) {
+		rec := signed("GET", "/api/me/notifications?limit=1", map[string]any{"action": "GET_NOTIFICATIONS"})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body: %s", rec.Code, rec.Body.String())
+		}
+		json.NewDecoder(rec.Body).Decode(&list)
+		if len(list.Notifications) != 1 || list.Notifications[0].Kind != "reaction" || list.Unread != 2 {
+			t.Errorf("list = %+v", list)
+		}
+
+		if rec := signed("GET", "/api/me/notifications?unread=maybe", map[string]any{"action": "GET_NOTIFICATIONS"}); rec.Code != http.StatusBadRequest {
+			t.Errorf("invalid unread: status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("marks notifications as read", func(t *testing.T) {
+		url := fmt.Sprintf("/api/me/notifications/%d/read", list.Notifications[0].ID)
+		rec := signed("PUT", url, map[string]any{"action": "READ_NOTIFICATION"})
+
+		var resp models.MarkReadResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || resp.Unread != 1 {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+		if rec := signed("PUT", "/api/me/notifications/999/read", map[string]any{"action": "READ_NOTIFICATION"}); rec.Code != http.StatusNotFound {
+			t.Errorf("unknown notification: status = %d, want 404", rec.Code)
+		}
+
+		rec = signed("PUT", "/api/me/notifications/read", map[string]any{"action": "READ_NOTIFICATIONS"})
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || resp.Marked != 1 || resp.Unread != 0 {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("updates preferences", func(t *testing.T) {
+		rec := signed("PUT", "/api/me/notifications/preferences", map[string]any{
+			"action":      "SET_NOTIFICATION_PREFERENCES",
+			"preferences": map[string]bool{"reply": false},
+		})
+		var prefs models.PreferencesResponse
+		json.NewDecoder(rec.Body).Decode(&prefs)
+		if rec.Code != http.StatusOK || prefs.Preferences["reply"] || !prefs.Preferences["mention"] {
+			t.Errorf("status = %d, prefs = %+v", rec.Code, prefs)
+		}
+
+		rec = signed("PUT", "/api/me/notifications/preferences", map[string]any{
+			"action":      "SET_NOTIFICATION_PREFERENCES",
+			"preferences": map[string]bool{"likes": true},
+		})
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("unknown kind: status = %d, want 400", rec.Code)
+		}
+
+		rec = signed("GET", "/api/me/notifications/preferences", map[string]any{"action": "GET_NOTIFICATION_PREFERENCES"})
+		json.NewDecoder(rec.Body).Decode(&prefs)
+		if prefs.Preferences["reply"] {
+			t.Errorf("prefs = %+v, want replies off", prefs)
+		}
+	})
+
+	t.Run("rejects other actions", func(t *testing.T) {
+		if rec := signed("PUT", "/api/me/notifications/read", map[string]any{"action": "READ_NOTIFICATION"}); rec.Code != http.StatusBadRequest {
+			t.Errorf("mark all read: status = %d, want 400", rec.Code)
+		}
+		rec := signed("PUT", "/api/me/notifications/preferences", map[string]any{
+			"action":      "GET_NOTIFICATION_PREFERENCES",
+			"preferences": map[string]bool{"reply": true},
+		})
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("set preferences: status = %d, want 400", rec.Code)
+		}
+		if rec := signed("GET", "/api/me/notifications", map[string]any{"action": "READ_NOTIFICATIONS"}); rec.Code != http.StatusBadRequest {
+			t.Errorf("list: status = %d, want 400", rec.Code)
+		}
+	})
+}
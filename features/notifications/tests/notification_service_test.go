@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"arkana/features/notifications/services"
+	postservices "arkana/features/posts/services"
+	"errors"
+	"testing"
+)
+
+func TestNotifications(t *testing.T) {
+	db := setupTestDB(t)
+	comments, svc := setupServices(t, db)
+	alice := insertTestWallet(t, db, "0xaaaa000000000000000000000000000000000001")
+	bob := insertTestWallet(t, db, "0xbbbb000000000000000000000000000000000002")
+	carol := insertTestWallet(t, db, "0xcccc000000000000000000000000000000000003")
+	db.Exec("UPDATE wallets SET display_name = 'Bob' WHERE id = ?", bob)
+	post := insertTestPost(t, db, "wtf-is/risc-v")
+
+	root, _ := comments.Create(post, alice, "What about the vector extension?", nil, "")
+
+	t.Run("notifies replies and mentions once", func(t *testing.T) {
+		body := "@0xaaaa000000000000000000000000000000000001 and @0xcccc000000000000000000000000000000000003: it's in 1.0"
+		reply, err := comments.Create(post, bob, body, &root.ID, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		list, _ := svc.List(alice, 10, 0, false)
+		if len(list) != 1 || list[0].Kind != "reply" || list[0].CommentID != reply.ID {
+			t.Fatalf("alice = %+v, want only the reply", list)
+		}
+		n := list[0]
+		if n.Actor.DisplayName != "Bob" || n.PostPath != "wtf-is/risc-v" || n.Read || n.Excerpt != body {
+			t.Errorf("notification = %+v", n)
+		}
+
+		list, _ = svc.List(carol, 10, 0, false)
+		if len(list) != 1 || list[0].Kind != "mention" {
+			t.Errorf("carol = %+v, want the mention", list)
+		}
+	})
+
+	t.Run("does not notify actors about themselves", func(t *testing.T) {
+		comments.Create(post, alice, "answering myself", &root.ID, "")
+		if n, _ := svc.Unread(alice); n != 1 {
+			t.Errorf("unread = %d, want 1", n)
+		}
+	})
+
+	t.Run("notifies an upvote once", func(t *testing.T) {
+		comments.Vote(root.ID, postservices.CommentViewer{WalletID: carol}, 1)
+		comments.Vote(root.ID, postservices.CommentViewer{WalletID: carol}, 0)
+		comments.Vote(root.ID, postservices.CommentViewer{WalletID: carol}, 1)
+		comments.Vote(root.ID, postservices.CommentViewer{WalletID: bob}, -1)
+
+		list, _ := svc.List(alice, 10, 0, false)
+		if len(list) != 2 || list[0].Kind != "reaction" {
+			t.Errorf("alice = %+v, want the reply and one reaction", list)
+		}
+	})
+
+	t.Run("respects preferences", func(t *testing.T) {
+		if err := svc.SetPreferences(alice, map[string]bool{"reaction": false}); err != nil {
+			t.Fatal(err)
+		}
+		comments.Vote(root.ID, postservices.CommentViewer{WalletID: bob}, 1)
+		if n, _ := svc.Unread(alice); n != 2 {
+			t.Errorf("unread = %d, want 2", n)
+		}
+
+		prefs, _ := svc.Preferences(alice)
+		if prefs["reaction"] || !prefs["reply"] || !prefs["mention"] {
+			t.Errorf("preferences = %v", prefs)
+		}
+		if err := svc.SetPreferences(alice, map[string]bool{"likes": false}); !errors.Is(err, services.ErrUnknownKind) {
+			t.Errorf("err = %v, want ErrUnknownKind", err)
+		}
+	})
+
+	t.Run("marks notifications as read", func(t *testing.T) {
+		list, _ := svc.List(alice, 10, 0, false)
+		newest, oldest := list[0].ID, list[1].ID
+
+		if err := svc.MarkRead(bob, newest); !errors.Is(err, services.ErrNotificationNotFound) {
+			t.Errorf("someone else's: err = %v, want ErrNotificationNotFound", err)
+		}
+		if err := svc.MarkRead(alice, oldest); err != nil {
+			t.Fatal(err)
+		}
+		unread, _ := svc.List(alice, 10, 0, true)
+		if len(unread) != 1 || unread[0].ID != newest {
+			t.Errorf("unread = %+v, want the newest", unread)
+		}
+
+		if n, _ := svc.MarkAllRead(alice, oldest); n != 0 {
+			t.Errorf("marked %d up to the oldest, want 0", n)
+		}
+		if n, _ := svc.MarkAllRead(alice, 0); n != 1 {
+			t.Errorf("marked %d, want 1", n)
+		}
+	})
+
+	t.Run("drops the notifications of deleted comments", func(t *testing.T) {
+		list, _ := svc.List(carol, 10, 0, false)
+		if _, err := comments.Delete(list[0].CommentID, bob); err != nil {
+			t.Fatal(err)
+		}
+		if list, _ := svc.List(carol, 10, 0, false); len(list) != 0 {
+			t.Errorf("carol = %+v, want none", list)
+		}
+	})
+}
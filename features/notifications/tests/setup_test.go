@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"arkana/features/notifications/handlers"
+	"arkana/features/notifications/services"
+	postservices "arkana/features/posts/services"
+	walletmw "arkana/features/wallet/middlewares"
+	walletsvc "arkana/features/wallet/services"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/mux"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE wallets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			address TEXT UNIQUE NOT NULL,
+			system TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			display_name TEXT NOT NULL DEFAULT '',
+			hide_likes INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE TABLE posts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			path_identifier TEXT UNIQUE NOT NULL,
+			like_count INTEGER NOT NULL DEFAULT 0,
+			series_id INTEGER,
+			series_position INTEGER,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE comments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			post_id INTEGER NOT NULL,
+			wallet_id INTEGER NOT NULL,
+			parent_id INTEGER,
+			body TEXT NOT NULL,
+			locale TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			revision INTEGER NOT NULL DEFAULT 1,
+			edited_at TIMESTAMP,
+			deleted_at TIMESTAMP,
+			deleted_by TEXT,
+			body_html TEXT NOT NULL DEFAULT '',
+			render_version INTEGER NOT NULL DEFAULT 0,
+			upvotes INTEGER NOT NULL DEFAULT 0,
+			downvotes INTEGER NOT NULL DEFAULT 0,
+			score REAL NOT NULL DEFAULT 0,
+			status TEXT NOT NULL DEFAULT 'visible'
+		);
+		CREATE TABLE comment_revisions (
+			comment_id INTEGER NOT NULL,
+			revision INTEGER NOT NULL,
+			body TEXT NOT NULL,
+			body_html TEXT NOT NULL DEFAULT '',
+			render_version INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (comment_id, revision)
+		);
+		CREATE TABLE comment_votes (
+			comment_id INTEGER NOT NULL,
+			wallet_id INTEGER NOT NULL,
+			value INTEGER NOT NULL CHECK (value IN (-1, 1)),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (comment_id, wallet_id)
+		);
+		CREATE TABLE comment_mentions (
+			comment_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			length INTEGER NOT NULL,
+			wallet_id INTEGER NOT NULL,
+			PRIMARY KEY (comment_id, position)
+		);
+		CREATE TABLE comment_reports (
+			comment_id INTEGER NOT NULL,
+			wallet_id INTEGER NOT NULL,
+			reason TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			resolved_at TIMESTAMP,
+			PRIMARY KEY (comment_id, wallet_id)
+		);
+		CREATE TABLE comment_bans (
+			wallet_id INTEGER PRIMARY KEY,
+			moderator_id INTEGER NOT NULL,
+			reason TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE notifications (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			wallet_id INTEGER NOT NULL,
+			kind TEXT NOT NULL CHECK (kind IN ('reply', 'mention', 'reaction')),
+			actor_id INTEGER NOT NULL,
+			comment_id INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			read_at TIMESTAMP,
+			UNIQUE (wallet_id, kind, actor_id, comment_id)
+		);
+		CREATE TABLE notification_preferences (
+			wallet_id INTEGER NOT NULL,
+			kind TEXT NOT NULL,
+			enabled INTEGER NOT NULL,
+			PRIMARY KEY (wallet_id, kind)
+		);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func insertTestWallet(t *testing.T, db *sql.DB, address string) int {
+	t.Helper()
+	result, err := db.Exec(
+		"INSERT INTO wallets (address, system) VALUES (?, 'ethereum')", strings.ToLower(address),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := result.LastInsertId()
+	return int(id)
+}
+
+func insertTestPost(t *testing.T, db *sql.DB, path string) int {
+	t.Helper()
+	result, err := db.Exec("INSERT INTO posts (path_identifier) VALUES (?)", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := result.LastInsertId()
+	return int(id)
+}
+
+// setupServices returns a comment service that sends its events to the
+// notification service.
+func setupServices(t *testing.T, db *sql.DB) (*postservices.CommentService, *services.NotificationService) {
+	t.Helper()
+	comments := postservices.NewCommentService(db)
+	notifications := services.NewNotificationService(db)
+	comments.SetNotifier(notifications)
+	return comments, notifications
+}
+
+func setupRouter(t *testing.T, db *sql.DB, ns *services.NotificationService) *mux.Router {
+	t.Helper()
+	router := mux.NewRouter()
+	auth := walletmw.NewAuthMiddleware(walletsvc.NewWalletService(db), nil)
+	handlers.RegisterRoutes(router, ns, auth)
+	return router
+}
+
+// generateTestKey creates a new Ethereum private key and returns it with its address.
+func generateTestKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+// signJWS creates a compact JWS string (header.payload.signature) signed by the given key.
+func signJWS(t *testing.T, key *ecdsa.PrivateKey, payload map[string]any) string {
+	t.Helper()
+
+	headerJSON, _ := json.Marshal(map[string]string{"system": "ethereum"})
+	protectedB64 := base64.RawURLEncoding.EncodeToString(headerJSON)
+
+	payload["address"] = crypto.PubkeyToAddress(key.PublicKey).Hex()
+	payload["timestamp"] = time.Now().Unix()
+
+	payloadJSON, _ := json.Marshal(payload)
+	payloadB64 := base64.RawURLEncoding.EncodeToString(payloadJSON)
+
+	signingInput := string(payloadJSON)
+	prefixed := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(signingInput), signingInput)
+	hash := crypto.Keccak256Hash([]byte(prefixed))
+
+	sig, err := crypto.Sign(hash.Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27 // EIP-191 recovery id
+
+	return protectedB64 + "." + payloadB64 + "." + hex.EncodeToString(sig)
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"arkana/features/posts/models"
+	"arkana/features/posts/services"
+	"arkana/features/wallet/middlewares"
+	"arkana/shared/httputil"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+type AdminHandler struct {
+	postService      *services.PostService
+	syncService      *services.SyncService
+	reconcileService *services.ReconcileService
+}
+
+func NewAdminHandler(ps *services.PostService, sync *services.SyncService, rs *services.ReconcileService) *AdminHandler {
+	return &AdminHandler{postService: ps, syncService: sync, reconcileService: rs}
+}
+
+// SyncPosts handles POST /api/admin/posts/sync
+//
+// The signed payload may embed a JSON manifest; otherwise the configured
+// manifest source is used.
+func (h *AdminHandler) SyncPosts(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "SYNC_POSTS" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		DryRun   bool            `json:"dry_run"`
+		Manifest json.RawMessage `json:"manifest,omitempty"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	log.Printf("[Sync] Requested by %s (dry_run=%v, inline=%v)", vr.Address, payload.DryRun, len(payload.Manifest) > 0)
+
+	var err error
+	var report *models.SyncReport
+	if len(payload.Manifest) > 0 {
+		manifest, parseErr := services.ParseJSONManifest(payload.Manifest)
+		if parseErr != nil {
+			httputil.WriteError(w, http.StatusBadRequest, parseErr.Error())
+			return
+		}
+		report, err = h.syncService.Sync(manifest, payload.DryRun)
+	} else {
+		report, err = h.syncService.SyncFromSource(payload.DryRun)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrNoManifestSource) || errors.Is(err, services.ErrInvalidSeriesPart) {
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("[Sync] Failed: %v", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to sync posts")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, report)
+}
+
+// RenamePost handles POST /api/admin/posts/rename
+//
+// The old path is kept as an alias of the post.
+func (h *AdminHandler) RenamePost(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "RENAME_POST" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		From string `json:"from" validate:"required"`
+		To   string `json:"to" validate:"required"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload: "+err.Error())
+		return
+	}
+
+	log.Printf("[Admin] Rename %q -> %q requested by %s", payload.From, payload.To, vr.Address)
+
+	post, err := h.postService.Rename(payload.From, payload.To)
+	if err != nil {
+		h.writeAliasError(w, "rename", err)
+		return
+	}
+
+	h.writePostInfo(w, post.PathIdentifier)
+}
+
+// MergePosts handles POST /api/admin/posts/merge
+//
+// Likes, comments and translations of the source post move to the target,
+// and the source path becomes an alias of the target.
+func (h *AdminHandler) MergePosts(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "MERGE_POSTS" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		From string `json:"from" validate:"required"`
+		Into string `json:"into" validate:"required"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload: "+err.Error())
+		return
+	}
+
+	log.Printf("[Admin] Merge %q into %q requested by %s", payload.From, payload.Into, vr.Address)
+
+	post, err := h.postService.Merge(payload.From, payload.Into)
+	if err != nil {
+		h.writeAliasError(w, "merge", err)
+		return
+	}
+
+	h.writePostInfo(w, post.PathIdentifier)
+}
+
+func (h *AdminHandler) writeAliasError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, services.ErrPostNotFound):
+		httputil.WriteError(w, http.StatusNotFound, "post not found")
+	case errors.Is(err, services.ErrPathTaken), errors.Is(err, services.ErrSelfMerge):
+		httputil.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("[Admin] Failed to %s post: %v", op, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to "+op+" post")
+	}
+}
+
+func (h *AdminHandler) writePostInfo(w http.ResponseWriter, path string) {
+	info, err := h.postService.GetPostInfo(path, "", "")
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to get post info")
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, info)
+}
+
+// GetIntegrity handles GET /api/admin/integrity
+//
+// Returns the report of the last counter reconciliation. The request, signed
+// with the GET_INTEGRITY action, is sent as an "Authorization: Bearer" header.
+func (h *AdminHandler) GetIntegrity(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "GET_INTEGRITY" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	report, err := h.reconcileService.Latest()
+	if err != nil {
+		if errors.Is(err, services.ErrNoIntegrityReport) {
+			httputil.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("[Admin] Failed to load integrity report: %v", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to load integrity report")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, report)
+}
+
+// RunIntegrity handles POST /api/admin/integrity
+//
+// Runs a counter reconciliation now, fixing discrepancies if requested.
+func (h *AdminHandler) RunIntegrity(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "RECONCILE" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		Fix bool `json:"fix"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	log.Printf("[Admin] Reconcile requested by %s (fix=%v)", vr.Address, payload.Fix)
+
+	report, err := h.reconcileService.Run(payload.Fix)
+	if err != nil {
+		log.Printf("[Admin] Reconcile failed: %v", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to reconcile counters")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, report)
+}
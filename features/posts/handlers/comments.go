@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"arkana/features/posts/models"
 	"arkana/features/posts/services"
 	"arkana/features/wallet/middlewares"
 	"arkana/shared/httputil"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
@@ -18,13 +21,18 @@ var validate = validator.New()
 type CommentHandler struct {
 	postService    *services.PostService
 	commentService *services.CommentService
+	auth           *middlewares.AuthMiddleware
 }
 
-func NewCommentHandler(ps *services.PostService, cs *services.CommentService) *CommentHandler {
-	return &CommentHandler{postService: ps, commentService: cs}
+func NewCommentHandler(ps *services.PostService, cs *services.CommentService, auth *middlewares.AuthMiddleware) *CommentHandler {
+	return &CommentHandler{postService: ps, commentService: cs, auth: auth}
 }
 
-// GetComments handles GET /api/posts/{path}/comments
+// GetComments handles GET /api/posts/{path}/comments?locale=xx&sort=.
+// sort is oldest (default), newest or top, which ranks comments by votes.
+// With view=tree the comments are returned as a page of nested threads.
+// Requests signed in the Authorization header also list the hidden
+// comments of their wallet, or all of them for moderators.
 func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	path := vars["path"]
@@ -43,8 +51,23 @@ func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	comments, err := h.commentService.GetByPostID(post.ID)
+	if r.URL.Query().Get("view") == "tree" {
+		opts, ok := parseTreeOptions(w, r)
+		if !ok {
+			return
+		}
+		opts.Viewer = h.viewer(r)
+		tree, err := h.commentService.Tree(post.ID, opts)
+		writeTree(w, tree, err)
+		return
+	}
+
+	comments, err := h.commentService.List(post.ID, r.URL.Query().Get("locale"), r.URL.Query().Get("sort"), h.viewer(r))
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidCommentSort) {
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		httputil.WriteError(w, http.StatusInternalServerError, "failed to fetch comments")
 		return
 	}
@@ -52,6 +75,113 @@ func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
 	httputil.WriteJSON(w, http.StatusOK, comments)
 }
 
+// GetComment handles GET /api/comments/{id}, the permalink of a comment.
+// It returns the comment with its ancestors and replies, and siblings
+// (default 2, at most 10) of its siblings on each side. It takes the tree
+// options of GetComments, and like it shows hidden comments to their author
+// and moderators when signed.
+func (h *CommentHandler) GetComment(w http.ResponseWriter, r *http.Request) {
+	opts, ok := parseTreeOptions(w, r)
+	if !ok {
+		return
+	}
+	opts.Viewer = h.viewer(r)
+
+	siblings := 2
+	if v := r.URL.Query().Get("siblings"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			httputil.WriteError(w, http.StatusBadRequest, "siblings must be a non-negative integer")
+			return
+		}
+		siblings = n
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	resp, err := h.commentService.Context(id, siblings, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCommentNotFound):
+			httputil.WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrInvalidCommentSort):
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			log.Printf("[Comments] Failed to fetch comment %d: %v", id, err)
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to fetch comment")
+		}
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetReplies handles GET /api/comments/{id}/replies, which loads the
+// replies left out of a comment tree. It takes the same options as the
+// tree view of GetComments, and is signed the same way.
+func (h *CommentHandler) GetReplies(w http.ResponseWriter, r *http.Request) {
+	opts, ok := parseTreeOptions(w, r)
+	if !ok {
+		return
+	}
+	opts.Viewer = h.viewer(r)
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	replies, err := h.commentService.Replies(id, opts)
+	if errors.Is(err, services.ErrCommentNotFound) {
+		httputil.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeTree(w, replies, err)
+}
+
+// parseTreeOptions reads the tree query parameters: sort, depth (default 3),
+// limit (default 20), replies per comment (default 5), cursor and locale.
+// It writes a 400 response and returns false when they are invalid.
+func parseTreeOptions(w http.ResponseWriter, r *http.Request) (services.CommentTreeOptions, bool) {
+	query := r.URL.Query()
+	opts := services.CommentTreeOptions{
+		Locale:  query.Get("locale"),
+		Sort:    query.Get("sort"),
+		Cursor:  query.Get("cursor"),
+		Depth:   3,
+		Replies: 5,
+	}
+
+	limit, _, err := httputil.ParsePagination(r, 20, services.MaxCommentPage)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return opts, false
+	}
+	opts.Limit = limit
+
+	for name, value := range map[string]*int{"depth": &opts.Depth, "replies": &opts.Replies} {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				httputil.WriteError(w, http.StatusBadRequest, name+" must be a positive integer")
+				return opts, false
+			}
+			*value = n
+		}
+	}
+
+	return opts, true
+}
+
+func writeTree(w http.ResponseWriter, tree *models.CommentTreeResponse, err error) {
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) || errors.Is(err, services.ErrInvalidCommentSort) {
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("[Comments] Failed to build comment tree: %v", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to fetch comments")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, tree)
+}
+
 // CreateComment handles POST /api/posts/{path}/comments
 func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 	vr, ok := middlewares.GetVerifiedRequest(r.Context())
@@ -81,7 +211,7 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := h.postService.GetByPath(path)
+	post, err := h.postService.GetOrAutoCreate(path)
 	if err != nil {
 		if errors.Is(err, services.ErrPostNotFound) {
 			httputil.WriteError(w, http.StatusNotFound, "post not found")
@@ -91,15 +221,228 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	comment, err := h.commentService.Create(post.ID, vr.WalletID, payload.Body, payload.ParentID)
+	comment, err := h.commentService.Create(post.ID, vr.WalletID, payload.Body, payload.ParentID, post.Locale)
 	if err != nil {
 		if errors.Is(err, services.ErrCommentTooLong) {
 			httputil.WriteError(w, http.StatusBadRequest, fmt.Sprintf("comment exceeds maximum length of %d characters", services.MaxCommentLength))
 			return
 		}
+		if errors.Is(err, services.ErrWalletBanned) {
+			httputil.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		httputil.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	httputil.WriteJSON(w, http.StatusCreated, comment)
 }
+
+// EditComment handles PUT /api/comments/{id}, signed with the EDIT_COMMENT
+// action. The payload carries the new body and the revision being edited.
+func (h *CommentHandler) EditComment(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if vr.Action != "EDIT_COMMENT" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		Body     string `json:"body" validate:"required"`
+		Revision int    `json:"revision" validate:"required,min=1"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload: "+err.Error())
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	comment, err := h.commentService.Edit(id, vr.WalletID, payload.Body, payload.Revision)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCommentNotFound):
+			httputil.WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrCommentDeleted):
+			httputil.WriteError(w, http.StatusGone, err.Error())
+		case errors.Is(err, services.ErrNotCommentAuthor), errors.Is(err, services.ErrEditWindowClosed),
+			errors.Is(err, services.ErrWalletBanned):
+			httputil.WriteError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrRevisionConflict):
+			httputil.WriteError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrCommentTooLong):
+			httputil.WriteError(w, http.StatusBadRequest, fmt.Sprintf("comment exceeds maximum length of %d characters", services.MaxCommentLength))
+		case errors.Is(err, services.ErrCommentEmpty):
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			log.Printf("[Comments] Failed to edit comment %d: %v", id, err)
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to edit comment")
+		}
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, models.CommentResponse{
+		ID:            comment.ID,
+		ParentID:      comment.ParentID,
+		Body:          comment.Body,
+		BodyHTML:      comment.BodyHTML,
+		Locale:        comment.Locale,
+		CreatedAt:     comment.CreatedAt,
+		Revision:      comment.Revision,
+		EditedAt:      comment.EditedAt,
+		AuthorAddress: vr.Address,
+		Upvotes:       comment.Upvotes,
+		Downvotes:     comment.Downvotes,
+		Score:         comment.Score,
+		Status:        comment.Status,
+	})
+}
+
+// VoteComment handles PUT and DELETE /api/comments/{id}/vote, signed with
+// the VOTE_COMMENT action. PUT sets the wallet's vote to the payload's
+// value (1 or -1) and DELETE withdraws it.
+func (h *CommentHandler) VoteComment(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if vr.Action != "VOTE_COMMENT" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var value int
+	if r.Method == http.MethodPut {
+		var payload struct {
+			Value int `json:"value" validate:"oneof=1 -1"`
+		}
+		if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+		if err := validate.Struct(payload); err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, services.ErrInvalidVote.Error())
+			return
+		}
+		value = payload.Value
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	vote, err := h.commentService.Vote(id, h.viewer(r), value)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCommentNotFound):
+			httputil.WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrCommentDeleted):
+			httputil.WriteError(w, http.StatusGone, err.Error())
+		case errors.Is(err, services.ErrSelfVote), errors.Is(err, services.ErrWalletBanned):
+			httputil.WriteError(w, http.StatusForbidden, err.Error())
+		default:
+			log.Printf("[Comments] Failed to vote on comment %d: %v", id, err)
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to vote")
+		}
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, vote)
+}
+
+// GetCommentHistory handles GET /api/comments/{id}/history. The history
+// of a hidden comment is only returned to its author and moderators.
+func (h *CommentHandler) GetCommentHistory(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	history, err := h.commentService.History(id, h.viewer(r))
+	if err != nil {
+		if errors.Is(err, services.ErrCommentNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to fetch comment history")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, history)
+}
+
+// DeleteComment handles DELETE /api/comments/{id}, signed by the author
+// with the DELETE_COMMENT action.
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "DELETE_COMMENT" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	purged, err := h.commentService.Delete(id, vr.WalletID)
+	h.writeDeleteResult(w, id, purged, err)
+}
+
+// AdminDeleteComment handles DELETE /api/admin/comments/{id}, signed with
+// the DELETE_COMMENT action. The payload carries the reason, which is
+// recorded in the moderation log like any other moderator decision.
+func (h *CommentHandler) AdminDeleteComment(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "DELETE_COMMENT" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	result, err := h.commentService.Moderate(id, vr.WalletID, services.ModerationDelete, payload.Reason)
+	if errors.Is(err, services.ErrInvalidModerationReason) {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.writeDeleteResult(w, id, false, err)
+		return
+	}
+	log.Printf("[Comments] Comment %d deleted by admin %s (purged=%v): %s", id, vr.Address, result.Purged, payload.Reason)
+	h.writeDeleteResult(w, id, result.Purged, nil)
+}
+
+func (h *CommentHandler) writeDeleteResult(w http.ResponseWriter, id int, purged bool, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCommentNotFound):
+			httputil.WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrCommentDeleted):
+			httputil.WriteError(w, http.StatusGone, err.Error())
+		case errors.Is(err, services.ErrNotCommentAuthor):
+			httputil.WriteError(w, http.StatusForbidden, "only the author can delete a comment")
+		default:
+			log.Printf("[Comments] Failed to delete comment %d: %v", id, err)
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to delete comment")
+		}
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, models.DeleteCommentResponse{ID: id, Purged: purged})
+}
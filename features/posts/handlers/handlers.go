@@ -8,15 +8,74 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func RegisterRoutes(router *mux.Router, ps *services.PostService, cs *services.CommentService, auth *middlewares.AuthMiddleware) {
-	likeHandler := NewLikeHandler(ps)
-	commentHandler := NewCommentHandler(ps, cs)
-	infoHandler := NewInfoHandler(ps)
+// Services groups the services backing the posts routes.
+type Services struct {
+	Posts       *services.PostService
+	Comments    *services.CommentService
+	Series      *services.SeriesService
+	Sync        *services.SyncService
+	Idempotency *services.IdempotencyService
+	Reconcile   *services.ReconcileService
+	Engagement  *services.EngagementService
+	Trending    *services.TrendingService
+	Related     *services.RelatedService
+	Views       *services.ViewService
+	// TrustProxy takes client IPs from X-Forwarded-For
+	TrustProxy bool
+}
+
+func RegisterRoutes(router *mux.Router, svc Services, auth *middlewares.AuthMiddleware) {
+	likeHandler := NewLikeHandler(svc.Posts)
+	reactionHandler := NewReactionHandler(svc.Posts)
+	commentHandler := NewCommentHandler(svc.Posts, svc.Comments, auth)
+	infoHandler := NewInfoHandler(svc.Posts)
+	seriesHandler := NewSeriesHandler(svc.Posts, svc.Series)
+	adminHandler := NewAdminHandler(svc.Posts, svc.Sync, svc.Reconcile)
+	listingHandler := NewListingHandler(svc.Posts, svc.Trending)
+	statsHandler := NewStatsHandler(svc.Posts, svc.Series, svc.Engagement)
+	relatedHandler := NewRelatedHandler(svc.Posts, svc.Related)
+	viewHandler := NewViewHandler(svc.Posts, svc.Views, svc.TrustProxy)
+
+	// write authenticates a handler and honours idempotency keys
+	write := func(h http.HandlerFunc) http.Handler {
+		return auth.RequireAuth(Idempotent(svc.Idempotency, h))
+	}
+
+	router.Handle("/api/admin/posts/sync", auth.RequireAdmin(http.HandlerFunc(adminHandler.SyncPosts))).Methods("POST", "OPTIONS")
+	router.Handle("/api/admin/posts/rename", auth.RequireAdmin(http.HandlerFunc(adminHandler.RenamePost))).Methods("POST", "OPTIONS")
+	router.Handle("/api/admin/posts/merge", auth.RequireAdmin(http.HandlerFunc(adminHandler.MergePosts))).Methods("POST", "OPTIONS")
+	router.Handle("/api/admin/integrity", auth.RequireAdmin(http.HandlerFunc(adminHandler.GetIntegrity))).Methods("GET", "OPTIONS")
+	router.Handle("/api/admin/integrity", auth.RequireAdmin(http.HandlerFunc(adminHandler.RunIntegrity))).Methods("POST")
+	router.Handle("/api/admin/comments/{id:[0-9]+}", auth.RequireAdmin(http.HandlerFunc(commentHandler.AdminDeleteComment))).Methods("DELETE", "OPTIONS")
+	router.Handle("/api/admin/comments/reports", auth.RequireAdmin(http.HandlerFunc(commentHandler.GetModerationQueue))).Methods("GET", "OPTIONS")
+	router.Handle("/api/admin/comments/{id:[0-9]+}/moderate", auth.RequireAdmin(http.HandlerFunc(commentHandler.ModerateComment))).Methods("POST", "OPTIONS")
+
+	router.HandleFunc("/api/posts", listingHandler.ListPosts).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/posts/trending", listingHandler.ListTrending).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/tags", listingHandler.ListTags).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/series", seriesHandler.ListSeries).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/series/{slug}", seriesHandler.GetSeries).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/series/{slug}/stats", statsHandler.GetSeriesStats).Methods("GET", "OPTIONS")
+	router.Handle("/api/comments/{id:[0-9]+}", auth.OptionalAuth(http.HandlerFunc(commentHandler.GetComment))).Methods("GET")
+	router.Handle("/api/comments/{id:[0-9]+}", write(commentHandler.EditComment)).Methods("PUT", "OPTIONS")
+	router.Handle("/api/comments/{id:[0-9]+}", write(commentHandler.DeleteComment)).Methods("DELETE")
+	router.Handle("/api/comments/{id:[0-9]+}/vote", write(commentHandler.VoteComment)).Methods("PUT", "DELETE")
+	router.Handle("/api/comments/{id:[0-9]+}/report", write(commentHandler.ReportComment)).Methods("POST", "OPTIONS")
+	router.Handle("/api/comments/{id:[0-9]+}/replies", auth.OptionalAuth(http.HandlerFunc(commentHandler.GetReplies))).Methods("GET", "OPTIONS")
+	router.Handle("/api/comments/{id:[0-9]+}/history", auth.OptionalAuth(http.HandlerFunc(commentHandler.GetCommentHistory))).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/wallets/{address}/likes", likeHandler.ListWalletLikes).Methods("GET", "OPTIONS")
 
 	// REST-compliant routes with path as URL parameter
 	// The {path:.*} pattern captures everything including slashes
-	router.HandleFunc("/api/posts/{path:.*}/info", infoHandler.GetPostInfo).Methods("GET", "OPTIONS")
-	router.Handle("/api/posts/{path:.*}/like", auth.RequireAuth(http.HandlerFunc(likeHandler.ToggleLike))).Methods("POST", "OPTIONS")
-	router.HandleFunc("/api/posts/{path:.*}/comments", commentHandler.GetComments).Methods("GET", "OPTIONS")
-	router.Handle("/api/posts/{path:.*}/comments", auth.RequireAuth(http.HandlerFunc(commentHandler.CreateComment))).Methods("POST", "OPTIONS")
+	router.Handle("/api/posts/{path:.*}/info", auth.OptionalAuth(http.HandlerFunc(infoHandler.GetPostInfo))).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/posts/{path:.*}/stats", statsHandler.GetPostStats).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/posts/{path:.*}/view", viewHandler.RecordView).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/posts/{path:.*}/related", relatedHandler.GetRelated).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/posts/{path:.*}/navigation", seriesHandler.GetNavigation).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/posts/{path:.*}/likes", likeHandler.ListLikes).Methods("GET", "OPTIONS")
+	router.Handle("/api/posts/{path:.*}/like", write(likeHandler.ToggleLike)).Methods("POST", "OPTIONS")
+	router.Handle("/api/posts/{path:.*}/like", write(likeHandler.SetLike)).Methods("PUT", "DELETE")
+	router.Handle("/api/posts/{path:.*}/reactions", write(reactionHandler.ToggleReaction)).Methods("POST", "OPTIONS")
+	router.Handle("/api/posts/{path:.*}/comments", auth.OptionalAuth(http.HandlerFunc(commentHandler.GetComments))).Methods("GET", "OPTIONS")
+	router.Handle("/api/posts/{path:.*}/comments", write(commentHandler.CreateComment)).Methods("POST", "OPTIONS")
 }
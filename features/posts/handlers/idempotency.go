@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"arkana/features/posts/services"
+	"arkana/features/wallet/middlewares"
+	"arkana/shared/httputil"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// maxIdempotencyKeyLength bounds client-chosen idempotency keys.
+const maxIdempotencyKeyLength = 255
+
+// Idempotent makes an authenticated write replayable. When the request
+// carries an Idempotency-Key header (or an "idempotency_key" payload field),
+// the first response is recorded and returned again for retries with the
+// same key instead of repeating the write. Requests without a key pass
+// through unchanged. Must run inside RequireAuth.
+func Idempotent(svc *services.IdempotencyService, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vr, ok := middlewares.GetVerifiedRequest(r.Context())
+		if !ok || svc == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			var payload struct {
+				IdempotencyKey string `json:"idempotency_key"`
+			}
+			json.Unmarshal(vr.Payload, &payload)
+			key = payload.IdempotencyKey
+		}
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			httputil.WriteError(w, http.StatusBadRequest, "idempotency key is too long")
+			return
+		}
+
+		stored, err := svc.Begin(vr.WalletID, key, requestFingerprint(r, vr))
+		switch {
+		case errors.Is(err, services.ErrIdempotencyMismatch):
+			httputil.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		case errors.Is(err, services.ErrIdempotencyInProgress):
+			httputil.WriteError(w, http.StatusConflict, err.Error())
+			return
+		case err != nil:
+			log.Printf("[Idempotency] Failed to reserve key for wallet %d: %v", vr.WalletID, err)
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to process idempotency key")
+			return
+		}
+
+		if stored != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.StatusCode)
+			w.Write(stored.Body)
+			return
+		}
+
+		// A panicking handler releases the key before the panic goes on
+		defer func() {
+			if p := recover(); p != nil {
+				if err := svc.Release(vr.WalletID, key); err != nil {
+					log.Printf("[Idempotency] Failed to release key for wallet %d: %v", vr.WalletID, err)
+				}
+				panic(p)
+			}
+		}()
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// Server errors are not recorded so that the client can retry
+		if rec.status >= http.StatusInternalServerError {
+			err = svc.Release(vr.WalletID, key)
+		} else {
+			err = svc.Complete(vr.WalletID, key, rec.status, rec.body.Bytes())
+		}
+		if err != nil {
+			log.Printf("[Idempotency] Failed to record key for wallet %d: %v", vr.WalletID, err)
+		}
+	})
+}
+
+// unfingerprintedFields are the payload fields left out of fingerprints:
+// a retry signed again after the original signature expired differs in
+// them only.
+var unfingerprintedFields = []string{"timestamp", "address", "idempotency_key"}
+
+// requestFingerprint identifies a write by method, path and a hash of the
+// signed action and payload, so that a key reused with a different body is
+// rejected rather than replayed.
+func requestFingerprint(r *http.Request, vr *middlewares.VerifiedRequest) string {
+	payload := []byte(vr.Payload)
+	var fields map[string]any
+	if err := json.Unmarshal(vr.Payload, &fields); err == nil {
+		for _, name := range unfingerprintedFields {
+			delete(fields, name)
+		}
+		// Maps are encoded with sorted keys, so field order doesn't matter
+		payload, _ = json.Marshal(fields)
+	}
+
+	sum := sha256.New()
+	sum.Write([]byte(vr.Action))
+	sum.Write([]byte{0})
+	sum.Write(payload)
+	return r.Method + " " + r.URL.Path + " " + hex.EncodeToString(sum.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy of its
+// status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"arkana/features/posts/services"
+	"arkana/features/wallet/middlewares"
 	"arkana/shared/httputil"
 	"errors"
 	"log"
@@ -19,11 +20,19 @@ func NewInfoHandler(ps *services.PostService) *InfoHandler {
 }
 
 // GetPostInfo handles GET /api/posts/{path}/info?wallet=xxx
+//
+// liked and my_reactions of a wallet that hides its likes are only returned
+// when the request is signed by that wallet.
 func (h *InfoHandler) GetPostInfo(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	path := vars["path"]
 	wallet := r.URL.Query().Get("wallet")
 
+	var viewer string
+	if vr, ok := middlewares.GetVerifiedRequest(r.Context()); ok {
+		viewer = vr.Address
+	}
+
 	log.Printf("[PostInfo] Request for path=%q wallet=%q", path, wallet)
 
 	if path == "" {
@@ -32,7 +41,14 @@ func (h *InfoHandler) GetPostInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	info, err := h.postService.GetPostInfo(path, wallet)
+	info, err := h.postService.GetPostInfo(path, wallet, viewer)
+	if errors.Is(err, services.ErrPostNotFound) {
+		// Unknown paths allowed by the auto-create policy are registered on first view
+		if _, err = h.postService.GetOrAutoCreate(path); err == nil {
+			log.Printf("[PostInfo] Auto-created post for path %s", path)
+			info, err = h.postService.GetPostInfo(path, wallet, viewer)
+		}
+	}
 	if err != nil {
 		if errors.Is(err, services.ErrPostNotFound) {
 			log.Printf("[PostInfo] Post not found: %s", path)
@@ -5,6 +5,7 @@ import (
 	"arkana/features/posts/services"
 	"arkana/features/wallet/middlewares"
 	"arkana/shared/httputil"
+	"encoding/json"
 	"errors"
 	"log"
 	"net/http"
@@ -43,7 +44,7 @@ func (h *LikeHandler) ToggleLike(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("[Like] Processing like for path: %s", path)
 
-	post, err := h.postService.GetByPath(path)
+	post, err := h.postService.GetOrAutoCreate(path)
 	if err != nil {
 		if errors.Is(err, services.ErrPostNotFound) {
 			log.Printf("[Like] Post not found: %s", path)
@@ -57,6 +58,19 @@ func (h *LikeHandler) ToggleLike(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("[Like] Post resolved: ID=%d, path=%s", post.ID, post.PathIdentifier)
 
+	// Clients that send the desired state get retry-safe semantics
+	var payload struct {
+		Desired *bool `json:"desired,omitempty"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	if payload.Desired != nil {
+		h.writeSetLike(w, post.ID, vr.WalletID, *payload.Desired)
+		return
+	}
+
 	liked, likeCount, err := h.postService.ToggleLike(post.ID, vr.WalletID)
 	if err != nil {
 		log.Printf("[Like] Failed to toggle like for post %d, wallet %d: %v", post.ID, vr.WalletID, err)
@@ -71,3 +85,110 @@ func (h *LikeHandler) ToggleLike(w http.ResponseWriter, r *http.Request) {
 		LikeCount: likeCount,
 	})
 }
+
+// SetLike handles PUT and DELETE /api/posts/{path}/like, signed with the
+// SET_LIKE action.
+//
+// PUT likes the post and DELETE removes the like; repeating either request
+// leaves the like in the same state.
+func (h *LikeHandler) SetLike(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if vr.Action != "SET_LIKE" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	path := mux.Vars(r)["path"]
+	if path == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "missing path in URL")
+		return
+	}
+
+	post, err := h.postService.GetOrAutoCreate(path)
+	if err != nil {
+		if errors.Is(err, services.ErrPostNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "post not found")
+			return
+		}
+		log.Printf("[Like] Failed to resolve post for path %s: %v", path, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to resolve post")
+		return
+	}
+
+	h.writeSetLike(w, post.ID, vr.WalletID, r.Method == http.MethodPut)
+}
+
+func (h *LikeHandler) writeSetLike(w http.ResponseWriter, postID, walletID int, liked bool) {
+	likeCount, err := h.postService.SetLike(postID, walletID, liked)
+	if err != nil {
+		log.Printf("[Like] Failed to set like=%v for post %d, wallet %d: %v", liked, postID, walletID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to set like")
+		return
+	}
+
+	log.Printf("[Like] Success: post=%d, wallet=%d, liked=%v, count=%d", postID, walletID, liked, likeCount)
+
+	httputil.WriteJSON(w, http.StatusOK, models.ToggleLikeResponse{
+		Liked:     liked,
+		LikeCount: likeCount,
+	})
+}
+
+// ListLikes handles GET /api/posts/{path}/likes?limit=&offset=
+func (h *LikeHandler) ListLikes(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := httputil.ParsePagination(r, 20, 100)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	path := mux.Vars(r)["path"]
+	post, err := h.postService.GetByPath(path)
+	if err != nil {
+		if errors.Is(err, services.ErrPostNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "post not found")
+			return
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to resolve post")
+		return
+	}
+
+	likes, err := h.postService.ListLikers(post.ID, limit, offset)
+	if err != nil {
+		log.Printf("[Like] Failed to list likes for post %d: %v", post.ID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to list likes")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, likes)
+}
+
+// ListWalletLikes handles GET /api/wallets/{address}/likes?limit=&offset=
+func (h *LikeHandler) ListWalletLikes(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := httputil.ParsePagination(r, 20, 100)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	likes, err := h.postService.ListWalletLikes(mux.Vars(r)["address"], limit, offset)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrWalletNotFound):
+			httputil.WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrLikesHidden):
+			httputil.WriteError(w, http.StatusForbidden, err.Error())
+		default:
+			log.Printf("[Like] Failed to list likes of wallet: %v", err)
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to list likes")
+		}
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, likes)
+}
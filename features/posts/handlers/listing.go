@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"arkana/features/posts/models"
+	"arkana/features/posts/services"
+	"arkana/shared/httputil"
+	"log"
+	"net/http"
+)
+
+type ListingHandler struct {
+	postService     *services.PostService
+	trendingService *services.TrendingService
+}
+
+func NewListingHandler(ps *services.PostService, ts *services.TrendingService) *ListingHandler {
+	return &ListingHandler{postService: ps, trendingService: ts}
+}
+
+// ListPosts handles GET /api/posts?tag=&author=&series=&sort=&limit=&offset=
+func (h *ListingHandler) ListPosts(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := httputil.ParsePagination(r, 20, 100)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	sort := query.Get("sort")
+	if sort != "" && sort != "published" && sort != "likes" {
+		httputil.WriteError(w, http.StatusBadRequest, "sort must be one of: published, likes")
+		return
+	}
+
+	posts, err := h.postService.List(models.PostFilter{
+		Tag:    query.Get("tag"),
+		Author: query.Get("author"),
+		Series: query.Get("series"),
+		Sort:   sort,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to list posts")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, posts)
+}
+
+// ListTags handles GET /api/tags
+func (h *ListingHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.postService.ListTags()
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to list tags")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, tags)
+}
+
+// ListTrending handles GET /api/posts/trending?window=7d&prefix=&limit=
+func (h *ListingHandler) ListTrending(w http.ResponseWriter, r *http.Request) {
+	limit, _, err := httputil.ParsePagination(r, 10, 50)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	windowParam := query.Get("window")
+	if windowParam == "" {
+		windowParam = "7d"
+	}
+	window, err := services.ParseWindow(windowParam)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	trending, err := h.trendingService.Trending(window, query.Get("prefix"), limit)
+	if err != nil {
+		log.Printf("[Trending] Failed to compute trending posts: %v", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to compute trending posts")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, trending)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"arkana/features/posts/services"
+	"arkana/features/wallet/middlewares"
+	"arkana/shared/httputil"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// viewer returns the wallet reading comments, if the request was signed.
+func (h *CommentHandler) viewer(r *http.Request) services.CommentViewer {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		return services.CommentViewer{}
+	}
+	return services.CommentViewer{WalletID: vr.WalletID, Moderator: h.auth.IsAdmin(vr.Address)}
+}
+
+// ReportComment handles POST /api/comments/{id}/report, signed with the
+// REPORT_COMMENT action. The payload carries the reason, one of
+// services.ReportReasons.
+func (h *CommentHandler) ReportComment(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if vr.Action != "REPORT_COMMENT" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	report, err := h.commentService.Report(id, h.viewer(r), payload.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCommentNotFound):
+			httputil.WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrCommentDeleted):
+			httputil.WriteError(w, http.StatusGone, err.Error())
+		case errors.Is(err, services.ErrInvalidReportReason):
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrSelfReport), errors.Is(err, services.ErrWalletBanned):
+			httputil.WriteError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrReportResolved):
+			httputil.WriteError(w, http.StatusConflict, err.Error())
+		default:
+			log.Printf("[Comments] Failed to report comment %d: %v", id, err)
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to report comment")
+		}
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, report)
+}
+
+// GetModerationQueue handles GET /api/admin/comments/reports?limit=&offset=,
+// signed with the GET_MODERATION_QUEUE action.
+func (h *CommentHandler) GetModerationQueue(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if vr.Action != "GET_MODERATION_QUEUE" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	limit, offset, err := httputil.ParsePagination(r, 50, 200)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	queue, err := h.commentService.ModerationQueue(limit, offset)
+	if err != nil {
+		log.Printf("[Comments] Failed to load moderation queue: %v", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to load moderation queue")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, queue)
+}
+
+// ModerateComment handles POST /api/admin/comments/{id}/moderate, signed
+// with the MODERATE_COMMENT action. The payload carries the decision
+// (hide, restore, delete or ban) and the reason for it.
+func (h *CommentHandler) ModerateComment(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if vr.Action != "MODERATE_COMMENT" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		Decision string `json:"decision"`
+		Reason   string `json:"reason"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	result, err := h.commentService.Moderate(id, vr.WalletID, payload.Decision, payload.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCommentNotFound):
+			httputil.WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrCommentDeleted):
+			httputil.WriteError(w, http.StatusGone, err.Error())
+		case errors.Is(err, services.ErrInvalidDecision), errors.Is(err, services.ErrInvalidModerationReason):
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			log.Printf("[Comments] Failed to moderate comment %d: %v", id, err)
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to moderate comment")
+		}
+		return
+	}
+
+	log.Printf("[Comments] Comment %d moderated by %s: %s (%s)", id, vr.Address, payload.Decision, payload.Reason)
+	httputil.WriteJSON(w, http.StatusOK, result)
+}
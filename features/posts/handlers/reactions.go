@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"arkana/features/posts/services"
+	"arkana/features/wallet/middlewares"
+	"arkana/shared/httputil"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type ReactionHandler struct {
+	postService *services.PostService
+}
+
+func NewReactionHandler(ps *services.PostService) *ReactionHandler {
+	return &ReactionHandler{postService: ps}
+}
+
+// ToggleReaction handles POST /api/posts/{path}/reactions, signed with the
+// TOGGLE_REACTION action.
+//
+// The like endpoint remains available and is equivalent to toggling the
+// 👍 reaction.
+func (h *ReactionHandler) ToggleReaction(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if vr.Action != "TOGGLE_REACTION" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	path := mux.Vars(r)["path"]
+	if path == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "missing path in URL")
+		return
+	}
+
+	var payload struct {
+		Reaction string `json:"reaction" validate:"required"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload: "+err.Error())
+		return
+	}
+
+	post, err := h.postService.GetOrAutoCreate(path)
+	if err != nil {
+		if errors.Is(err, services.ErrPostNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "post not found")
+			return
+		}
+		log.Printf("[Reaction] Failed to resolve post for path %s: %v", path, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to resolve post")
+		return
+	}
+
+	resp, err := h.postService.ToggleReaction(post.ID, vr.WalletID, payload.Reaction)
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownReaction) {
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("[Reaction] Failed to toggle %s for post %d, wallet %d: %v", payload.Reaction, post.ID, vr.WalletID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to toggle reaction")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, resp)
+}
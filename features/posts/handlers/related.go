@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"arkana/features/posts/models"
+	"arkana/features/posts/services"
+	"arkana/shared/httputil"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type RelatedHandler struct {
+	postService    *services.PostService
+	relatedService *services.RelatedService
+}
+
+func NewRelatedHandler(ps *services.PostService, rs *services.RelatedService) *RelatedHandler {
+	return &RelatedHandler{postService: ps, relatedService: rs}
+}
+
+// GetRelated handles GET /api/posts/{path}/related?limit=
+func (h *RelatedHandler) GetRelated(w http.ResponseWriter, r *http.Request) {
+	limit, _, err := httputil.ParsePagination(r, 5, 10)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	post, err := h.postService.GetByPath(mux.Vars(r)["path"])
+	if err != nil {
+		if errors.Is(err, services.ErrPostNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "post not found")
+			return
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to get post")
+		return
+	}
+
+	related, err := h.relatedService.Related(post.ID, limit)
+	if err != nil {
+		log.Printf("[Related] Failed to get related posts for %s: %v", post.PathIdentifier, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to get related posts")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, models.RelatedResponse{
+		Path:  post.PathIdentifier,
+		Posts: related,
+	})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"arkana/features/posts/services"
+	"arkana/shared/httputil"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type SeriesHandler struct {
+	postService   *services.PostService
+	seriesService *services.SeriesService
+}
+
+func NewSeriesHandler(ps *services.PostService, ss *services.SeriesService) *SeriesHandler {
+	return &SeriesHandler{postService: ps, seriesService: ss}
+}
+
+// ListSeries handles GET /api/series
+func (h *SeriesHandler) ListSeries(w http.ResponseWriter, r *http.Request) {
+	series, err := h.seriesService.List()
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to fetch series")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, series)
+}
+
+// GetSeries handles GET /api/series/{slug}
+func (h *SeriesHandler) GetSeries(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+
+	series, err := h.seriesService.GetDetail(slug)
+	if err != nil {
+		if errors.Is(err, services.ErrSeriesNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "series not found")
+			return
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to fetch series")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, series)
+}
+
+// GetNavigation handles GET /api/posts/{path}/navigation
+func (h *SeriesHandler) GetNavigation(w http.ResponseWriter, r *http.Request) {
+	path := mux.Vars(r)["path"]
+	if path == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "missing path in URL")
+		return
+	}
+
+	post, err := h.postService.GetByPath(path)
+	if err != nil {
+		if errors.Is(err, services.ErrPostNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "post not found")
+			return
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to resolve post")
+		return
+	}
+
+	nav, err := h.seriesService.Navigation(post)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to build navigation")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, nav)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"arkana/features/posts/services"
+	"arkana/shared/httputil"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultStatsDays is the range returned when no from date is given.
+const defaultStatsDays = 30
+
+type StatsHandler struct {
+	postService       *services.PostService
+	seriesService     *services.SeriesService
+	engagementService *services.EngagementService
+}
+
+func NewStatsHandler(ps *services.PostService, ss *services.SeriesService, es *services.EngagementService) *StatsHandler {
+	return &StatsHandler{postService: ps, seriesService: ss, engagementService: es}
+}
+
+// GetPostStats handles GET /api/posts/{path}/stats?from=&to=&interval=
+func (h *StatsHandler) GetPostStats(w http.ResponseWriter, r *http.Request) {
+	from, to, interval, ok := parseStatsQuery(w, r)
+	if !ok {
+		return
+	}
+
+	path := mux.Vars(r)["path"]
+	post, err := h.postService.GetByPath(path)
+	if err != nil {
+		if errors.Is(err, services.ErrPostNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "post not found")
+			return
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to resolve post")
+		return
+	}
+
+	stats, err := h.engagementService.PostStats(post.ID, from, to, interval)
+	if err != nil {
+		writeStatsError(w, err)
+		return
+	}
+	stats.Path = post.PathIdentifier
+
+	httputil.WriteJSON(w, http.StatusOK, stats)
+}
+
+// GetSeriesStats handles GET /api/series/{slug}/stats?from=&to=&interval=
+func (h *StatsHandler) GetSeriesStats(w http.ResponseWriter, r *http.Request) {
+	from, to, interval, ok := parseStatsQuery(w, r)
+	if !ok {
+		return
+	}
+
+	slug := mux.Vars(r)["slug"]
+	series, err := h.seriesService.GetBySlug(slug)
+	if err != nil {
+		if errors.Is(err, services.ErrSeriesNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "series not found")
+			return
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to fetch series")
+		return
+	}
+
+	stats, err := h.engagementService.SeriesStats(series.ID, from, to, interval)
+	if err != nil {
+		writeStatsError(w, err)
+		return
+	}
+	stats.Series = series.Slug
+
+	httputil.WriteJSON(w, http.StatusOK, stats)
+}
+
+// parseStatsQuery reads the from and to dates (YYYY-MM-DD) and the interval,
+// defaulting to daily buckets over the last 30 days. It writes a 400
+// response and returns false when the query is invalid.
+func parseStatsQuery(w http.ResponseWriter, r *http.Request) (from, to time.Time, interval string, ok bool) {
+	query := r.URL.Query()
+
+	to = time.Now().UTC().Truncate(24 * time.Hour)
+	if v := query.Get("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "to must be a date (YYYY-MM-DD)")
+			return from, to, "", false
+		}
+		to = t
+	}
+
+	from = to.AddDate(0, 0, 1-defaultStatsDays)
+	if v := query.Get("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "from must be a date (YYYY-MM-DD)")
+			return from, to, "", false
+		}
+		from = t
+	}
+
+	interval = query.Get("interval")
+	if interval == "" {
+		interval = "day"
+	}
+
+	return from, to, interval, true
+}
+
+func writeStatsError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidInterval):
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrInvalidStatsRange):
+		httputil.WriteError(w, http.StatusBadRequest, fmt.Sprintf("from must not be after to, and the range is limited to %d points", services.MaxEngagementPoints))
+	default:
+		log.Printf("[Stats] Failed to load engagement stats: %v", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to load stats")
+	}
+}
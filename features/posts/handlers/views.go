@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"arkana/features/posts/services"
+	"arkana/shared/httputil"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type ViewHandler struct {
+	postService *services.PostService
+	viewService *services.ViewService
+	trustProxy  bool
+}
+
+func NewViewHandler(ps *services.PostService, vs *services.ViewService, trustProxy bool) *ViewHandler {
+	return &ViewHandler{postService: ps, viewService: vs, trustProxy: trustProxy}
+}
+
+// RecordView handles POST /api/posts/{path}/view, the beacon sent when a
+// post is read. It answers 204 whether or not the view was counted, and
+// 404 for paths that are not posts yet.
+func (h *ViewHandler) RecordView(w http.ResponseWriter, r *http.Request) {
+	// Browsers prefetching or prerendering a page are not readers
+	if r.Header.Get("Sec-Purpose") != "" || r.Header.Get("Purpose") == "prefetch" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Anonymous beacons only count views of posts that already exist
+	post, err := h.postService.GetByPath(mux.Vars(r)["path"])
+	if err != nil {
+		if errors.Is(err, services.ErrPostNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "post not found")
+			return
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to resolve post")
+		return
+	}
+
+	if _, err := h.viewService.Record(post.ID, httputil.ClientIP(r, h.trustProxy), r.UserAgent()); err != nil {
+		log.Printf("[Views] Failed to record view of %s: %v", post.PathIdentifier, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to record view")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,24 @@
+package models
+
+// EngagementPoint is the engagement of one time bucket. Date is the first
+// day of the bucket (YYYY-MM-DD).
+type EngagementPoint struct {
+	Date           string `json:"date"`
+	Likes          int    `json:"likes"`
+	Unlikes        int    `json:"unlikes"`
+	Comments       int    `json:"comments"`
+	EngagedWallets int    `json:"engaged_wallets"`
+	// Views counts unique visitors per day, summed over the bucket
+	Views int `json:"views"`
+}
+
+// EngagementStats is the engagement time series of a post or series, with
+// a point for every bucket in range including empty ones.
+type EngagementStats struct {
+	Path     string            `json:"path,omitempty"`
+	Series   string            `json:"series,omitempty"`
+	Interval string            `json:"interval"`
+	From     string            `json:"from"`
+	To       string            `json:"to"`
+	Points   []EngagementPoint `json:"points"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// CounterDiscrepancy is a denormalized counter that disagrees with the rows
+// it counts. Key names the counted item for per-item counters (e.g. the
+// reaction of a reaction count).
+type CounterDiscrepancy struct {
+	Counter string `json:"counter"`
+	PostID  int    `json:"post_id"`
+	Path    string `json:"path"`
+	Key     string `json:"key,omitempty"`
+	Stored  int    `json:"stored"`
+	Actual  int    `json:"actual"`
+}
+
+// IntegrityReport is the outcome of a counter reconciliation run. Fixed
+// tells whether the discrepancies were corrected.
+type IntegrityReport struct {
+	CheckedAt     time.Time            `json:"checked_at"`
+	Counters      []string             `json:"counters"`
+	Fixed         bool                 `json:"fixed"`
+	Discrepancies []CounterDiscrepancy `json:"discrepancies"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// ManifestEntry describes a post as published by the frontend. Optional
+// fields left empty (or nil for Tags and Authors) are not touched by a sync.
+type ManifestEntry struct {
+	Path        string     `json:"path"`
+	Title       string     `json:"title,omitempty"`
+	Summary     string     `json:"summary,omitempty"`
+	Series      string     `json:"series,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Authors     []string   `json:"authors,omitempty"` // Wallet addresses
+	Locale      string     `json:"locale,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	ReadingTime int        `json:"reading_time,omitempty"` // Minutes
+}
+
+// ManifestSeries carries series metadata and the reading order of its parts.
+type ManifestSeries struct {
+	Slug        string   `json:"slug"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Parts       []string `json:"parts,omitempty"`
+}
+
+// Manifest is the full content registry ingested by a sync.
+type Manifest struct {
+	Posts  []ManifestEntry  `json:"posts"`
+	Series []ManifestSeries `json:"series,omitempty"`
+}
+
+// SyncChange lists which fields of an existing post a sync modified.
+type SyncChange struct {
+	Path   string   `json:"path"`
+	Fields []string `json:"fields"`
+}
+
+// SyncSeriesPart is a series part listed by the manifest.
+type SyncSeriesPart struct {
+	Series string `json:"series"`
+	Path   string `json:"path"`
+}
+
+// SyncConflict is a manifest path that is a former path of a renamed or
+// merged post, now at Post.
+type SyncConflict struct {
+	Path string `json:"path"`
+	Post string `json:"post"`
+}
+
+// SyncReport summarizes the outcome of a manifest sync. Orphaned posts are
+// registered in the database but missing from the manifest; they are only
+// reported, never deleted. Conflicts are skipped, so that no post shadows
+// an alias. InvalidParts are series parts that are not posts of their
+// series; they fail a sync that isn't a dry run.
+type SyncReport struct {
+	DryRun       bool             `json:"dry_run"`
+	Added        []string         `json:"added"`
+	Changed      []SyncChange     `json:"changed"`
+	Orphaned     []string         `json:"orphaned"`
+	Conflicts    []SyncConflict   `json:"conflicts"`
+	InvalidParts []SyncSeriesPart `json:"invalid_parts"`
+	Unchanged    int              `json:"unchanged"`
+}
@@ -6,8 +6,14 @@ type Post struct {
 	ID             int       `json:"id"`
 	PathIdentifier string    `json:"path_identifier"`
 	LikeCount      int       `json:"like_count"`
+	SeriesID       *int      `json:"series_id,omitempty"`
+	SeriesPosition *int      `json:"series_position,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+
+	// Locale is the locale prefix the post was requested with, if any.
+	// It is not stored on the post itself.
+	Locale string `json:"locale,omitempty"`
 }
 
 type Comment struct {
@@ -16,16 +22,119 @@ type Comment struct {
 	WalletID  int       `json:"wallet_id"`
 	ParentID  *int      `json:"parent_id,omitempty"`
 	Body      string    `json:"body"`
+	BodyHTML  string    `json:"body_html"`
+	Locale    string    `json:"locale,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
+	// Revision starts at 1 and grows with every edit
+	Revision  int        `json:"revision"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Upvotes   int        `json:"upvotes"`
+	Downvotes int        `json:"downvotes"`
+	Score     float64    `json:"score"`
+	// Status is visible or hidden by moderation
+	Status   string    `json:"status"`
+	Mentions []Mention `json:"mentions,omitempty"`
+}
+
+// Mention is a wallet mentioned in a comment. Offset and Length locate the
+// mention, @ included, in the Markdown body, in bytes.
+type Mention struct {
+	Offset      int    `json:"offset"`
+	Length      int    `json:"length"`
+	Address     string `json:"address"`
+	DisplayName string `json:"display_name,omitempty"`
 }
 
 // CommentResponse is the API response for a comment, including author info.
+// EditedAt is set once the comment has been edited; Revision must be sent
+// back when editing it. Body is the Markdown source and BodyHTML its
+// sanitized rendering. Score ranks the comment by its votes, and heavily
+// downvoted comments are marked Collapsed. Deleted comments that still
+// have replies are returned as tombstones, with a placeholder body and no
+// author.
 type CommentResponse struct {
-	ID            int       `json:"id"`
-	ParentID      *int      `json:"parent_id,omitempty"`
-	Body          string    `json:"body"`
-	CreatedAt     time.Time `json:"created_at"`
-	AuthorAddress string    `json:"author_address"`
+	ID            int        `json:"id"`
+	ParentID      *int       `json:"parent_id,omitempty"`
+	Body          string     `json:"body"`
+	BodyHTML      string     `json:"body_html"`
+	Locale        string     `json:"locale,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Revision      int        `json:"revision"`
+	EditedAt      *time.Time `json:"edited_at,omitempty"`
+	Deleted       bool       `json:"deleted,omitempty"`
+	AuthorAddress string     `json:"author_address"`
+	Upvotes       int        `json:"upvotes"`
+	Downvotes     int        `json:"downvotes"`
+	Score         float64    `json:"score"`
+	Collapsed     bool       `json:"collapsed,omitempty"`
+	Status        string     `json:"status"`
+	Mentions      []Mention  `json:"mentions,omitempty"`
+}
+
+// CommentVoteResponse is a wallet's vote on a comment (1, -1 or 0 when
+// withdrawn) and the comment's resulting tally.
+type CommentVoteResponse struct {
+	CommentID int     `json:"comment_id"`
+	Vote      int     `json:"vote"`
+	Upvotes   int     `json:"upvotes"`
+	Downvotes int     `json:"downvotes"`
+	Score     float64 `json:"score"`
+	Collapsed bool    `json:"collapsed"`
+}
+
+// CommentNode is a comment with its replies embedded. ReplyCount counts
+// all direct replies, including those not embedded; when some are missing
+// RepliesCursor continues them through the comment's replies endpoint.
+type CommentNode struct {
+	CommentResponse
+	ReplyCount    int           `json:"reply_count"`
+	Replies       []CommentNode `json:"replies"`
+	RepliesCursor string        `json:"replies_cursor,omitempty"`
+}
+
+// CommentTreeResponse is a page of comment threads. NextCursor fetches the
+// next page and is empty on the last one.
+type CommentTreeResponse struct {
+	Comments   []CommentNode `json:"comments"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// CommentContextResponse is a comment permalink: the comment with its
+// replies, the comments it answers from the thread root down, and a window
+// of its siblings in thread order. MoreBefore and MoreAfter tell whether
+// the thread has siblings beyond the window.
+type CommentContextResponse struct {
+	PostPath       string        `json:"post_path"`
+	Comment        CommentNode   `json:"comment"`
+	Ancestors      []CommentNode `json:"ancestors"`
+	SiblingsBefore []CommentNode `json:"siblings_before"`
+	SiblingsAfter  []CommentNode `json:"siblings_after"`
+	MoreBefore     bool          `json:"more_before"`
+	MoreAfter      bool          `json:"more_after"`
+}
+
+// DeleteCommentResponse tells whether a deleted comment was removed
+// entirely or left as a tombstone because it has replies.
+type DeleteCommentResponse struct {
+	ID     int  `json:"id"`
+	Purged bool `json:"purged"`
+}
+
+// CommentRevision is one version of a comment body. CreatedAt is when that
+// version was written.
+type CommentRevision struct {
+	Revision  int       `json:"revision"`
+	Body      string    `json:"body"`
+	BodyHTML  string    `json:"body_html"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CommentHistoryResponse lists every version of a comment, oldest first;
+// the last one is the current body.
+type CommentHistoryResponse struct {
+	CommentID int               `json:"comment_id"`
+	Revisions []CommentRevision `json:"revisions"`
 }
 
 // CommentsResponse wraps the list of comments for a post.
@@ -39,8 +148,205 @@ type ToggleLikeResponse struct {
 	LikeCount int  `json:"like_count"`
 }
 
+// Liker is a wallet that liked a post, with its public profile.
+type Liker struct {
+	Address     string    `json:"address"`
+	DisplayName string    `json:"display_name,omitempty"`
+	LikedAt     time.Time `json:"liked_at"`
+}
+
+// PostLikesResponse lists the wallets that liked a post. Total counts the
+// listed wallets; Hidden counts likes of wallets that keep them private.
+type PostLikesResponse struct {
+	Likes  []Liker `json:"likes"`
+	Total  int     `json:"total"`
+	Hidden int     `json:"hidden"`
+}
+
+// LikedPost is a post liked by a wallet.
+type LikedPost struct {
+	Path    string    `json:"path"`
+	Title   string    `json:"title,omitempty"`
+	LikedAt time.Time `json:"liked_at"`
+}
+
+type WalletLikesResponse struct {
+	Address string      `json:"address"`
+	Likes   []LikedPost `json:"likes"`
+	Total   int         `json:"total"`
+}
+
+type ReactionCount struct {
+	Reaction string `json:"reaction"`
+	Count    int    `json:"count"`
+}
+
+type ToggleReactionResponse struct {
+	Reaction  string          `json:"reaction"`
+	Reacted   bool            `json:"reacted"`
+	Reactions []ReactionCount `json:"reactions"`
+}
+
 type PostInfoResponse struct {
+	Path string `json:"path"`
+	// CanonicalPath is the current path of the post; it differs from Path
+	// when the post was requested through an alias and should be redirected
+	CanonicalPath string `json:"canonical_path"`
+	LikeCount     int    `json:"like_count"`
+	Liked         bool   `json:"liked"` // Only meaningful if wallet address was provided
+	// ViewCount is the sum of the post's unique daily views
+	ViewCount int    `json:"view_count"`
+	Series    string `json:"series,omitempty"`
+	// Locales lists the translations the post is published in
+	Locales []string `json:"locales"`
+	// Reactions counts every configured reaction; MyReactions lists those
+	// of the requesting wallet
+	Reactions   []ReactionCount `json:"reactions"`
+	MyReactions []string        `json:"my_reactions"`
+	PostMetadata
+}
+
+// PostMetadata is the editorial metadata of a post, as synced from the
+// content manifest. Authors are wallet addresses.
+type PostMetadata struct {
+	Title       string     `json:"title,omitempty"`
+	Summary     string     `json:"summary,omitempty"`
+	Tags        []string   `json:"tags"`
+	Authors     []string   `json:"authors"`
+	Locale      string     `json:"locale,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	ReadingTime int        `json:"reading_time,omitempty"` // Minutes
+}
+
+// PostSummary is a post as it appears in listings.
+type PostSummary struct {
+	Path      string `json:"path"`
+	LikeCount int    `json:"like_count"`
+	Series    string `json:"series,omitempty"`
+	PostMetadata
+}
+
+type PostListResponse struct {
+	Posts []PostSummary `json:"posts"`
+	Total int           `json:"total"`
+}
+
+// PostFilter narrows a post listing. Empty fields match every post.
+type PostFilter struct {
+	Tag    string
+	Author string
+	Series string
+	Sort   string // "published" (default) or "likes"
+	Limit  int
+	Offset int
+}
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+type TagsResponse struct {
+	Tags []TagCount `json:"tags"`
+}
+
+type Series struct {
+	ID          int       `json:"id"`
+	Slug        string    `json:"slug"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SeriesPart is a post as it appears in the ordered list of a series.
+type SeriesPart struct {
 	Path      string `json:"path"`
+	Position  int    `json:"position"`
 	LikeCount int    `json:"like_count"`
-	Liked     bool   `json:"liked"` // Only meaningful if wallet address was provided
-}
\ No newline at end of file
+}
+
+// SeriesStats aggregates engagement across every post in a series.
+// Readers counts distinct wallets that liked or commented on any part.
+type SeriesStats struct {
+	PostCount     int `json:"post_count"`
+	TotalLikes    int `json:"total_likes"`
+	TotalComments int `json:"total_comments"`
+	Readers       int `json:"readers"`
+}
+
+type SeriesSummary struct {
+	Series
+	PostCount int `json:"post_count"`
+}
+
+type SeriesListResponse struct {
+	Series []SeriesSummary `json:"series"`
+}
+
+type SeriesResponse struct {
+	Series
+	Parts []SeriesPart `json:"parts"`
+	Stats SeriesStats  `json:"stats"`
+}
+
+// PostNavigationResponse describes where a post sits inside its series.
+// Series, Previous and Next are nil when not applicable.
+type PostNavigationResponse struct {
+	Path     string      `json:"path"`
+	Series   *Series     `json:"series"`
+	Position int         `json:"position,omitempty"`
+	Total    int         `json:"total,omitempty"`
+	Previous *SeriesPart `json:"previous"`
+	Next     *SeriesPart `json:"next"`
+}
+
+// LocaleFold is a post stored under a localized path and the canonical
+// path it was folded into. Merged tells whether it was merged into an
+// existing post there rather than renamed.
+type LocaleFold struct {
+	Path      string `json:"path"`
+	Canonical string `json:"canonical"`
+	Locale    string `json:"locale"`
+	Merged    bool   `json:"merged"`
+}
+
+// FoldReport lists the posts folded into their canonical post.
+type FoldReport struct {
+	DryRun bool         `json:"dry_run"`
+	Folded []LocaleFold `json:"folded"`
+}
+
+// CommentReportResponse acknowledges a wallet's report of a comment.
+type CommentReportResponse struct {
+	CommentID int    `json:"comment_id"`
+	Reason    string `json:"reason"`
+}
+
+// ReportedComment is an entry of the moderation queue: a comment with open
+// reports, counted by reason.
+type ReportedComment struct {
+	CommentID      int            `json:"comment_id"`
+	PostPath       string         `json:"post_path"`
+	AuthorAddress  string         `json:"author_address"`
+	Body           string         `json:"body"`
+	Status         string         `json:"status"`
+	Reports        int            `json:"reports"`
+	Reasons        map[string]int `json:"reasons"`
+	LastReportedAt time.Time      `json:"last_reported_at"`
+}
+
+type ModerationQueueResponse struct {
+	Comments []ReportedComment `json:"comments"`
+	Total    int               `json:"total"`
+}
+
+// ModerationResponse is the outcome of a moderator action. Status is the
+// comment's new status; a deleted comment has none.
+type ModerationResponse struct {
+	CommentID int    `json:"comment_id"`
+	Decision  string `json:"decision"`
+	Status    string `json:"status,omitempty"`
+	Purged    bool   `json:"purged,omitempty"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// TrendingPost is a post ranked by its recent, time-decayed engagement.
+type TrendingPost struct {
+	PostSummary
+	Score          float64 `json:"score"`
+	RecentLikes    int     `json:"recent_likes"`
+	RecentComments int     `json:"recent_comments"`
+}
+
+type TrendingResponse struct {
+	Window     string         `json:"window"`
+	ComputedAt time.Time      `json:"computed_at"`
+	Posts      []TrendingPost `json:"posts"`
+}
+
+// RelatedPost is a post read by the same wallets as another post. Support
+// is the number of wallets that engaged with both.
+type RelatedPost struct {
+	PostSummary
+	Score   float64 `json:"score"`
+	Support int     `json:"support"`
+}
+
+type RelatedResponse struct {
+	Path  string        `json:"path"`
+	Posts []RelatedPost `json:"posts"`
+}
@@ -1,17 +1,112 @@
 package posts
 
 import (
+	"arkana/config"
 	"arkana/features/posts/handlers"
 	"arkana/features/posts/services"
 	"arkana/features/wallet/middlewares"
+	walletservices "arkana/features/wallet/services"
+	"arkana/shared/jobs"
+	"context"
 	"database/sql"
+	"log"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
-func Initialize(router *mux.Router, db *sql.DB, auth *middlewares.AuthMiddleware) {
+// manifestIndexTTL is how long the content manifest is trusted before it is
+// reloaded to confirm newly published posts.
+const manifestIndexTTL = 5 * time.Minute
+
+// ensCacheTTL is how long resolved ENS names are trusted.
+const ensCacheTTL = time.Hour
+
+// Initialize registers the posts routes and background jobs. It returns the
+// post service so that other modules can resolve post paths, and the
+// comment service so that they can receive comment events.
+func Initialize(ctx context.Context, router *mux.Router, db *sql.DB, cfg *config.Config, auth *middlewares.AuthMiddleware) (*services.PostService, *services.CommentService) {
 	postService := services.NewPostService(db)
+	postService.SetSupportedLocales(cfg.SupportedLocales)
+	postService.SetReactions(cfg.Reactions)
 	commentService := services.NewCommentService(db)
+	commentService.SetEditWindow(cfg.CommentEditWindow)
+	commentService.SetReportThreshold(cfg.CommentReportThreshold)
+	if cfg.ENSRPCURL != "" {
+		resolver, err := walletservices.NewENSResolver(cfg.ENSRPCURL, ensCacheTTL)
+		if err != nil {
+			log.Printf("[Posts] ENS mentions disabled: %v", err)
+		} else {
+			commentService.SetNameResolver(resolver)
+		}
+	}
+
+	if n, err := commentService.RenderStale(); err != nil {
+		log.Printf("[Posts] Failed to render comments: %v", err)
+	} else if n > 0 {
+		log.Printf("[Posts] Re-rendered %d comments", n)
+	}
+
+	if len(cfg.AutoCreatePaths) > 0 || cfg.AutoCreateFromManifest {
+		var manifest *services.ManifestIndex
+		if cfg.AutoCreateFromManifest && cfg.ContentManifest != "" {
+			manifest = services.NewManifestIndex(cfg.ContentManifest, cfg.ContentPathPrefix, manifestIndexTTL)
+		} else if cfg.AutoCreateFromManifest {
+			log.Printf("[Posts] AUTO_CREATE_FROM_MANIFEST is set but CONTENT_MANIFEST is empty")
+		}
+		policy, err := services.NewAutoCreatePolicy(cfg.AutoCreatePaths, manifest)
+		if err != nil {
+			log.Printf("[Posts] Auto-create disabled: %v", err)
+		} else {
+			postService.SetAutoCreatePolicy(policy)
+		}
+	}
+	seriesService := services.NewSeriesService(db)
+	seriesService.SetSupportedLocales(cfg.SupportedLocales)
+
+	// Link posts created outside the API (e.g. by the seed script) to their series
+	if n, err := seriesService.AssignFromPaths(); err != nil {
+		log.Printf("[Posts] Failed to assign series from paths: %v", err)
+	} else if n > 0 {
+		log.Printf("[Posts] Assigned %d posts to series", n)
+	}
+
+	syncService := services.NewSyncService(db, cfg.ContentManifest, cfg.ContentPathPrefix)
+
+	reconcileService := services.NewReconcileService(db)
+	jobs.Every(ctx, "reconcile-counters", cfg.ReconcileInterval, func() error {
+		report, err := reconcileService.Run(cfg.ReconcileFix)
+		if err == nil && len(report.Discrepancies) > 0 {
+			log.Printf("[Reconcile] Found %d counter discrepancies (fixed=%v)", len(report.Discrepancies), report.Fixed)
+		}
+		return err
+	})
+
+	engagementService := services.NewEngagementService(db)
+	jobs.Every(ctx, "engagement-rollups", cfg.RollupInterval, engagementService.Aggregate)
+
+	trendingService := services.NewTrendingService(postService, cfg.TrendingHalfLife, cfg.TrendingRefresh)
+	jobs.Every(ctx, "trending-refresh", cfg.TrendingRefresh, trendingService.Refresh)
+
+	relatedService := services.NewRelatedService(postService, cfg.RelatedMinSupport, cfg.RelatedPerSeries)
+	jobs.Every(ctx, "related-posts", cfg.RelatedRefresh, relatedService.Recompute)
+
+	viewService := services.NewViewService(db)
+	jobs.Every(ctx, "view-salt-rotation", time.Hour, viewService.Rotate)
+
+	handlers.RegisterRoutes(router, handlers.Services{
+		Posts:       postService,
+		Comments:    commentService,
+		Series:      seriesService,
+		Sync:        syncService,
+		Idempotency: services.NewIdempotencyService(db),
+		Reconcile:   reconcileService,
+		Engagement:  engagementService,
+		Trending:    trendingService,
+		Related:     relatedService,
+		Views:       viewService,
+		TrustProxy:  cfg.TrustProxy,
+	}, auth)
 
-	handlers.RegisterRoutes(router, postService, commentService, auth)
+	return postService, commentService
 }
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"fmt"
+	"log"
+	"path"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// AutoCreatePolicy decides which unknown paths may be registered as posts on
+// their first interaction. A path is allowed when it matches one of Patterns
+// or, if Manifest is set, is listed in the content manifest.
+type AutoCreatePolicy struct {
+	// Patterns are path.Match globs, so "*" does not cross "/"
+	Patterns []string
+	Manifest *ManifestIndex
+}
+
+// NewAutoCreatePolicy validates the given patterns and builds a policy.
+func NewAutoCreatePolicy(patterns []string, manifest *ManifestIndex) (*AutoCreatePolicy, error) {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid auto-create pattern %q: %w", p, err)
+		}
+	}
+	return &AutoCreatePolicy{Patterns: patterns, Manifest: manifest}, nil
+}
+
+// maxAutoCreatePathLength bounds paths accepted for auto-creation.
+const maxAutoCreatePathLength = 200
+
+// Allows reports whether the canonical path may be auto-created. Paths that
+// are not in clean form (e.g. containing "..") are always rejected.
+func (p *AutoCreatePolicy) Allows(canonical string) (bool, error) {
+	if canonical == "" || len(canonical) > maxAutoCreatePathLength || path.Clean(canonical) != canonical {
+		return false, nil
+	}
+	for _, pattern := range p.Patterns {
+		if ok, _ := path.Match(pattern, canonical); ok {
+			return true, nil
+		}
+	}
+	if p.Manifest != nil {
+		return p.Manifest.Contains(canonical)
+	}
+	return false, nil
+}
+
+// ManifestIndex caches the set of paths listed in the content manifest,
+// reloading it once it is older than the configured TTL.
+type ManifestIndex struct {
+	source     string
+	pathPrefix string
+	ttl        time.Duration
+
+	loads singleflight.Group
+
+	mu       sync.Mutex
+	paths    map[string]bool
+	loadedAt time.Time
+	// failures counts consecutive failed loads; no reload is attempted
+	// before retryAt
+	failures int
+	retryAt  time.Time
+	lastErr  error
+}
+
+// Delays between manifest loads after consecutive failures, doubling from
+// minManifestRetry up to the index TTL.
+const (
+	minManifestRetry = 5 * time.Second
+	maxManifestRetry = 10 * time.Minute
+)
+
+func NewManifestIndex(source, pathPrefix string, ttl time.Duration) *ManifestIndex {
+	return &ManifestIndex{source: source, pathPrefix: pathPrefix, ttl: ttl}
+}
+
+// Contains reports whether the manifest lists the given path. When a reload
+// fails the previously loaded paths keep being used, and further loads back
+// off until the failures stop.
+func (i *ManifestIndex) Contains(canonical string) (bool, error) {
+	i.mu.Lock()
+	paths, err := i.paths, i.lastErr
+	due := (paths == nil || time.Since(i.loadedAt) > i.ttl) && !time.Now().Before(i.retryAt)
+	i.mu.Unlock()
+
+	if due {
+		// Concurrent callers share a single load, made without holding mu
+		result, loadErr, _ := i.loads.Do("manifest", func() (any, error) {
+			return i.reload()
+		})
+		if loadErr == nil {
+			paths = result.(map[string]bool)
+		}
+		err = loadErr
+	}
+
+	if paths == nil {
+		return false, err
+	}
+	return paths[canonical], nil
+}
+
+func (i *ManifestIndex) reload() (map[string]bool, error) {
+	manifest, err := LoadManifest(i.source, i.pathPrefix)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if err != nil {
+		i.failures++
+		i.retryAt = time.Now().Add(i.retryDelay())
+		i.lastErr = err
+		if i.paths != nil {
+			log.Printf("[Posts] Failed to reload content manifest, using cached paths: %v", err)
+		}
+		return nil, err
+	}
+
+	paths := make(map[string]bool, len(manifest.Posts))
+	for _, entry := range manifest.Posts {
+		paths[entry.Path] = true
+	}
+	i.paths = paths
+	i.loadedAt = time.Now()
+	i.failures = 0
+	i.retryAt = time.Time{}
+	i.lastErr = nil
+	return paths, nil
+}
+
+// retryDelay returns the back-off after the current number of failures.
+// Callers must hold mu.
+func (i *ManifestIndex) retryDelay() time.Duration {
+	limit := min(i.ttl, maxManifestRetry)
+	delay := minManifestRetry
+	for n := 1; n < i.failures && delay < limit; n++ {
+		delay *= 2
+	}
+	return min(delay, max(limit, minManifestRetry))
+}
+
+// SetAutoCreatePolicy enables auto-creation of unknown posts in
+// GetOrAutoCreate. A nil policy disables it.
+func (s *PostService) SetAutoCreatePolicy(policy *AutoCreatePolicy) {
+	s.autoCreate = policy
+}
+
+// GetOrAutoCreate finds a post by path, creating it when the path is
+// unknown but allowed by the auto-create policy.
+// Returns ErrPostNotFound for unknown paths the policy rejects, including
+// those it can't check because the manifest could not be loaded.
+func (s *PostService) GetOrAutoCreate(path string) (*models.Post, error) {
+	p, err := s.GetByPath(path)
+	if err != ErrPostNotFound || s.autoCreate == nil {
+		return p, err
+	}
+
+	_, canonical := s.SplitLocale(path)
+	allowed, err := s.autoCreate.Allows(canonical)
+	if err != nil {
+		log.Printf("[Posts] Content manifest unavailable, not auto-creating %s: %v", canonical, err)
+	}
+	if !allowed {
+		return nil, ErrPostNotFound
+	}
+
+	return s.GetOrCreateByPath(path)
+}
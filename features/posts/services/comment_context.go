@@ -0,0 +1,102 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"database/sql"
+	"slices"
+)
+
+// commentAncestorsQuery selects the comments a comment (?1) answers that
+// the viewer (?3, ?4) may read, from the thread root down, counting replies
+// in the locale ?2.
+var commentAncestorsQuery = `
+	WITH RECURSIVE chain(id, depth) AS (
+		SELECT parent_id, 1 FROM comments WHERE id = ?1
+		UNION ALL
+		SELECT c.parent_id, chain.depth + 1 FROM comments c JOIN chain ON c.id = chain.id
+	)
+	SELECT n.* FROM (` + commentTreeQuery("?2", "?3", "?4") + `c.id IN (SELECT id FROM chain)) n
+	JOIN chain ON chain.id = n.id
+	ORDER BY chain.depth DESC
+`
+
+// Context returns the permalink view of a comment: the comment with its
+// replies nested like Tree, its ancestors, and up to siblings comments on
+// each side of it in opts.Sort order. The locale filters the siblings and
+// replies only, so the comment itself is always returned.
+// Returns ErrCommentNotFound if the comment doesn't exist or is hidden from
+// opts.Viewer.
+func (s *CommentService) Context(commentID, siblings int, opts CommentTreeOptions) (*models.CommentContextResponse, error) {
+	sort, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+	siblings = min(max(siblings, 0), MaxCommentSiblings)
+
+	resp := &models.CommentContextResponse{}
+	var postID int
+	err = s.db.QueryRow(`
+		SELECT c.post_id, p.path_identifier
+		FROM comments c
+		JOIN posts p ON p.id = c.post_id
+		WHERE c.id = ?
+	`, commentID).Scan(&postID, &resp.PostPath)
+	if err == sql.ErrNoRows {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	viewer := []any{opts.Viewer.Moderator, opts.Viewer.WalletID}
+	nodes, err := s.scanNodes(commentTreeQuery("?2", "?3", "?4")+"c.id = ?1", append([]any{commentID, opts.Locale}, viewer...)...)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, ErrCommentNotFound
+	}
+	if err := s.embedReplies([]*models.CommentNode{&nodes[0]}, sort, opts, opts.Depth-1); err != nil {
+		return nil, err
+	}
+	resp.Comment = nodes[0]
+
+	if resp.Ancestors, err = s.scanNodes(commentAncestorsQuery, append([]any{commentID, opts.Locale}, viewer...)...); err != nil {
+		return nil, err
+	}
+
+	filter, id := "c.post_id = ?4 AND c.parent_id IS NULL", postID
+	if resp.Comment.ParentID != nil {
+		filter, id = "c.parent_id = ?4", *resp.Comment.ParentID
+	}
+	key := sort.cursorKey(sort.key(resp.Comment))
+
+	resp.SiblingsBefore, resp.MoreBefore, err = s.siblingWindow(filter, id, sort.before, sort.reverse, key, commentID, opts, siblings)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(resp.SiblingsBefore)
+
+	resp.SiblingsAfter, resp.MoreAfter, err = s.siblingWindow(filter, id, sort.after, sort.order, key, commentID, opts, siblings)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// siblingWindow returns up to n comments matching filter that follow the
+// comment at (key, commentID) under the given keyset condition and order,
+// and whether more follow them, in the locale of opts and among those
+// opts.Viewer may read.
+func (s *CommentService) siblingWindow(filter string, id int, cond, order string, key any, commentID int, opts CommentTreeOptions, n int) ([]models.CommentNode, bool, error) {
+	query := "SELECT * FROM (" + commentTreeQuery("?3", "?6", "?7") + filter + " AND (?3 = '' OR c.locale = ?3)) WHERE " +
+		cond + " ORDER BY " + order + " LIMIT ?5"
+	nodes, err := s.scanNodes(query, key, commentID, opts.Locale, id, n+1, opts.Viewer.Moderator, opts.Viewer.WalletID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(nodes) > n {
+		return nodes[:n], true, nil
+	}
+	return nodes, false, nil
+}
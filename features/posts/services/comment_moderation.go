@@ -0,0 +1,310 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Comment statuses. Deletion is tracked separately, by deleted_at.
+const (
+	CommentVisible = "visible"
+	CommentHidden  = "hidden"
+)
+
+// countedComments selects the comments counted as engagement, leaving out
+// hidden and deleted ones.
+const countedComments = "status = 'visible' AND deleted_at IS NULL"
+
+// ReportReasons are the reasons a comment may be reported for.
+var ReportReasons = []string{"spam", "harassment", "hate", "misinformation", "off_topic", "other"}
+
+// Moderator decisions on a comment. Banning the author also hides the
+// comment.
+const (
+	ModerationHide    = "hide"
+	ModerationRestore = "restore"
+	ModerationDelete  = "delete"
+	ModerationBan     = "ban"
+)
+
+var moderationDecisions = []string{ModerationHide, ModerationRestore, ModerationDelete, ModerationBan}
+
+// MaxModerationReasonLength bounds the reason recorded with a moderator
+// action.
+const MaxModerationReasonLength = 500
+
+var (
+	ErrInvalidReportReason     = fmt.Errorf("reason must be one of: %s", strings.Join(ReportReasons, ", "))
+	ErrSelfReport              = errors.New("cannot report your own comment")
+	ErrReportResolved          = errors.New("report was already reviewed by a moderator")
+	ErrWalletBanned            = errors.New("wallet is banned from commenting")
+	ErrInvalidDecision         = fmt.Errorf("decision must be one of: %s", strings.Join(moderationDecisions, ", "))
+	ErrInvalidModerationReason = fmt.Errorf("reason must be 1 to %d characters", MaxModerationReasonLength)
+)
+
+// CommentViewer is the wallet reading comments, which decides whether
+// hidden comments are shown. The zero value is an anonymous reader.
+type CommentViewer struct {
+	WalletID  int
+	Moderator bool
+}
+
+// hides tells whether c is hidden from the viewer: hidden comments are
+// only shown to their author and to moderators.
+func (v CommentViewer) hides(c *models.Comment) bool {
+	return c.Status == CommentHidden && !v.Moderator && c.WalletID != v.WalletID
+}
+
+// SetReportThreshold sets the number of wallets whose open reports hide a
+// comment. Zero leaves hiding to moderators.
+func (s *CommentService) SetReportThreshold(n int) {
+	s.reportThreshold = n
+}
+
+// checkBanned returns ErrWalletBanned if the wallet was banned.
+func (s *CommentService) checkBanned(walletID int) error {
+	var banned bool
+	err := s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM comment_bans WHERE wallet_id = ?)", walletID).Scan(&banned)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return ErrWalletBanned
+	}
+	return nil
+}
+
+// Report records a wallet's report of a comment; reporting again replaces
+// the reason until a moderator reviews it. Once reports from
+// reportThreshold wallets are open, the comment is hidden until a moderator
+// reviews it.
+// Returns ErrCommentNotFound if the comment is hidden from the reporter, and
+// ErrInvalidReportReason, ErrSelfReport, ErrWalletBanned or
+// ErrReportResolved when the report is not allowed.
+func (s *CommentService) Report(commentID int, reporter CommentViewer, reason string) (*models.CommentReportResponse, error) {
+	if !slices.Contains(ReportReasons, reason) {
+		return nil, ErrInvalidReportReason
+	}
+
+	c, err := s.getByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if reporter.hides(c) {
+		return nil, ErrCommentNotFound
+	}
+	if c.DeletedAt != nil {
+		return nil, ErrCommentDeleted
+	}
+	if c.WalletID == reporter.WalletID {
+		return nil, ErrSelfReport
+	}
+	if err := s.checkBanned(reporter.WalletID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// A resolved report stays resolved, so the wallets a moderator overruled
+	// can't hide the comment again.
+	result, err := tx.Exec(`
+		INSERT INTO comment_reports (comment_id, wallet_id, reason) VALUES (?, ?, ?)
+		ON CONFLICT (comment_id, wallet_id) DO UPDATE
+		SET reason = excluded.reason, created_at = CURRENT_TIMESTAMP
+		WHERE comment_reports.resolved_at IS NULL
+	`, commentID, reporter.WalletID, reason)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrReportResolved
+	}
+
+	if s.reportThreshold > 0 && c.Status == CommentVisible {
+		var open int
+		err := tx.QueryRow(
+			"SELECT COUNT(*) FROM comment_reports WHERE comment_id = ? AND resolved_at IS NULL", commentID,
+		).Scan(&open)
+		if err != nil {
+			return nil, err
+		}
+		if open >= s.reportThreshold {
+			if _, err := tx.Exec("UPDATE comments SET status = ? WHERE id = ?", CommentHidden, commentID); err != nil {
+				return nil, err
+			}
+			if err := logModeration(tx, c, nil, ModerationHide, fmt.Sprintf("reported by %d wallets", open)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &models.CommentReportResponse{CommentID: commentID, Reason: reason}, tx.Commit()
+}
+
+// ModerationQueue returns a page of the comments with open reports, the
+// most reported first.
+func (s *CommentService) ModerationQueue(limit, offset int) (*models.ModerationQueueResponse, error) {
+	resp := &models.ModerationQueueResponse{Comments: []models.ReportedComment{}}
+	err := s.db.QueryRow(
+		"SELECT COUNT(DISTINCT comment_id) FROM comment_reports WHERE resolved_at IS NULL",
+	).Scan(&resp.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT c.id, p.path_identifier, w.address, c.body, c.status, COUNT(*) AS reports, MAX(r.created_at) AS last_reported
+		FROM comment_reports r
+		JOIN comments c ON c.id = r.comment_id
+		JOIN posts p ON p.id = c.post_id
+		JOIN wallets w ON w.id = c.wallet_id
+		WHERE r.resolved_at IS NULL
+		GROUP BY c.id
+		ORDER BY reports DESC, last_reported DESC, c.id
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := map[int]*models.ReportedComment{}
+	for rows.Next() {
+		var rc models.ReportedComment
+		var lastReported string
+		err := rows.Scan(&rc.CommentID, &rc.PostPath, &rc.AuthorAddress, &rc.Body, &rc.Status, &rc.Reports, &lastReported)
+		if err != nil {
+			return nil, err
+		}
+		// Aggregates lose the column type, so the time comes back as text
+		if rc.LastReportedAt, err = time.Parse(sqliteTimeLayout, lastReported); err != nil {
+			return nil, err
+		}
+		rc.Reasons = map[string]int{}
+		resp.Comments = append(resp.Comments, rc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(resp.Comments) == 0 {
+		return resp, nil
+	}
+
+	placeholders := make([]string, len(resp.Comments))
+	args := make([]any, len(resp.Comments))
+	for i := range resp.Comments {
+		placeholders[i] = "?"
+		args[i] = resp.Comments[i].CommentID
+		byID[resp.Comments[i].CommentID] = &resp.Comments[i]
+	}
+	reasons, err := s.db.Query(`
+		SELECT comment_id, reason, COUNT(*) FROM comment_reports
+		WHERE resolved_at IS NULL AND comment_id IN (`+strings.Join(placeholders, ", ")+`)
+		GROUP BY comment_id, reason
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer reasons.Close()
+
+	for reasons.Next() {
+		var id, n int
+		var reason string
+		if err := reasons.Scan(&id, &reason, &n); err != nil {
+			return nil, err
+		}
+		byID[id].Reasons[reason] = n
+	}
+	return resp, reasons.Err()
+}
+
+// Moderate applies a moderator's decision to a comment and records it with
+// the reason. Every decision resolves the open reports of the comment.
+// Returns ErrInvalidDecision or ErrInvalidModerationReason for invalid
+// input, and ErrCommentNotFound or ErrCommentDeleted.
+func (s *CommentService) Moderate(commentID, moderatorID int, decision, reason string) (*models.ModerationResponse, error) {
+	if !slices.Contains(moderationDecisions, decision) {
+		return nil, ErrInvalidDecision
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > MaxModerationReasonLength {
+		return nil, ErrInvalidModerationReason
+	}
+
+	c, err := s.getByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if c.DeletedAt != nil {
+		return nil, ErrCommentDeleted
+	}
+	resp := &models.ModerationResponse{CommentID: commentID, Decision: decision}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if decision == ModerationDelete {
+		// Deleting drops the reports along with the body
+		if resp.Purged, err = removeComment(tx, c, "admin"); err != nil {
+			return nil, err
+		}
+		if err := logModeration(tx, c, &moderatorID, decision, reason); err != nil {
+			return nil, err
+		}
+		return resp, tx.Commit()
+	}
+
+	resp.Status = CommentHidden
+	if decision == ModerationRestore {
+		resp.Status = CommentVisible
+	}
+
+	if _, err := tx.Exec("UPDATE comments SET status = ? WHERE id = ?", resp.Status, commentID); err != nil {
+		return nil, err
+	}
+	if decision == ModerationBan {
+		_, err := tx.Exec(`
+			INSERT INTO comment_bans (wallet_id, moderator_id, reason) VALUES (?, ?, ?)
+			ON CONFLICT (wallet_id) DO UPDATE
+			SET moderator_id = excluded.moderator_id, reason = excluded.reason, created_at = CURRENT_TIMESTAMP
+		`, c.WalletID, moderatorID, reason)
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err = tx.Exec(
+		"UPDATE comment_reports SET resolved_at = ? WHERE comment_id = ? AND resolved_at IS NULL",
+		time.Now().UTC(), commentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := logModeration(tx, c, &moderatorID, decision, reason); err != nil {
+		return nil, err
+	}
+
+	return resp, tx.Commit()
+}
+
+// logModeration records an action on a comment. moderatorID is nil for
+// comments hidden by reports.
+func logModeration(db execer, c *models.Comment, moderatorID *int, action, reason string) error {
+	_, err := db.Exec(
+		"INSERT INTO comment_moderation_log (comment_id, author_id, moderator_id, action, reason) VALUES (?, ?, ?, ?, ?)",
+		c.ID, c.WalletID, moderatorID, action, reason,
+	)
+	return err
+}
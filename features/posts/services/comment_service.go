@@ -5,33 +5,65 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // MaxCommentLength is the maximum allowed length for a comment body.
 const MaxCommentLength = 1000
 
-var ErrCommentTooLong = errors.New("comment exceeds maximum length")
+var (
+	ErrCommentTooLong   = errors.New("comment exceeds maximum length")
+	ErrCommentEmpty     = errors.New("comment body is empty")
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrNotCommentAuthor = errors.New("only the author can edit a comment")
+	ErrEditWindowClosed = errors.New("comment can no longer be edited")
+	ErrRevisionConflict = errors.New("comment was edited concurrently")
+	ErrCommentDeleted   = errors.New("comment was deleted")
+	ErrCommentHidden    = errors.New("comment is hidden")
+)
+
+// DeletedCommentBody replaces the body of deleted comments kept as
+// tombstones for their replies.
+const DeletedCommentBody = "[deleted]"
 
 type CommentService struct {
-	db *sql.DB
+	db              *sql.DB
+	editWindow      time.Duration
+	reportThreshold int
+	resolver        NameResolver
+	notifier        Notifier
 }
 
 func NewCommentService(db *sql.DB) *CommentService {
 	return &CommentService{db: db}
 }
 
+// SetEditWindow sets how long after posting a comment its author may edit
+// it. A zero window disables editing.
+func (s *CommentService) SetEditWindow(window time.Duration) {
+	s.editWindow = window
+}
+
 // Create adds a new comment to a post. If parentID is non-nil, validates
-// that the parent comment belongs to the same post.
-func (s *CommentService) Create(postID, walletID int, body string, parentID *int) (*models.Comment, error) {
+// that the parent comment belongs to the same post. locale records which
+// translation the comment was written on and may be empty.
+func (s *CommentService) Create(postID, walletID int, body string, parentID *int, locale string) (*models.Comment, error) {
 	if len(body) > MaxCommentLength {
 		return nil, ErrCommentTooLong
 	}
+	if err := s.checkBanned(walletID); err != nil {
+		return nil, err
+	}
 
+	var parentAuthorID int
 	if parentID != nil {
 		var parentPostID int
+		var parentDeleted bool
+		var parentStatus string
 		err := s.db.QueryRow(
-			"SELECT post_id FROM comments WHERE id = ?", *parentID,
-		).Scan(&parentPostID)
+			"SELECT post_id, wallet_id, deleted_at IS NOT NULL, status FROM comments WHERE id = ?", *parentID,
+		).Scan(&parentPostID, &parentAuthorID, &parentDeleted, &parentStatus)
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("parent comment not found")
 		}
@@ -41,11 +73,28 @@ func (s *CommentService) Create(postID, walletID int, body string, parentID *int
 		if parentPostID != postID {
 			return nil, fmt.Errorf("parent comment belongs to a different post")
 		}
+		if parentDeleted {
+			return nil, fmt.Errorf("parent comment was deleted")
+		}
+		if parentStatus == CommentHidden {
+			return nil, fmt.Errorf("parent comment is hidden")
+		}
+	}
+
+	mentions, err := s.resolveMentions(postID, body)
+	if err != nil {
+		return nil, err
 	}
 
-	result, err := s.db.Exec(
-		"INSERT INTO comments (post_id, wallet_id, parent_id, body) VALUES (?, ?, ?, ?)",
-		postID, walletID, parentID, body,
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT INTO comments (post_id, wallet_id, parent_id, body, locale, body_html, render_version) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		postID, walletID, parentID, body, locale, RenderMarkdown(body), MarkdownVersion,
 	)
 	if err != nil {
 		return nil, err
@@ -55,29 +104,81 @@ func (s *CommentService) Create(postID, walletID int, body string, parentID *int
 	if err != nil {
 		return nil, err
 	}
+	if _, err := saveMentions(tx, int(id), mentions); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	c, err := s.getByID(int(id))
+	if err != nil {
+		return nil, err
+	}
+	c.Mentions = mentionEntities(mentions)
+	if parentID != nil {
+		s.notify(CommentEvent{
+			Kind:       EventReply,
+			CommentID:  c.ID,
+			PostID:     postID,
+			ActorID:    walletID,
+			Recipients: []int{parentAuthorID},
+		})
+	}
+	// The parent's author already hears about the reply
+	s.notifyMentions(c, mentions, map[int]bool{parentAuthorID: true})
+	return c, nil
+}
 
+func (s *CommentService) getByID(id int) (*models.Comment, error) {
 	var c models.Comment
-	err = s.db.QueryRow(
-		"SELECT id, post_id, wallet_id, parent_id, body, created_at FROM comments WHERE id = ?",
+	err := s.db.QueryRow(
+		`SELECT id, post_id, wallet_id, parent_id, body, body_html, locale, created_at, revision, edited_at, deleted_at,
+			upvotes, downvotes, score, status
+		FROM comments WHERE id = ?`,
 		id,
-	).Scan(&c.ID, &c.PostID, &c.WalletID, &c.ParentID, &c.Body, &c.CreatedAt)
+	).Scan(&c.ID, &c.PostID, &c.WalletID, &c.ParentID, &c.Body, &c.BodyHTML, &c.Locale, &c.CreatedAt, &c.Revision, &c.EditedAt, &c.DeletedAt,
+		&c.Upvotes, &c.Downvotes, &c.Score, &c.Status)
+	if err == sql.ErrNoRows {
+		return nil, ErrCommentNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
-
 	return &c, nil
 }
 
 // GetByPostID returns all comments for a post, ordered by creation time.
-// Includes the author's wallet address for display.
-func (s *CommentService) GetByPostID(postID int) (*models.CommentsResponse, error) {
+// Includes the author's wallet address for display. A non-empty locale
+// restricts the result to comments written on that translation. Deleted
+// comments are returned as tombstones. Hidden comments are left out unless
+// the viewer wrote them or is a moderator.
+func (s *CommentService) GetByPostID(postID int, locale string, viewer CommentViewer) (*models.CommentsResponse, error) {
+	return s.List(postID, locale, "", viewer)
+}
+
+// List returns the comments of a post like GetByPostID, in one of the sort
+// orders of comment trees. An empty sort means oldest first.
+// Returns ErrInvalidCommentSort for unknown sorts.
+func (s *CommentService) List(postID int, locale, sortName string, viewer CommentViewer) (*models.CommentsResponse, error) {
+	if sortName == "" {
+		sortName = "oldest"
+	}
+	sort, ok := commentSorts[sortName]
+	if !ok {
+		return nil, ErrInvalidCommentSort
+	}
+
 	rows, err := s.db.Query(`
-		SELECT c.id, c.parent_id, c.body, c.created_at, w.address
-		FROM comments c
-		JOIN wallets w ON w.id = c.wallet_id
-		WHERE c.post_id = ?
-		ORDER BY c.created_at ASC
-	`, postID)
+		SELECT * FROM (
+			SELECT c.id, c.parent_id, c.body, c.body_html, c.locale, c.created_at, c.revision, c.edited_at,
+				c.deleted_at IS NOT NULL AS deleted, w.address, c.upvotes, c.downvotes, c.score, c.status
+			FROM comments c
+			JOIN wallets w ON w.id = c.wallet_id
+			WHERE c.post_id = ?1 AND (?2 = '' OR c.locale = ?2)
+				AND (c.status = 'visible' OR ?3 OR c.wallet_id = ?4)
+		) ORDER BY `+sort.order, postID, locale, viewer.Moderator, viewer.WalletID)
 	if err != nil {
 		return nil, err
 	}
@@ -86,9 +187,12 @@ func (s *CommentService) GetByPostID(postID int) (*models.CommentsResponse, erro
 	var comments []models.CommentResponse
 	for rows.Next() {
 		var c models.CommentResponse
-		if err := rows.Scan(&c.ID, &c.ParentID, &c.Body, &c.CreatedAt, &c.AuthorAddress); err != nil {
+		err := rows.Scan(&c.ID, &c.ParentID, &c.Body, &c.BodyHTML, &c.Locale, &c.CreatedAt, &c.Revision, &c.EditedAt,
+			&c.Deleted, &c.AuthorAddress, &c.Upvotes, &c.Downvotes, &c.Score, &c.Status)
+		if err != nil {
 			return nil, err
 		}
+		present(&c)
 		comments = append(comments, c)
 	}
 
@@ -101,8 +205,397 @@ func (s *CommentService) GetByPostID(postID int) (*models.CommentsResponse, erro
 		comments = []models.CommentResponse{}
 	}
 
+	listed := make([]*models.CommentResponse, len(comments))
+	for i := range comments {
+		listed[i] = &comments[i]
+	}
+	if err := s.attachMentions(listed); err != nil {
+		return nil, err
+	}
+
 	return &models.CommentsResponse{
 		Comments: comments,
 		Total:    len(comments),
 	}, nil
 }
+
+// Edit replaces the body of a comment, keeping the previous body in its
+// revision history. revision is the revision the author edited, so that
+// an edit made from a stale copy is rejected instead of overwriting a
+// newer one.
+// Returns ErrCommentNotFound, ErrNotCommentAuthor, ErrEditWindowClosed or
+// ErrRevisionConflict when the edit is not allowed.
+func (s *CommentService) Edit(commentID, walletID int, body string, revision int) (*models.Comment, error) {
+	if len(body) > MaxCommentLength {
+		return nil, ErrCommentTooLong
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, ErrCommentEmpty
+	}
+
+	c, err := s.getByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if c.DeletedAt != nil {
+		return nil, ErrCommentDeleted
+	}
+	if c.WalletID != walletID {
+		return nil, ErrNotCommentAuthor
+	}
+	if err := s.checkBanned(walletID); err != nil {
+		return nil, err
+	}
+	if s.editWindow <= 0 || time.Since(c.CreatedAt) > s.editWindow {
+		return nil, ErrEditWindowClosed
+	}
+	if c.Revision != revision {
+		return nil, ErrRevisionConflict
+	}
+	if body == c.Body {
+		return c, nil
+	}
+
+	mentions, err := s.resolveMentions(c.PostID, body)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The replaced body was written when the comment was created or last edited
+	writtenAt := c.CreatedAt
+	if c.EditedAt != nil {
+		writtenAt = *c.EditedAt
+	}
+	_, err = tx.Exec(`
+		INSERT INTO comment_revisions (comment_id, revision, body, body_html, render_version, created_at)
+		SELECT id, revision, body, body_html, render_version, ? FROM comments WHERE id = ?
+	`, writtenAt.UTC(), c.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec(
+		"UPDATE comments SET body = ?, body_html = ?, render_version = ?, revision = revision + 1, edited_at = ? WHERE id = ? AND revision = ?",
+		body, RenderMarkdown(body), MarkdownVersion, time.Now().UTC(), c.ID, revision,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrRevisionConflict
+	}
+
+	previous, err := saveMentions(tx, c.ID, mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	edited, err := s.getByID(c.ID)
+	if err != nil {
+		return nil, err
+	}
+	edited.Mentions = mentionEntities(mentions)
+	s.notifyMentions(edited, mentions, previous)
+	return edited, nil
+}
+
+// History returns every version of a comment, oldest first.
+// Returns ErrCommentNotFound if the comment doesn't exist, was deleted or is
+// hidden from the viewer.
+func (s *CommentService) History(commentID int, viewer CommentViewer) (*models.CommentHistoryResponse, error) {
+	c, err := s.getByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if c.DeletedAt != nil {
+		return nil, ErrCommentNotFound
+	}
+	if viewer.hides(c) {
+		return nil, ErrCommentNotFound
+	}
+
+	rows, err := s.db.Query(
+		"SELECT revision, body, body_html, created_at FROM comment_revisions WHERE comment_id = ? ORDER BY revision",
+		commentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := &models.CommentHistoryResponse{CommentID: c.ID, Revisions: []models.CommentRevision{}}
+	for rows.Next() {
+		var r models.CommentRevision
+		if err := rows.Scan(&r.Revision, &r.Body, &r.BodyHTML, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		history.Revisions = append(history.Revisions, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	current := models.CommentRevision{Revision: c.Revision, Body: c.Body, BodyHTML: c.BodyHTML, CreatedAt: c.CreatedAt}
+	if c.EditedAt != nil {
+		current.CreatedAt = *c.EditedAt
+	}
+	history.Revisions = append(history.Revisions, current)
+
+	return history, nil
+}
+
+// renderBatch is the number of comments re-rendered per query by
+// RenderStale.
+const renderBatch = 100
+
+// RenderStale re-renders the comments and revisions rendered by an older
+// MarkdownVersion and returns how many were updated.
+func (s *CommentService) RenderStale() (int, error) {
+	total, err := s.renderStaleComments()
+	if err != nil {
+		return total, err
+	}
+	revisions, err := s.renderStaleRevisions()
+	return total + revisions, err
+}
+
+func (s *CommentService) renderStaleComments() (int, error) {
+	total := 0
+	for {
+		rows, err := s.db.Query(
+			"SELECT id, body FROM comments WHERE render_version <> ? ORDER BY id LIMIT ?",
+			MarkdownVersion, renderBatch,
+		)
+		if err != nil {
+			return total, err
+		}
+		type stale struct {
+			id   int
+			body string
+		}
+		var batch []stale
+		for rows.Next() {
+			var c stale
+			if err := rows.Scan(&c.id, &c.body); err != nil {
+				rows.Close()
+				return total, err
+			}
+			batch = append(batch, c)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return total, err
+		}
+		if len(batch) == 0 {
+			return total, nil
+		}
+
+		for _, c := range batch {
+			// Comments edited meanwhile were rendered by the edit
+			_, err := s.db.Exec(
+				"UPDATE comments SET body_html = ?, render_version = ? WHERE id = ? AND body = ?",
+				RenderMarkdown(c.body), MarkdownVersion, c.id, c.body,
+			)
+			if err != nil {
+				return total, err
+			}
+			total++
+		}
+	}
+}
+
+// renderStaleRevisions is the counterpart of renderStaleComments for the
+// replaced bodies kept in comment_revisions, which never change.
+func (s *CommentService) renderStaleRevisions() (int, error) {
+	total := 0
+	for {
+		rows, err := s.db.Query(
+			"SELECT comment_id, revision, body FROM comment_revisions WHERE render_version <> ? LIMIT ?",
+			MarkdownVersion, renderBatch,
+		)
+		if err != nil {
+			return total, err
+		}
+		type stale struct {
+			commentID int
+			revision  int
+			body      string
+		}
+		var batch []stale
+		for rows.Next() {
+			var r stale
+			if err := rows.Scan(&r.commentID, &r.revision, &r.body); err != nil {
+				rows.Close()
+				return total, err
+			}
+			batch = append(batch, r)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return total, err
+		}
+		if len(batch) == 0 {
+			return total, nil
+		}
+
+		for _, r := range batch {
+			_, err := s.db.Exec(
+				"UPDATE comment_revisions SET body_html = ?, render_version = ? WHERE comment_id = ? AND revision = ?",
+				RenderMarkdown(r.body), MarkdownVersion, r.commentID, r.revision,
+			)
+			if err != nil {
+				return total, err
+			}
+			total++
+		}
+	}
+}
+
+// present applies the display rules of listed comments: collapsing and
+// tombstones.
+func present(c *models.CommentResponse) {
+	c.Collapsed = collapsed(c.Upvotes, c.Downvotes)
+	if c.Deleted {
+		tombstone(c)
+	}
+}
+
+// tombstone hides the body and author of a deleted comment.
+func tombstone(c *models.CommentResponse) {
+	c.Body = DeletedCommentBody
+	c.BodyHTML = ""
+	c.Mentions = nil
+	c.AuthorAddress = ""
+	c.EditedAt = nil
+	c.Deleted = true
+}
+
+// Delete deletes a comment on behalf of its author. A comment without
+// replies is purged; one with replies becomes a tombstone so that the
+// thread keeps its shape. It reports whether the comment was purged.
+// Returns ErrNotCommentAuthor if the wallet did not write the comment.
+func (s *CommentService) Delete(commentID, walletID int) (bool, error) {
+	c, err := s.getByID(commentID)
+	if err != nil {
+		return false, err
+	}
+	if c.WalletID != walletID {
+		return false, ErrNotCommentAuthor
+	}
+	return s.remove(c, "author")
+}
+
+func (s *CommentService) remove(c *models.Comment, deletedBy string) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	purged, err := removeComment(tx, c, deletedBy)
+	if err != nil {
+		return false, err
+	}
+	return purged, tx.Commit()
+}
+
+// removeComment deletes a comment inside tx, purging it or leaving a
+// tombstone like Delete, and reports whether it was purged.
+func removeComment(tx *sql.Tx, c *models.Comment, deletedBy string) (bool, error) {
+	if c.DeletedAt != nil {
+		return false, ErrCommentDeleted
+	}
+
+	replies, err := countReplies(tx, c.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if replies > 0 {
+		// The history, mentions, notifications and reports go with the body
+		for _, stmt := range []string{
+			"DELETE FROM comment_revisions WHERE comment_id = ?",
+			"DELETE FROM comment_mentions WHERE comment_id = ?",
+			"DELETE FROM notifications WHERE comment_id = ?",
+			"DELETE FROM comment_reports WHERE comment_id = ?",
+		} {
+			if _, err := tx.Exec(stmt, c.ID); err != nil {
+				return false, err
+			}
+		}
+		_, err = tx.Exec(
+			"UPDATE comments SET body = '', body_html = '', deleted_at = ?, deleted_by = ? WHERE id = ?",
+			time.Now().UTC(), deletedBy, c.ID,
+		)
+		return false, err
+	}
+
+	if err := purgeComment(tx, c.ID); err != nil {
+		return false, err
+	}
+
+	// Tombstones left without replies are purged as well
+	for parentID := c.ParentID; parentID != nil; {
+		var deleted bool
+		var grandparentID *int
+		err := tx.QueryRow(
+			"SELECT deleted_at IS NOT NULL, parent_id FROM comments WHERE id = ?", *parentID,
+		).Scan(&deleted, &grandparentID)
+		if err != nil {
+			return false, err
+		}
+		if !deleted {
+			break
+		}
+		n, err := countReplies(tx, *parentID)
+		if err != nil {
+			return false, err
+		}
+		if n > 0 {
+			break
+		}
+		if err := purgeComment(tx, *parentID); err != nil {
+			return false, err
+		}
+		parentID = grandparentID
+	}
+
+	return true, nil
+}
+
+func countReplies(tx *sql.Tx, commentID int) (int, error) {
+	var n int
+	err := tx.QueryRow("SELECT COUNT(*) FROM comments WHERE parent_id = ?", commentID).Scan(&n)
+	return n, err
+}
+
+// purgeComment removes a comment and every row referencing it. Tables
+// that reference comments must be cleared here.
+func purgeComment(tx *sql.Tx, commentID int) error {
+	statements := []string{
+		"DELETE FROM comment_revisions WHERE comment_id = ?",
+		"DELETE FROM comment_votes WHERE comment_id = ?",
+		"DELETE FROM comment_mentions WHERE comment_id = ?",
+		"DELETE FROM notifications WHERE comment_id = ?",
+		"DELETE FROM comment_reports WHERE comment_id = ?",
+		"DELETE FROM comments WHERE id = ?",
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt, commentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Bounds of comment tree queries.
+const (
+	MaxCommentDepth    = 10
+	MaxCommentPage     = 100
+	MaxRepliesPerLevel = 50
+	MaxCommentSiblings = 10
+)
+
+var (
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrInvalidCommentSort = errors.New("sort must be one of: oldest, newest, top")
+)
+
+// CommentTreeOptions shapes a comment tree query. Depth is the number of
+// levels returned, 1 meaning the threads without replies; Limit bounds the
+// threads of the page and Replies the replies embedded under each comment.
+// Viewer decides which hidden comments are returned, as in List.
+type CommentTreeOptions struct {
+	Locale  string
+	Sort    string
+	Depth   int
+	Limit   int
+	Replies int
+	Cursor  string
+	Viewer  CommentViewer
+}
+
+// commentSort describes a sort mode: order is its ORDER BY clause over the
+// columns of commentTreeQuery and after the keyset condition selecting the
+// rows following a cursor (?1 key, ?2 id); reverse and before are their
+// counterparts walking backwards. Cursor keys are creation times, or
+// scores when numeric is set.
+type commentSort struct {
+	numeric bool
+	order   string
+	after   string
+	reverse string
+	before  string
+}
+
+// sqliteTimeLayout is the format of CURRENT_TIMESTAMP, used to compare
+// cursor keys with created_at.
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// key returns the sort key of a comment.
+func (s commentSort) key(n models.CommentNode) string {
+	if s.numeric {
+		return strconv.FormatFloat(n.Score, 'g', -1, 64)
+	}
+	return n.CreatedAt.UTC().Format(sqliteTimeLayout)
+}
+
+// cursor returns the cursor following a comment.
+func (s commentSort) cursor(n models.CommentNode) string {
+	return commentCursor{key: s.key(n), id: n.ID}.encode()
+}
+
+// cursorKey converts a cursor key to the type of the sort column.
+func (s commentSort) cursorKey(key string) any {
+	if s.numeric {
+		score, _ := strconv.ParseFloat(key, 64)
+		return score
+	}
+	return key
+}
+
+var commentSorts = map[string]commentSort{
+	"oldest": {
+		order:   "created_at, id",
+		after:   "(created_at > ?1 OR (created_at = ?1 AND id > ?2))",
+		reverse: "created_at DESC, id DESC",
+		before:  "(created_at < ?1 OR (created_at = ?1 AND id < ?2))",
+	},
+	"newest": {
+		order:   "created_at DESC, id DESC",
+		after:   "(created_at < ?1 OR (created_at = ?1 AND id < ?2))",
+		reverse: "created_at, id",
+		before:  "(created_at > ?1 OR (created_at = ?1 AND id > ?2))",
+	},
+	// top ranks comments by the Wilson score of their votes
+	"top": {
+		numeric: true,
+		order:   "score DESC, id",
+		after:   "(score < ?1 OR (score = ?1 AND id > ?2))",
+		reverse: "score, id DESC",
+		before:  "(score > ?1 OR (score = ?1 AND id < ?2))",
+	},
+}
+
+// commentTreeQuery selects the comments a viewer may read with their
+// direct reply count in a locale. Its arguments are the placeholders bound
+// to the locale and to the moderator flag and wallet of the viewer, e.g.
+// "?3". Hidden comments are left out like in List. The filter is appended
+// to it.
+func commentTreeQuery(locale, moderator, wallet string) string {
+	return `
+	SELECT c.id, c.parent_id, c.body, c.body_html, c.locale, c.created_at, c.revision, c.edited_at,
+		c.deleted_at IS NOT NULL AS deleted, w.address, c.upvotes, c.downvotes, c.score, c.status,
+		(SELECT COUNT(*) FROM comments r
+			WHERE r.parent_id = c.id AND (` + locale + ` = '' OR r.locale = ` + locale + `)
+				AND (r.status = 'visible' OR ` + moderator + ` OR r.wallet_id = ` + wallet + `)) AS reply_count
+	FROM comments c
+	JOIN wallets w ON w.id = c.wallet_id
+	WHERE (c.status = 'visible' OR ` + moderator + ` OR c.wallet_id = ` + wallet + `) AND `
+}
+
+// commentCursor is the position after a comment in a sort order.
+type commentCursor struct {
+	key string
+	id  int
+}
+
+func (c commentCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.key + "|" + strconv.Itoa(c.id)))
+}
+
+func decodeCommentCursor(cursor string) (*commentCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	i := strings.LastIndex(string(raw), "|")
+	if i < 0 {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.Atoi(string(raw[i+1:]))
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &commentCursor{key: string(raw[:i]), id: id}, nil
+}
+
+// normalize applies defaults and bounds to the options.
+func (o *CommentTreeOptions) normalize() (commentSort, error) {
+	if o.Sort == "" {
+		o.Sort = "oldest"
+	}
+	sort, ok := commentSorts[o.Sort]
+	if !ok {
+		return sort, ErrInvalidCommentSort
+	}
+	o.Depth = min(max(o.Depth, 1), MaxCommentDepth)
+	o.Limit = min(max(o.Limit, 1), MaxCommentPage)
+	o.Replies = min(max(o.Replies, 1), MaxRepliesPerLevel)
+	return sort, nil
+}
+
+// Tree returns a page of the top-level comments of a post with their
+// replies nested up to opts.Depth levels.
+func (s *CommentService) Tree(postID int, opts CommentTreeOptions) (*models.CommentTreeResponse, error) {
+	return s.threadPage("c.post_id = ?4 AND c.parent_id IS NULL", postID, opts)
+}
+
+// Replies returns a page of the replies to a comment, nested like Tree,
+// to load the replies left out of a tree.
+// Returns ErrCommentNotFound if the comment doesn't exist.
+func (s *CommentService) Replies(commentID int, opts CommentTreeOptions) (*models.CommentTreeResponse, error) {
+	if _, err := s.getByID(commentID); err != nil {
+		return nil, err
+	}
+	return s.threadPage("c.parent_id = ?4", commentID, opts)
+}
+
+func (s *CommentService) threadPage(filter string, id int, opts CommentTreeOptions) (*models.CommentTreeResponse, error) {
+	sort, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	// ?1 and ?2 are the cursor, ?3 the locale, ?4 the filter argument and
+	// ?6 and ?7 the viewer
+	args := []any{nil, nil, opts.Locale, id, opts.Limit + 1, opts.Viewer.Moderator, opts.Viewer.WalletID}
+	query := "SELECT * FROM (" + commentTreeQuery("?3", "?6", "?7") + filter + " AND (?3 = '' OR c.locale = ?3))"
+	if opts.Cursor != "" {
+		cursor, err := decodeCommentCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		args[0], args[1] = sort.cursorKey(cursor.key), cursor.id
+		query += " WHERE " + sort.after
+	}
+	query += " ORDER BY " + sort.order + " LIMIT ?5"
+
+	nodes, err := s.scanNodes(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.CommentTreeResponse{Comments: nodes}
+	if len(nodes) > opts.Limit {
+		resp.Comments = nodes[:opts.Limit]
+		resp.NextCursor = sort.cursor(resp.Comments[opts.Limit-1])
+	}
+
+	level := make([]*models.CommentNode, len(resp.Comments))
+	for i := range resp.Comments {
+		level[i] = &resp.Comments[i]
+	}
+	if err := s.embedReplies(level, sort, opts, opts.Depth-1); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// embedReplies fills the replies of nodes, one level of the tree at a
+// time, for depth more levels. Up to opts.Replies replies are embedded
+// under each comment.
+func (s *CommentService) embedReplies(nodes []*models.CommentNode, sort commentSort, opts CommentTreeOptions, depth int) error {
+	if depth <= 0 || len(nodes) == 0 {
+		return nil
+	}
+
+	parents := make(map[int]*models.CommentNode, len(nodes))
+	var placeholders []string
+	args := []any{opts.Locale, opts.Replies + 1, opts.Viewer.Moderator, opts.Viewer.WalletID}
+	for _, n := range nodes {
+		if n.ReplyCount == 0 {
+			continue
+		}
+		parents[n.ID] = n
+		args = append(args, n.ID)
+		placeholders = append(placeholders, "?"+strconv.Itoa(len(args)))
+	}
+	if len(parents) == 0 {
+		return nil
+	}
+
+	query := `
+		SELECT id, parent_id, body, body_html, locale, created_at, revision, edited_at, deleted, address,
+			upvotes, downvotes, score, status, reply_count FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY ` + sort.order + `) AS rn FROM (` +
+		commentTreeQuery("?1", "?3", "?4") + `(?1 = '' OR c.locale = ?1) AND c.parent_id IN (` + strings.Join(placeholders, ", ") + `)
+			)
+		) WHERE rn <= ?2
+		ORDER BY parent_id, rn
+	`
+	replies, err := s.scanNodes(query, args...)
+	if err != nil {
+		return err
+	}
+
+	for _, reply := range replies {
+		parent := parents[*reply.ParentID]
+		if len(parent.Replies) == opts.Replies {
+			parent.RepliesCursor = sort.cursor(parent.Replies[len(parent.Replies)-1])
+			continue
+		}
+		parent.Replies = append(parent.Replies, reply)
+	}
+
+	var next []*models.CommentNode
+	for _, n := range nodes {
+		for i := range n.Replies {
+			next = append(next, &n.Replies[i])
+		}
+	}
+	return s.embedReplies(next, sort, opts, depth-1)
+}
+
+// scanNodes runs a query selecting the columns of commentTreeQuery.
+func (s *CommentService) scanNodes(query string, args ...any) ([]models.CommentNode, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := []models.CommentNode{}
+	for rows.Next() {
+		var n models.CommentNode
+		err := rows.Scan(&n.ID, &n.ParentID, &n.Body, &n.BodyHTML, &n.Locale, &n.CreatedAt, &n.Revision, &n.EditedAt,
+			&n.Deleted, &n.AuthorAddress, &n.Upvotes, &n.Downvotes, &n.Score, &n.Status, &n.ReplyCount)
+		if err != nil {
+			return nil, err
+		}
+		present(&n.CommentResponse)
+		n.Replies = []models.CommentNode{}
+		nodes = append(nodes, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	listed := make([]*models.CommentResponse, len(nodes))
+	for i := range nodes {
+		listed[i] = &nodes[i].CommentResponse
+	}
+	if err := s.attachMentions(listed); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"database/sql"
+	"errors"
+	"math"
+)
+
+// CollapseScore is the net score (upvotes minus downvotes) at or below
+// which a comment is collapsed by default.
+const CollapseScore = -5
+
+var (
+	ErrInvalidVote = errors.New("vote must be 1 or -1")
+	ErrSelfVote    = errors.New("cannot vote on your own comment")
+)
+
+// wilsonZ is the z-score of the 95% confidence level used by WilsonScore.
+const wilsonZ = 1.96
+
+// WilsonScore returns the lower bound of the Wilson score interval for the
+// share of upvotes: a comment ranks high once enough votes make a high
+// share likely, so a single upvote doesn't outrank 40 up and 2 down.
+func WilsonScore(up, down int) float64 {
+	if up == 0 {
+		return 0
+	}
+	n := float64(up + down)
+	p := float64(up) / n
+	z2 := wilsonZ * wilsonZ
+	return (p + z2/(2*n) - wilsonZ*math.Sqrt((p*(1-p)+z2/(4*n))/n)) / (1 + z2/n)
+}
+
+// collapsed tells whether a comment's votes hide it by default.
+func collapsed(up, down int) bool {
+	return up-down <= CollapseScore
+}
+
+// Vote records a wallet's vote on a comment: 1 up, -1 down and 0 to
+// withdraw it. Voting again replaces the previous vote.
+// Returns ErrCommentNotFound, also for comments hidden from the voter,
+// ErrCommentDeleted, ErrSelfVote or ErrInvalidVote when the vote is not
+// allowed.
+func (s *CommentService) Vote(commentID int, voter CommentViewer, value int) (*models.CommentVoteResponse, error) {
+	if value < -1 || value > 1 {
+		return nil, ErrInvalidVote
+	}
+
+	c, err := s.getByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if voter.hides(c) {
+		return nil, ErrCommentNotFound
+	}
+	if c.DeletedAt != nil {
+		return nil, ErrCommentDeleted
+	}
+	if c.WalletID == voter.WalletID {
+		return nil, ErrSelfVote
+	}
+	if err := s.checkBanned(voter.WalletID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var previous int
+	err = tx.QueryRow(
+		"SELECT value FROM comment_votes WHERE comment_id = ? AND wallet_id = ?", commentID, voter.WalletID,
+	).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if value != previous {
+		if value == 0 {
+			_, err = tx.Exec("DELETE FROM comment_votes WHERE comment_id = ? AND wallet_id = ?", commentID, voter.WalletID)
+		} else {
+			_, err = tx.Exec(`
+				INSERT INTO comment_votes (comment_id, wallet_id, value) VALUES (?, ?, ?)
+				ON CONFLICT (comment_id, wallet_id) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP
+			`, commentID, voter.WalletID, value)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		up, down := voteCounts(value)
+		prevUp, prevDown := voteCounts(previous)
+		_, err = tx.Exec(
+			"UPDATE comments SET upvotes = upvotes + ?, downvotes = downvotes + ? WHERE id = ?",
+			up-prevUp, down-prevDown, commentID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := updateCommentScore(tx, commentID); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &models.CommentVoteResponse{CommentID: commentID, Vote: value}
+	err = tx.QueryRow(
+		"SELECT upvotes, downvotes, score FROM comments WHERE id = ?", commentID,
+	).Scan(&resp.Upvotes, &resp.Downvotes, &resp.Score)
+	if err != nil {
+		return nil, err
+	}
+	resp.Collapsed = collapsed(resp.Upvotes, resp.Downvotes)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if value == 1 && previous != 1 {
+		s.notify(CommentEvent{
+			Kind:       EventReaction,
+			CommentID:  commentID,
+			PostID:     c.PostID,
+			ActorID:    voter.WalletID,
+			Recipients: []int{c.WalletID},
+		})
+	}
+	return resp, nil
+}
+
+// voteCounts returns the upvotes and downvotes a vote value counts for.
+func voteCounts(value int) (up, down int) {
+	switch value {
+	case 1:
+		return 1, 0
+	case -1:
+		return 0, 1
+	}
+	return 0, 0
+}
+
+// updateCommentScore recomputes a comment's score from its vote counts.
+func updateCommentScore(tx *sql.Tx, commentID int) error {
+	var up, down int
+	if err := tx.QueryRow("SELECT upvotes, downvotes FROM comments WHERE id = ?", commentID).Scan(&up, &down); err != nil {
+		return err
+	}
+	_, err := tx.Exec("UPDATE comments SET score = ? WHERE id = ?", WilsonScore(up, down), commentID)
+	return err
+}
+
+// rescoreComments recomputes the score of every comment whose stored score
+// doesn't match its vote counts.
+func rescoreComments(tx *sql.Tx) error {
+	rows, err := tx.Query("SELECT id, upvotes, downvotes, score FROM comments WHERE upvotes > 0 OR downvotes > 0 OR score != 0")
+	if err != nil {
+		return err
+	}
+	scores := map[int]float64{}
+	for rows.Next() {
+		var id, up, down int
+		var score float64
+		if err := rows.Scan(&id, &up, &down, &score); err != nil {
+			rows.Close()
+			return err
+		}
+		if want := WilsonScore(up, down); want != score {
+			scores[id] = want
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, score := range scores {
+		if _, err := tx.Exec("UPDATE comments SET score = ? WHERE id = ?", score, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
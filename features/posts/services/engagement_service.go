@@ -0,0 +1,272 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxEngagementPoints bounds the number of buckets returned by a stats query.
+const MaxEngagementPoints = 366
+
+const dateLayout = "2006-01-02"
+
+var (
+	ErrInvalidInterval   = errors.New("interval must be one of: day, week, month")
+	ErrInvalidStatsRange = errors.New("invalid stats range")
+)
+
+// engagementIntervals maps each rollup interval to the SQLite expression
+// giving the first day of the bucket of a timestamp. Weeks start on Monday.
+var engagementIntervals = map[string]string{
+	"day":   "date(%[1]s)",
+	"week":  "date(%[1]s, 'weekday 0', '-6 days')",
+	"month": "date(%[1]s, 'start of month')",
+}
+
+// bucketExpr returns the bucket expression of an interval for a column.
+func bucketExpr(interval, column string) string {
+	return fmt.Sprintf(engagementIntervals[interval], column)
+}
+
+// engagementEvents unions every engagement event with its wallet and kind.
+const engagementEvents = `(
+	SELECT post_id, wallet_id, kind, created_at FROM post_like_events
+	UNION ALL
+	SELECT post_id, wallet_id, 'comment', created_at FROM comments WHERE ` + countedComments + `
+)`
+
+// EngagementService maintains per-post and per-series engagement rollups
+// and serves them as time series.
+type EngagementService struct {
+	db *sql.DB
+}
+
+func NewEngagementService(db *sql.DB) *EngagementService {
+	return &EngagementService{db: db}
+}
+
+// postRollups selects post rollup rows (post_id, bucket, likes, unlikes,
+// comments, engaged_wallets) from the events matching filter.
+func postRollups(bucket, filter string) string {
+	return `
+		SELECT e.post_id, ` + bucket + ` AS bucket,
+			SUM(e.kind = 'like') AS likes,
+			SUM(e.kind = 'unlike') AS unlikes,
+			SUM(e.kind = 'comment') AS comments,
+			COUNT(DISTINCT e.wallet_id) AS engaged_wallets
+		FROM ` + engagementEvents + ` e
+		WHERE ` + filter + `
+		GROUP BY e.post_id, bucket`
+}
+
+// seriesRollups is the series counterpart of postRollups.
+func seriesRollups(bucket, filter string) string {
+	return `
+		SELECT p.series_id, ` + bucket + ` AS bucket,
+			SUM(e.kind = 'like') AS likes,
+			SUM(e.kind = 'unlike') AS unlikes,
+			SUM(e.kind = 'comment') AS comments,
+			COUNT(DISTINCT e.wallet_id) AS engaged_wallets
+		FROM ` + engagementEvents + ` e
+		JOIN posts p ON p.id = e.post_id
+		WHERE p.series_id IS NOT NULL AND ` + filter + `
+		GROUP BY p.series_id, bucket`
+}
+
+// Aggregate refreshes the rollups of every interval. Buckets from the most
+// recent one onwards are rebuilt from the event tables; older buckets are
+// final and left untouched.
+func (s *EngagementService) Aggregate() error {
+	for interval := range engagementIntervals {
+		bucket := bucketExpr(interval, "e.created_at")
+		if err := s.aggregate("post_engagement_rollups", "post_id", interval,
+			"SELECT * FROM ("+postRollups(bucket, "e.created_at >= ?1")+") WHERE bucket >= ?1",
+		); err != nil {
+			return err
+		}
+
+		if err := s.aggregate("series_engagement_rollups", "series_id", interval,
+			"SELECT * FROM ("+seriesRollups(bucket, "e.created_at >= ?1")+") WHERE bucket >= ?1",
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildRollups recomputes every bucket of the rollups of a post and of
+// the given series from the event tables, including the final ones that
+// Aggregate leaves alone. Used when events move between posts.
+func rebuildRollups(tx *sql.Tx, postID int, seriesIDs []int) error {
+	for interval := range engagementIntervals {
+		bucket := bucketExpr(interval, "e.created_at")
+
+		if _, err := tx.Exec("DELETE FROM post_engagement_rollups WHERE post_id = ? AND interval = ?", postID, interval); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+			INSERT INTO post_engagement_rollups (post_id, interval, bucket, likes, unlikes, comments, engaged_wallets)
+			SELECT post_id, ?2, bucket, likes, unlikes, comments, engaged_wallets FROM (`+postRollups(bucket, "e.post_id = ?1")+`)
+		`, postID, interval)
+		if err != nil {
+			return err
+		}
+
+		for _, seriesID := range seriesIDs {
+			if _, err := tx.Exec("DELETE FROM series_engagement_rollups WHERE series_id = ? AND interval = ?", seriesID, interval); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`
+				INSERT INTO series_engagement_rollups (series_id, interval, bucket, likes, unlikes, comments, engaged_wallets)
+				SELECT series_id, ?2, bucket, likes, unlikes, comments, engaged_wallets FROM (`+seriesRollups(bucket, "p.series_id = ?1")+`)
+			`, seriesID, interval)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// aggregate rebuilds the rollups of one table and interval from the most
+// recent bucket onwards using query, which selects the new rows.
+func (s *EngagementService) aggregate(table, idColumn, interval, query string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var from string
+	err = tx.QueryRow("SELECT COALESCE(MAX(bucket), '') FROM "+table+" WHERE interval = ?", interval).Scan(&from)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM "+table+" WHERE interval = ? AND bucket >= ?", interval, from); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO `+table+` (`+idColumn+`, interval, bucket, likes, unlikes, comments, engaged_wallets)
+		SELECT `+idColumn+`, ?2, bucket, likes, unlikes, comments, engaged_wallets FROM (`+query+`)
+	`, from, interval)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PostStats returns the engagement time series of a post between from and
+// to (inclusive dates) in buckets of the given interval.
+func (s *EngagementService) PostStats(postID int, from, to time.Time, interval string) (*models.EngagementStats, error) {
+	return s.stats("post_engagement_rollups", "post_id", "v.post_id = ?", postID, from, to, interval)
+}
+
+// SeriesStats returns the engagement time series of a series, counting each
+// wallet once per bucket across all parts. Views are the sum of the unique
+// daily views of its parts.
+func (s *EngagementService) SeriesStats(seriesID int, from, to time.Time, interval string) (*models.EngagementStats, error) {
+	return s.stats("series_engagement_rollups", "series_id", "v.post_id IN (SELECT id FROM posts WHERE series_id = ?)", seriesID, from, to, interval)
+}
+
+// stats reads the rollups of table and the views matching viewFilter, a
+// condition on post_daily_views v with a single id placeholder.
+func (s *EngagementService) stats(table, idColumn, viewFilter string, id int, from, to time.Time, interval string) (*models.EngagementStats, error) {
+	if _, ok := engagementIntervals[interval]; !ok {
+		return nil, ErrInvalidInterval
+	}
+
+	buckets := bucketStarts(from, to, interval)
+	if len(buckets) == 0 || len(buckets) > MaxEngagementPoints {
+		return nil, ErrInvalidStatsRange
+	}
+
+	stats := &models.EngagementStats{
+		Interval: interval,
+		From:     from.Format(dateLayout),
+		To:       to.Format(dateLayout),
+		Points:   make([]models.EngagementPoint, len(buckets)),
+	}
+	index := make(map[string]int, len(buckets))
+	for i, b := range buckets {
+		stats.Points[i].Date = b
+		index[b] = i
+	}
+
+	rows, err := s.db.Query(`
+		SELECT bucket, likes, unlikes, comments, engaged_wallets FROM `+table+`
+		WHERE `+idColumn+` = ? AND interval = ? AND bucket >= ? AND bucket <= ?
+	`, id, interval, buckets[0], buckets[len(buckets)-1])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.EngagementPoint
+		if err := rows.Scan(&p.Date, &p.Likes, &p.Unlikes, &p.Comments, &p.EngagedWallets); err != nil {
+			return nil, err
+		}
+		if i, ok := index[p.Date]; ok {
+			stats.Points[i] = p
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Views are counted per day, so buckets are computed at query time
+	bucket := bucketExpr(interval, "v.day")
+	viewRows, err := s.db.Query(`
+		SELECT `+bucket+` AS bucket, SUM(v.views) FROM post_daily_views v
+		WHERE `+viewFilter+` AND v.day >= ? AND v.day <= ?
+		GROUP BY bucket
+	`, id, buckets[0], to.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer viewRows.Close()
+
+	for viewRows.Next() {
+		var date string
+		var views int
+		if err := viewRows.Scan(&date, &views); err != nil {
+			return nil, err
+		}
+		if i, ok := index[date]; ok {
+			stats.Points[i].Views = views
+		}
+	}
+
+	return stats, viewRows.Err()
+}
+
+// bucketStarts lists the first day of every bucket overlapping [from, to].
+func bucketStarts(from, to time.Time, interval string) []string {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case "week":
+		from = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+	case "month":
+		from = from.AddDate(0, 0, 1-from.Day())
+	}
+
+	var buckets []string
+	for b := from; !b.After(to) && len(buckets) <= MaxEngagementPoints; {
+		buckets = append(buckets, b.Format(dateLayout))
+		switch interval {
+		case "week":
+			b = b.AddDate(0, 0, 7)
+		case "month":
+			b = b.AddDate(0, 1, 0)
+		default:
+			b = b.AddDate(0, 0, 1)
+		}
+	}
+	return buckets
+}
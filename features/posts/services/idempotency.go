@@ -0,0 +1,101 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// IdempotencyKeyTTL is how long a completed write can be replayed with the
+// same idempotency key.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// IdempotencyLease is how long a key stays reserved by a request that
+// neither completed nor released it, e.g. because the process crashed.
+const IdempotencyLease = time.Minute
+
+var (
+	ErrIdempotencyInProgress = errors.New("a request with this idempotency key is in progress")
+	ErrIdempotencyMismatch   = errors.New("idempotency key was used for a different request")
+)
+
+// StoredResponse is the response recorded for an idempotency key.
+type StoredResponse struct {
+	StatusCode int
+	Body       []byte
+}
+
+// IdempotencyService records the outcome of writes by (wallet, key) so that
+// retried requests replay the original response instead of repeating the
+// write.
+type IdempotencyService struct {
+	db *sql.DB
+}
+
+func NewIdempotencyService(db *sql.DB) *IdempotencyService {
+	return &IdempotencyService{db: db}
+}
+
+// Begin reserves key for the wallet. It returns nil when the caller should
+// process the request and then Complete or Release the key, or the stored
+// response when the request was already processed.
+// fingerprint identifies the request (e.g. method, path and payload hash); reusing a key
+// for another request returns ErrIdempotencyMismatch. Reservations older
+// than IdempotencyLease are reclaimed.
+func (s *IdempotencyService) Begin(walletID int, key, fingerprint string) (*StoredResponse, error) {
+	now := time.Now().UTC()
+	_, err := s.db.Exec(
+		"DELETE FROM idempotency_keys WHERE created_at < ? OR (status_code = 0 AND created_at < ?)",
+		now.Add(-IdempotencyKeyTTL), now.Add(-IdempotencyLease),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Exec(
+		"INSERT OR IGNORE INTO idempotency_keys (wallet_id, key, fingerprint, created_at) VALUES (?, ?, ?, ?)",
+		walletID, key, fingerprint, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 1 {
+		return nil, nil
+	}
+
+	var stored StoredResponse
+	var storedFingerprint string
+	err = s.db.QueryRow(
+		"SELECT fingerprint, status_code, response FROM idempotency_keys WHERE wallet_id = ? AND key = ?",
+		walletID, key,
+	).Scan(&storedFingerprint, &stored.StatusCode, &stored.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if storedFingerprint != fingerprint {
+		return nil, ErrIdempotencyMismatch
+	}
+	if stored.StatusCode == 0 {
+		return nil, ErrIdempotencyInProgress
+	}
+	return &stored, nil
+}
+
+// Complete records the response of a request reserved with Begin.
+func (s *IdempotencyService) Complete(walletID int, key string, statusCode int, body []byte) error {
+	_, err := s.db.Exec(
+		"UPDATE idempotency_keys SET status_code = ?, response = ? WHERE wallet_id = ? AND key = ?",
+		statusCode, body, walletID, key,
+	)
+	return err
+}
+
+// Release drops a reservation so that the request can be retried, e.g.
+// after a server error.
+func (s *IdempotencyService) Release(walletID int, key string) error {
+	_, err := s.db.Exec("DELETE FROM idempotency_keys WHERE wallet_id = ? AND key = ?", walletID, key)
+	return err
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrWalletNotFound = errors.New("wallet not found")
+	ErrLikesHidden    = errors.New("likes are hidden")
+)
+
+// ListLikers returns the wallets that liked a post, most recent first.
+// Wallets that hide their likes are left out and only counted.
+func (s *PostService) ListLikers(postID, limit, offset int) (*models.PostLikesResponse, error) {
+	resp := &models.PostLikesResponse{Likes: []models.Liker{}}
+
+	err := s.db.QueryRow(`
+		SELECT
+			COALESCE(SUM(w.hide_likes = 0), 0),
+			COALESCE(SUM(w.hide_likes != 0), 0)
+		FROM post_likes pl
+		JOIN wallets w ON w.id = pl.wallet_id
+		WHERE pl.post_id = ?
+	`, postID).Scan(&resp.Total, &resp.Hidden)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT w.address, w.display_name, pl.created_at
+		FROM post_likes pl
+		JOIN wallets w ON w.id = pl.wallet_id
+		WHERE pl.post_id = ? AND w.hide_likes = 0
+		ORDER BY pl.created_at DESC, w.id DESC
+		LIMIT ? OFFSET ?
+	`, postID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l models.Liker
+		if err := rows.Scan(&l.Address, &l.DisplayName, &l.LikedAt); err != nil {
+			return nil, err
+		}
+		resp.Likes = append(resp.Likes, l)
+	}
+
+	return resp, rows.Err()
+}
+
+// ListWalletLikes returns the posts a wallet liked, most recent first.
+// Returns ErrWalletNotFound for unknown wallets and ErrLikesHidden for
+// wallets that keep their likes private.
+func (s *PostService) ListWalletLikes(address string, limit, offset int) (*models.WalletLikesResponse, error) {
+	address = strings.ToLower(address)
+
+	var walletID int
+	var hidden bool
+	err := s.db.QueryRow("SELECT id, hide_likes FROM wallets WHERE address = ?", address).Scan(&walletID, &hidden)
+	if err == sql.ErrNoRows {
+		return nil, ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		return nil, ErrLikesHidden
+	}
+
+	resp := &models.WalletLikesResponse{Address: address, Likes: []models.LikedPost{}}
+	err = s.db.QueryRow("SELECT COUNT(*) FROM post_likes WHERE wallet_id = ?", walletID).Scan(&resp.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT p.path_identifier, COALESCE(m.title, ''), pl.created_at
+		FROM post_likes pl
+		JOIN posts p ON p.id = pl.post_id
+		LEFT JOIN post_metadata m ON m.post_id = p.id
+		WHERE pl.wallet_id = ?
+		ORDER BY pl.created_at DESC, p.id DESC
+		LIMIT ? OFFSET ?
+	`, walletID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l models.LikedPost
+		if err := rows.Scan(&l.Path, &l.Title, &l.LikedAt); err != nil {
+			return nil, err
+		}
+		resp.Likes = append(resp.Likes, l)
+	}
+
+	return resp, rows.Err()
+}
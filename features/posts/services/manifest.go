@@ -0,0 +1,419 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnsupportedManifest = errors.New("unsupported manifest format")
+
+// LoadManifest reads a manifest from source, which may be an http(s) URL,
+// a local .json or .xml (sitemap) file, or a content directory of Markdown
+// files with front-matter. pathPrefix is stripped from sitemap URLs to obtain
+// post paths (e.g. "blog/" for https://example.com/en/blog/series/post).
+func LoadManifest(source, pathPrefix string) (*models.Manifest, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		data, err := fetchManifest(source)
+		if err != nil {
+			return nil, err
+		}
+		return parseManifestData(source, data, pathPrefix)
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return LoadContentDir(source)
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	return parseManifestData(source, data, pathPrefix)
+}
+
+func fetchManifest(source string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching manifest: unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// parseManifestData picks a parser from the source extension, falling back
+// to sniffing the content.
+func parseManifestData(source string, data []byte, pathPrefix string) (*models.Manifest, error) {
+	ext := strings.ToLower(filepath.Ext(strings.SplitN(source, "?", 2)[0]))
+	trimmed := bytes.TrimSpace(data)
+
+	switch {
+	case ext == ".json", bytes.HasPrefix(trimmed, []byte("{")), bytes.HasPrefix(trimmed, []byte("[")):
+		return ParseJSONManifest(data)
+	case ext == ".xml", bytes.HasPrefix(trimmed, []byte("<")):
+		return ParseSitemap(data, pathPrefix)
+	default:
+		return nil, ErrUnsupportedManifest
+	}
+}
+
+// wordsPerMinute is the reading speed used to estimate reading time when the
+// front-matter doesn't provide one.
+const wordsPerMinute = 200
+
+var walletAddressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// jsonManifestEntry mirrors models.ManifestEntry but accepts dates either as
+// RFC 3339 timestamps or plain YYYY-MM-DD.
+type jsonManifestEntry struct {
+	models.ManifestEntry
+	PublishedAt string `json:"published_at,omitempty"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
+}
+
+// ParseJSONManifest parses either a {"posts": [...], "series": [...]} object
+// or a bare array of post entries. Like in sitemaps, a leading locale
+// segment of a path is recorded as the entry locale unless one is given.
+func ParseJSONManifest(data []byte) (*models.Manifest, error) {
+	var doc struct {
+		Posts  []jsonManifestEntry     `json:"posts"`
+		Series []models.ManifestSeries `json:"series"`
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &doc.Posts); err != nil {
+			return nil, fmt.Errorf("invalid manifest: %w", err)
+		}
+	} else if err := json.Unmarshal(trimmed, &doc); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	manifest := &models.Manifest{Series: doc.Series}
+	for _, raw := range doc.Posts {
+		entry := raw.ManifestEntry
+		locale, path := splitLocale(normalizePath(entry.Path))
+		entry.Path = path
+		if entry.Locale == "" {
+			entry.Locale = locale
+		}
+		if entry.Path == "" {
+			return nil, fmt.Errorf("invalid manifest: entry without path")
+		}
+		for _, date := range []struct {
+			raw string
+			dst **time.Time
+		}{{raw.PublishedAt, &entry.PublishedAt}, {raw.UpdatedAt, &entry.UpdatedAt}} {
+			if date.raw == "" {
+				continue
+			}
+			t, err := parseDate(date.raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid manifest: %s: %w", entry.Path, err)
+			}
+			*date.dst = &t
+		}
+		for _, author := range entry.Authors {
+			if !walletAddressPattern.MatchString(author) {
+				return nil, fmt.Errorf("invalid manifest: %s: author %q is not a wallet address", entry.Path, author)
+			}
+		}
+		manifest.Posts = append(manifest.Posts, entry)
+	}
+
+	return manifest, nil
+}
+
+// ParseSitemap extracts post paths from a sitemap.xml. A leading locale
+// segment is recorded as the entry locale, and URLs that don't start with
+// pathPrefix (after the locale) are skipped as non-post pages. The lastmod
+// date, when present, becomes the entry's updated date.
+func ParseSitemap(data []byte, pathPrefix string) (*models.Manifest, error) {
+	var doc struct {
+		URLs []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+		} `xml:"url"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid sitemap: %w", err)
+	}
+
+	pathPrefix = normalizePath(pathPrefix)
+	manifest := &models.Manifest{}
+	for _, u := range doc.URLs {
+		parsed, err := url.Parse(strings.TrimSpace(u.Loc))
+		if err != nil {
+			continue
+		}
+
+		locale, path := splitLocale(normalizePath(parsed.Path))
+		if pathPrefix != "" {
+			rest, ok := strings.CutPrefix(path, pathPrefix+"/")
+			if !ok {
+				continue
+			}
+			path = rest
+		}
+		if path == "" {
+			continue
+		}
+
+		entry := models.ManifestEntry{Path: path, Locale: locale}
+		if t, err := parseDate(strings.TrimSpace(u.LastMod)); err == nil {
+			entry.UpdatedAt = &t
+		}
+		manifest.Posts = append(manifest.Posts, entry)
+	}
+
+	return manifest, nil
+}
+
+// LoadContentDir builds a manifest from Markdown files under dir. The post
+// path is the file path relative to dir without extension ("index" files
+// take their directory name), minus a leading locale segment. Front-matter
+// provides title, summary, series, tags, authors, locale and dates; drafts
+// are skipped. Reading time is estimated from the body unless given.
+func LoadContentDir(dir string) (*models.Manifest, error) {
+	manifest := &models.Manifest{}
+
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(file))
+		if d.IsDir() || (ext != ".md" && ext != ".mdx") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		rel = strings.TrimSuffix(filepath.ToSlash(rel), filepath.Ext(rel))
+		if base := filepath.Base(rel); base == "index" {
+			rel = filepath.ToSlash(filepath.Dir(rel))
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		fm, body := parseFrontMatter(data)
+		entry, ok, err := entryFromFrontMatter(rel, fm)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if ok {
+			if entry.ReadingTime == 0 {
+				entry.ReadingTime = estimateReadingTime(body)
+			}
+			manifest.Posts = append(manifest.Posts, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func entryFromFrontMatter(rel string, fm map[string]any) (models.ManifestEntry, bool, error) {
+	if fm["draft"] == "true" {
+		return models.ManifestEntry{}, false, nil
+	}
+
+	locale, path := splitLocale(normalizePath(rel))
+	entry := models.ManifestEntry{Path: path, Locale: locale}
+	if path == "" || path == "." {
+		return entry, false, nil
+	}
+
+	entry.Title = frontMatterString(fm, "title")
+	entry.Summary = frontMatterString(fm, "summary", "description")
+	entry.Series = frontMatterString(fm, "series")
+	if l := frontMatterString(fm, "locale", "lang"); l != "" {
+		entry.Locale = l
+	}
+	if tags, ok := fm["tags"].([]string); ok {
+		entry.Tags = tags
+	}
+	// Author names are common in front-matter; only wallet addresses are kept
+	if authors, ok := fm["authors"].([]string); ok {
+		entry.Authors = []string{}
+		for _, a := range authors {
+			if walletAddressPattern.MatchString(a) {
+				entry.Authors = append(entry.Authors, a)
+			}
+		}
+	}
+	if minutes := frontMatterString(fm, "reading_time", "readingTime"); minutes != "" {
+		entry.ReadingTime = leadingInt(minutes)
+	}
+
+	for _, date := range []struct {
+		keys []string
+		dst  **time.Time
+	}{
+		{[]string{"published_at", "publishedAt", "date"}, &entry.PublishedAt},
+		{[]string{"updated_at", "updatedAt", "lastmod"}, &entry.UpdatedAt},
+	} {
+		value := frontMatterString(fm, date.keys...)
+		if value == "" {
+			continue
+		}
+		t, err := parseDate(value)
+		if err != nil {
+			return entry, false, err
+		}
+		*date.dst = &t
+	}
+
+	return entry, true, nil
+}
+
+// estimateReadingTime returns the minutes needed to read body, rounded up.
+func estimateReadingTime(body string) int {
+	words := len(strings.Fields(body))
+	if words == 0 {
+		return 0
+	}
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// leadingInt parses the number at the start of s, e.g. "7 min" → 7.
+func leadingInt(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
+
+func frontMatterString(fm map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if v, ok := fm[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseFrontMatter reads the YAML front-matter block delimited by "---"
+// lines. Only the subset used by the content folder is supported: scalar
+// "key: value" pairs, inline lists ("[a, b]") and block lists ("- a").
+// Scalars are returned as strings and lists as []string, along with the
+// document body that follows the block.
+func parseFrontMatter(data []byte) (map[string]any, string) {
+	fm := map[string]any{}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return fm, string(data)
+	}
+
+	var listKey string
+	body := ""
+	for i, line := range lines[1:] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "---" {
+			body = strings.Join(lines[i+2:], "\n")
+			break
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && listKey != "" {
+			list, _ := fm[listKey].([]string)
+			fm[listKey] = append(list, unquote(item))
+			continue
+		}
+
+		key, value, found := strings.Cut(trimmed, ":")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch {
+		case value == "":
+			listKey = key
+			fm[key] = []string{}
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			listKey = ""
+			items := []string{}
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = unquote(strings.TrimSpace(item)); item != "" {
+					items = append(items, item)
+				}
+			}
+			fm[key] = items
+		default:
+			listKey = ""
+			fm[key] = unquote(value)
+		}
+	}
+
+	return fm, body
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+func normalizePath(path string) string {
+	return strings.Trim(strings.TrimSpace(path), "/")
+}
+
+// splitLocale separates a leading two-letter locale segment ("en/...",
+// "es/...") from a path.
+func splitLocale(path string) (locale, rest string) {
+	first, remainder, found := strings.Cut(path, "/")
+	if found && isLocaleSegment(first) {
+		return first, remainder
+	}
+	return "", path
+}
+
+func isLocaleSegment(s string) bool {
+	return len(s) == 2 && s[0] >= 'a' && s[0] <= 'z' && s[1] >= 'a' && s[1] <= 'z'
+}
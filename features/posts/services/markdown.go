@@ -0,0 +1,210 @@
+package services
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	htmlrenderer "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// MarkdownVersion identifies the Markdown renderer and sanitizer policy.
+// Bump it whenever either changes: comments rendered by an older version
+// are re-rendered at startup.
+const MarkdownVersion = 1
+
+// markdown renders CommonMark with the GFM strikethrough, autolink and
+// table extensions. Raw HTML is omitted, images become links, and math
+// between $...$ or $$...$$ is passed through for KaTeX.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		mathExtension{},
+	),
+	goldmark.WithParserOptions(parser.WithASTTransformers(util.Prioritized(linkTransformer{}, 100))),
+	goldmark.WithRendererOptions(htmlrenderer.WithHardWraps()),
+)
+
+// markdownPolicy is the allowlist applied to rendered comments.
+var markdownPolicy = func() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements("p", "br", "hr", "em", "strong", "del", "blockquote", "pre", "code", "span",
+		"ul", "ol", "li", "h1", "h2", "h3", "h4", "h5", "h6",
+		"table", "thead", "tbody", "tr", "th", "td")
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^math (inline|display)$`)).OnElements("span")
+
+	p.AllowStandardURLs()
+	p.AllowAttrs("href", "title").OnElements("a")
+	p.AllowAttrs("rel").Matching(regexp.MustCompile(`^nofollow ugc$`)).OnElements("a")
+	p.RequireNoFollowOnLinks(true)
+	return p
+}()
+
+// RenderMarkdown renders a comment body to sanitized HTML. Math is
+// rendered as <span class="math inline"> or <span class="math display">
+// elements holding the TeX source.
+func RenderMarkdown(source string) string {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		return "<p>" + html.EscapeString(source) + "</p>"
+	}
+	return markdownPolicy.Sanitize(buf.String())
+}
+
+// linkTransformer marks links as user-generated and turns images into
+// links, so that comments can't embed remote content.
+type linkTransformer struct{}
+
+func (linkTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var images []*ast.Image
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link, *ast.AutoLink:
+			n.SetAttributeString("rel", []byte("nofollow ugc"))
+		case *ast.Image:
+			images = append(images, n)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, img := range images {
+		link := ast.NewLink()
+		link.Destination = img.Destination
+		link.Title = img.Title
+		link.SetAttributeString("rel", []byte("nofollow ugc"))
+		for c := img.FirstChild(); c != nil; {
+			next := c.NextSibling()
+			link.AppendChild(link, c)
+			c = next
+		}
+		img.Parent().ReplaceChild(img.Parent(), img, link)
+	}
+}
+
+var kindMath = ast.NewNodeKind("Math")
+
+// mathNode is a TeX formula. Display formulas were written between $$.
+type mathNode struct {
+	ast.BaseInline
+	tex     []byte
+	display bool
+}
+
+func (n *mathNode) Kind() ast.NodeKind {
+	return kindMath
+}
+
+func (n *mathNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"TeX": string(n.tex)}, nil)
+}
+
+type mathExtension struct{}
+
+func (mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(mathParser{}, 150)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(mathRenderer{}, 150)))
+}
+
+// mathParser parses $$...$$, which may span lines, and $...$ within a
+// line. Like Pandoc, inline math must not start or end with a space and
+// its closing $ must not be followed by a digit, so prices are left alone.
+type mathParser struct{}
+
+func (mathParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (mathParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if bytes.HasPrefix(line, []byte("$$")) {
+		return parseDisplayMath(block)
+	}
+
+	end := inlineMathEnd(line)
+	if end < 0 {
+		return nil
+	}
+	block.Advance(end + 1)
+	return &mathNode{tex: line[1:end]}
+}
+
+func parseDisplayMath(block text.Reader) ast.Node {
+	startLine, startPos := block.Position()
+	block.Advance(2)
+
+	var tex []byte
+	for {
+		line, _ := block.PeekLine()
+		if line == nil {
+			block.SetPosition(startLine, startPos)
+			return nil
+		}
+		if i := bytes.Index(line, []byte("$$")); i >= 0 {
+			tex = append(tex, line[:i]...)
+			block.Advance(i + 2)
+			break
+		}
+		tex = append(tex, line...)
+		block.AdvanceLine()
+	}
+
+	tex = bytes.TrimSpace(tex)
+	if len(tex) == 0 {
+		block.SetPosition(startLine, startPos)
+		return nil
+	}
+	return &mathNode{tex: tex, display: true}
+}
+
+// inlineMathEnd returns the index of the $ closing the inline formula
+// opened at line[0], or -1.
+func inlineMathEnd(line []byte) int {
+	if len(line) < 3 || util.IsSpace(line[1]) {
+		return -1
+	}
+	for i := 2; i < len(line); i++ {
+		if line[i] != '$' || line[i-1] == '\\' || util.IsSpace(line[i-1]) {
+			continue
+		}
+		if i+1 < len(line) && line[i+1] >= '0' && line[i+1] <= '9' {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+type mathRenderer struct{}
+
+func (mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMath, func(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		n := node.(*mathNode)
+		class := "math inline"
+		if n.display {
+			class = "math display"
+		}
+		_, _ = w.WriteString(`<span class="` + class + `">`)
+		_, _ = w.Write(util.EscapeHTML(n.tex))
+		_, _ = w.WriteString("</span>")
+		return ast.WalkSkipChildren, nil
+	})
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"context"
+	"database/sql"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// MaxMentions is the number of distinct names resolved per comment; later
+// ones are left as plain text.
+const MaxMentions = 10
+
+// nameResolveTimeout bounds the ENS lookups of one comment.
+const nameResolveTimeout = 3 * time.Second
+
+// NameResolver resolves ENS names to addresses.
+type NameResolver interface {
+	ResolveName(ctx context.Context, name string) (string, error)
+}
+
+// SetNameResolver enables mentions by ENS name. Without a resolver they
+// are left as plain text.
+func (s *CommentService) SetNameResolver(r NameResolver) {
+	s.resolver = r
+}
+
+// mentionPattern matches @address and @name, where a name is an ENS name
+// or a display name with its spaces written as underscores.
+var mentionPattern = regexp.MustCompile(`@(0x[0-9a-fA-F]{40}|[\p{L}\p{N}_][\p{L}\p{N}_.-]*)`)
+
+// mentionCandidate is an @name found in a comment body.
+type mentionCandidate struct {
+	offset int
+	name   string
+}
+
+// mention is a mention resolved to a wallet.
+type mention struct {
+	models.Mention
+	walletID int
+}
+
+// findMentions returns the @names of a Markdown body, leaving out code.
+// The @ must start the body or follow a space or opening punctuation, so
+// that e-mail addresses and URLs don't count.
+func findMentions(body string) []mentionCandidate {
+	code := codeRanges([]byte(body))
+
+	var found []mentionCandidate
+	for _, m := range mentionPattern.FindAllStringSubmatchIndex(body, -1) {
+		start := m[0]
+		if start > 0 {
+			r, _ := utf8.DecodeLastRuneInString(body[:start])
+			if !unicode.IsSpace(r) && !strings.ContainsRune(`([{<>"'*_~`, r) {
+				continue
+			}
+		}
+		if inRanges(code, start) {
+			continue
+		}
+		// Sentence punctuation isn't part of the name
+		name := strings.TrimRight(body[m[2]:m[3]], ".-")
+		found = append(found, mentionCandidate{offset: start, name: name})
+	}
+	return found
+}
+
+// codeRanges returns the byte ranges of the code spans and blocks of a
+// Markdown body.
+func codeRanges(source []byte) [][2]int {
+	var ranges [][2]int
+	doc := markdown.Parser().Parse(text.NewReader(source))
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.CodeSpan:
+			for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+				if t, ok := c.(*ast.Text); ok {
+					ranges = append(ranges, [2]int{t.Segment.Start, t.Segment.Stop})
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeBlock, *ast.FencedCodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				seg := lines.At(i)
+				ranges = append(ranges, [2]int{seg.Start, seg.Stop})
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return ranges
+}
+
+func inRanges(ranges [][2]int, offset int) bool {
+	for _, r := range ranges {
+		if offset >= r[0] && offset < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionedWallet is the wallet a name resolved to.
+type mentionedWallet struct {
+	id          int
+	address     string
+	displayName string
+}
+
+// resolveMentions finds the mentions of a comment body on a post and
+// resolves them to wallets. Names that match no wallet are left out.
+func (s *CommentService) resolveMentions(postID int, body string) ([]mention, error) {
+	candidates := findMentions(body)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), nameResolveTimeout)
+	defer cancel()
+
+	resolved := map[string]*mentionedWallet{}
+	var mentions []mention
+	for _, c := range candidates {
+		key := strings.ToLower(c.name)
+		w, seen := resolved[key]
+		if !seen {
+			if len(resolved) == MaxMentions {
+				continue
+			}
+			var err error
+			if w, err = s.resolveMention(ctx, postID, key); err != nil {
+				return nil, err
+			}
+			resolved[key] = w
+		}
+		if w == nil {
+			continue
+		}
+		mentions = append(mentions, mention{
+			walletID: w.id,
+			Mention: models.Mention{
+				Offset:      c.offset,
+				Length:      1 + len(c.name),
+				Address:     w.address,
+				DisplayName: w.displayName,
+			},
+		})
+	}
+	return mentions, nil
+}
+
+// ensTLDs are the top-level domains of names resolved through ENS.
+var ensTLDs = []string{".eth"}
+
+// isENSName reports whether a lowercased name ends in an ENS top-level
+// domain.
+func isENSName(name string) bool {
+	for _, tld := range ensTLDs {
+		if strings.HasSuffix(name, tld) && len(name) > len(tld) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveMention finds the wallet of a lowercased name: an address, an
+// ENS name, or the display name of a single wallet that commented on the
+// post. It returns nil when there is none.
+func (s *CommentService) resolveMention(ctx context.Context, postID int, name string) (*mentionedWallet, error) {
+	query := "SELECT id, address, display_name FROM wallets WHERE "
+	var args []any
+	switch {
+	case strings.HasPrefix(name, "0x") && len(name) == 42:
+		query += "address = ?"
+		args = []any{name}
+	case strings.Contains(name, "."):
+		if s.resolver == nil || !isENSName(name) {
+			return nil, nil
+		}
+		address, err := s.resolver.ResolveName(ctx, name)
+		if err != nil {
+			// An unresolvable name is just text
+			log.Printf("[Comments] Failed to resolve %s: %v", name, err)
+			return nil, nil
+		}
+		query += "address = ?"
+		args = []any{strings.ToLower(address)}
+	default:
+		query += `LOWER(REPLACE(display_name, ' ', '_')) = ?
+			AND id IN (SELECT wallet_id FROM comments WHERE post_id = ?)
+			LIMIT 2`
+		args = []any{name, postID}
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []mentionedWallet
+	for rows.Next() {
+		var w mentionedWallet
+		if err := rows.Scan(&w.id, &w.address, &w.displayName); err != nil {
+			return nil, err
+		}
+		matches = append(matches, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Display names are not unique; ambiguous ones resolve to nobody
+	if len(matches) != 1 {
+		return nil, nil
+	}
+	return &matches[0], nil
+}
+
+// saveMentions replaces the stored mentions of a comment and returns the
+// wallets that were mentioned before.
+func saveMentions(tx *sql.Tx, commentID int, mentions []mention) (map[int]bool, error) {
+	rows, err := tx.Query("SELECT DISTINCT wallet_id FROM comment_mentions WHERE comment_id = ?", commentID)
+	if err != nil {
+		return nil, err
+	}
+	previous := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		previous[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM comment_mentions WHERE comment_id = ?", commentID); err != nil {
+		return nil, err
+	}
+	for _, m := range mentions {
+		_, err := tx.Exec(
+			"INSERT INTO comment_mentions (comment_id, position, length, wallet_id) VALUES (?, ?, ?, ?)",
+			commentID, m.Offset, m.Length, m.walletID,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return previous, nil
+}
+
+// mentionEntities returns the public part of resolved mentions.
+func mentionEntities(mentions []mention) []models.Mention {
+	if len(mentions) == 0 {
+		return nil
+	}
+	entities := make([]models.Mention, len(mentions))
+	for i, m := range mentions {
+		entities[i] = m.Mention
+	}
+	return entities
+}
+
+// notifyMentions tells the wallets mentioned in a comment, except those in
+// skip, e.g. because its previous revision already mentioned them.
+func (s *CommentService) notifyMentions(c *models.Comment, mentions []mention, skip map[int]bool) {
+	var recipients []int
+	seen := map[int]bool{}
+	for _, m := range mentions {
+		if !seen[m.walletID] && !skip[m.walletID] {
+			recipients = append(recipients, m.walletID)
+		}
+		seen[m.walletID] = true
+	}
+	s.notify(CommentEvent{
+		Kind:       EventMention,
+		CommentID:  c.ID,
+		PostID:     c.PostID,
+		ActorID:    c.WalletID,
+		Recipients: recipients,
+	})
+}
+
+// mentionsOf loads the mentions of comments, by comment ID.
+func (s *CommentService) mentionsOf(commentIDs []int) (map[int][]models.Mention, error) {
+	mentions := map[int][]models.Mention{}
+	if len(commentIDs) == 0 {
+		return mentions, nil
+	}
+
+	placeholders := make([]string, len(commentIDs))
+	args := make([]any, len(commentIDs))
+	for i, id := range commentIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	rows, err := s.db.Query(`
+		SELECT m.comment_id, m.position, m.length, w.address, w.display_name
+		FROM comment_mentions m
+		JOIN wallets w ON w.id = m.wallet_id
+		WHERE m.comment_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY m.comment_id, m.position
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commentID int
+		var m models.Mention
+		if err := rows.Scan(&commentID, &m.Offset, &m.Length, &m.Address, &m.DisplayName); err != nil {
+			return nil, err
+		}
+		mentions[commentID] = append(mentions[commentID], m)
+	}
+	return mentions, rows.Err()
+}
+
+// attachMentions fills the mentions of listed comments.
+func (s *CommentService) attachMentions(comments []*models.CommentResponse) error {
+	ids := make([]int, len(comments))
+	for i, c := range comments {
+		ids[i] = c.ID
+	}
+	mentions, err := s.mentionsOf(ids)
+	if err != nil {
+		return err
+	}
+	for _, c := range comments {
+		c.Mentions = mentions[c.ID]
+	}
+	return nil
+}
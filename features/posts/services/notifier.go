@@ -0,0 +1,51 @@
+package services
+
+// Kinds of comment events: a reply to the recipient's comment, a mention
+// of the recipient, and an upvote on the recipient's comment.
+const (
+	EventReply    = "reply"
+	EventMention  = "mention"
+	EventReaction = "reaction"
+)
+
+// CommentEvent is activity on a comment that concerns other wallets: the
+// actor did something of the given kind to the comment, and the recipients
+// should hear about it. The actor is never among the recipients.
+type CommentEvent struct {
+	Kind       string
+	CommentID  int
+	PostID     int
+	ActorID    int
+	Recipients []int
+}
+
+// Notifier receives comment events, e.g. to fill notification inboxes. It
+// is called after the change is committed and must not block for long.
+type Notifier interface {
+	Notify(event CommentEvent)
+}
+
+// SetNotifier sets the receiver of comment events. Events are dropped
+// while it is unset.
+func (s *CommentService) SetNotifier(n Notifier) {
+	s.notifier = n
+}
+
+// notify sends an event to the notifier, leaving the actor out of the
+// recipients. Events without recipients are dropped.
+func (s *CommentService) notify(event CommentEvent) {
+	if s.notifier == nil {
+		return
+	}
+	recipients := make([]int, 0, len(event.Recipients))
+	for _, id := range event.Recipients {
+		if id != event.ActorID {
+			recipients = append(recipients, id)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+	event.Recipients = recipients
+	s.notifier.Notify(event)
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"database/sql"
+	"errors"
+)
+
+var (
+	ErrPathTaken = errors.New("path already belongs to another post")
+	ErrSelfMerge = errors.New("cannot merge a post into itself")
+)
+
+// Rename moves a post to a new path. The old path is kept as an alias so
+// that existing links, likes and comments keep resolving to the post.
+// Returns ErrPathTaken if the new path is used by another post.
+func (s *PostService) Rename(from, to string) (*models.Post, error) {
+	post, err := s.GetByPath(from)
+	if err != nil {
+		return nil, err
+	}
+
+	_, newPath := s.SplitLocale(normalizePath(to))
+	if newPath == post.PathIdentifier {
+		return post, nil
+	}
+
+	if target, err := s.GetByPath(newPath); err == nil {
+		if target.ID != post.ID {
+			return nil, ErrPathTaken
+		}
+	} else if err != ErrPostNotFound {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Renaming back to a former path turns the alias into the path again
+	if _, err := tx.Exec("DELETE FROM post_aliases WHERE path_identifier = ?", newPath); err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(
+		"INSERT INTO post_aliases (path_identifier, post_id) VALUES (?, ?)",
+		post.PathIdentifier, post.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(
+		"UPDATE posts SET path_identifier = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		newPath, post.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// A post moved under another prefix leaves its old series
+	if SeriesSlugFromPath(newPath) != SeriesSlugFromPath(post.PathIdentifier) {
+		_, err := tx.Exec("UPDATE posts SET series_id = NULL, series_position = NULL WHERE id = ?", post.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := assignSeriesFromPath(tx, post.ID, newPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.getByID(post.ID)
+}
+
+// Merge folds the post at from into the post at into. Likes and reactions
+// are moved with duplicates dropped, comments and translations are
+// re-pointed, and the source path becomes an alias of the target before the
+// source is deleted.
+// Returns ErrSelfMerge if both paths resolve to the same post.
+func (s *PostService) Merge(from, into string) (*models.Post, error) {
+	source, err := s.GetByPath(from)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.GetByPath(into)
+	if err != nil {
+		return nil, err
+	}
+	if source.ID == target.ID {
+		return nil, ErrSelfMerge
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := mergePosts(tx, source.ID, target.ID); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO post_aliases (path_identifier, post_id) VALUES (?, ?)",
+		source.PathIdentifier, target.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.getByID(target.ID)
+}
+
+// mergePosts moves everything attached to the source post to the target
+// and deletes the source. Tables that reference posts must be handled here.
+func mergePosts(tx *sql.Tx, sourceID, targetID int) error {
+	// Both series lose or gain the moved events
+	var seriesIDs []int
+	rows, err := tx.Query("SELECT DISTINCT series_id FROM posts WHERE id IN (?, ?) AND series_id IS NOT NULL", sourceID, targetID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		seriesIDs = append(seriesIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	statements := []string{
+		// A wallet that liked both posts keeps a single like
+		`INSERT OR IGNORE INTO post_likes (post_id, wallet_id, created_at)
+			SELECT ?2, wallet_id, created_at FROM post_likes WHERE post_id = ?1`,
+		"DELETE FROM post_likes WHERE post_id = ?1",
+		"UPDATE post_like_events SET post_id = ?2 WHERE post_id = ?1",
+		"DELETE FROM post_engagement_rollups WHERE post_id = ?1",
+		"DELETE FROM post_related WHERE ?1 IN (post_id, related_post_id)",
+		// A visitor of both posts on the same day is counted twice
+		`INSERT INTO post_daily_views (post_id, day, views)
+			SELECT ?2, day, views FROM post_daily_views WHERE post_id = ?1
+			ON CONFLICT(post_id, day) DO UPDATE SET views = views + excluded.views`,
+		"DELETE FROM post_daily_views WHERE post_id = ?1",
+		"DELETE FROM post_view_visitors WHERE post_id = ?1",
+		`INSERT OR IGNORE INTO reading_progress (wallet_id, post_id, position, section, completed_at, updated_at)
+			SELECT wallet_id, ?2, position, section, completed_at, updated_at FROM reading_progress WHERE post_id = ?1`,
+		"DELETE FROM reading_progress WHERE post_id = ?1",
+		`INSERT OR IGNORE INTO bookmarks (wallet_id, post_id, created_at)
+			SELECT wallet_id, ?2, created_at FROM bookmarks WHERE post_id = ?1`,
+		"DELETE FROM bookmarks WHERE post_id = ?1",
+		`INSERT OR IGNORE INTO reading_list_items (list_id, post_id, position, created_at)
+			SELECT list_id, ?2, position, created_at FROM reading_list_items WHERE post_id = ?1`,
+		"DELETE FROM reading_list_items WHERE post_id = ?1",
+		`INSERT OR IGNORE INTO post_reactions (post_id, wallet_id, reaction, created_at)
+			SELECT ?2, wallet_id, reaction, created_at FROM post_reactions WHERE post_id = ?1`,
+		"DELETE FROM post_reactions WHERE post_id = ?1",
+		"DELETE FROM post_reaction_counts WHERE post_id IN (?1, ?2)",
+		`INSERT INTO post_reaction_counts (post_id, reaction, count)
+			SELECT ?2, reaction, COUNT(*) FROM post_reactions WHERE post_id = ?2 GROUP BY reaction`,
+		"UPDATE comments SET post_id = ?2 WHERE post_id = ?1",
+		`INSERT OR IGNORE INTO post_locales (post_id, locale, title, created_at)
+			SELECT ?2, locale, title, created_at FROM post_locales WHERE post_id = ?1`,
+		"DELETE FROM post_locales WHERE post_id = ?1",
+		"UPDATE post_aliases SET post_id = ?2 WHERE post_id = ?1",
+		"DELETE FROM post_tags WHERE post_id = ?1",
+		"DELETE FROM post_authors WHERE post_id = ?1",
+		"DELETE FROM post_metadata WHERE post_id = ?1",
+		"DELETE FROM posts WHERE id = ?1",
+		`UPDATE posts SET
+			like_count = (SELECT COUNT(*) FROM post_likes WHERE post_id = ?2),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?2`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt, sourceID, targetID); err != nil {
+			return err
+		}
+	}
+	return rebuildRollups(tx, targetID, seriesIDs)
+}
+
+// FoldLocalePaths folds posts stored under a localized path, e.g. "es/foo"
+// from before translations shared their canonical post, into the post at
+// the canonical path: they are merged into it when it exists and renamed
+// to it otherwise. Their comments keep the locale they were written on.
+// With dryRun set, the report is computed but nothing is written.
+func (s *PostService) FoldLocalePaths(dryRun bool) (*models.FoldReport, error) {
+	rows, err := s.db.Query("SELECT id, path_identifier FROM posts ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	type localized struct {
+		id                      int
+		path, locale, canonical string
+	}
+	var pending []localized
+	for rows.Next() {
+		var id int
+		var path string
+		if err := rows.Scan(&id, &path); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if locale, canonical := s.SplitLocale(path); locale != "" {
+			pending = append(pending, localized{id, path, locale, canonical})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &models.FoldReport{DryRun: dryRun, Folded: []models.LocaleFold{}}
+	if len(pending) == 0 {
+		return report, nil
+	}
+
+	// A dry run folds inside the transaction too, so that posts folded
+	// into the same canonical path are reported as they would be, and
+	// rolls it back
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, p := range pending {
+		_, err := tx.Exec("UPDATE comments SET locale = ? WHERE post_id = ? AND locale = ''", p.locale, p.id)
+		if err != nil {
+			return nil, err
+		}
+
+		var targetID int
+		err = tx.QueryRow("SELECT id FROM posts WHERE path_identifier = ?", p.canonical).Scan(&targetID)
+		switch {
+		case err == sql.ErrNoRows:
+			targetID = p.id
+			_, err = tx.Exec(
+				"UPDATE posts SET path_identifier = ?, series_id = NULL, series_position = NULL WHERE id = ?",
+				p.canonical, p.id,
+			)
+			if err == nil {
+				err = assignSeriesFromPath(tx, p.id, p.canonical)
+			}
+		case err == nil:
+			err = mergePosts(tx, p.id, targetID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Folded = append(report.Folded, models.LocaleFold{
+			Path: p.path, Canonical: p.canonical, Locale: p.locale, Merged: targetID != p.id,
+		})
+
+		_, err = tx.Exec("INSERT OR IGNORE INTO post_locales (post_id, locale) VALUES (?, ?)", targetID, p.locale)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Drop the series that locale prefixes were mistaken for
+	for locale := range s.locales {
+		_, err := tx.Exec(
+			"DELETE FROM series WHERE slug = ? AND NOT EXISTS (SELECT 1 FROM posts WHERE series_id = series.id)",
+			locale,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	return report, tx.Commit()
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"strings"
+)
+
+// List returns posts matching the filter along with the total number of
+// matches, newest first unless sorted by likes.
+func (s *PostService) List(filter models.PostFilter) (*models.PostListResponse, error) {
+	var where []string
+	var args []any
+
+	if filter.Tag != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM post_tags t WHERE t.post_id = p.id AND t.tag = ?)")
+		args = append(args, strings.ToLower(filter.Tag))
+	}
+	if filter.Author != "" {
+		where = append(where, `EXISTS (
+			SELECT 1 FROM post_authors pa JOIN wallets w ON w.id = pa.wallet_id
+			WHERE pa.post_id = p.id AND w.address = ?
+		)`)
+		args = append(args, strings.ToLower(filter.Author))
+	}
+	if filter.Series != "" {
+		where = append(where, "s.slug = ?")
+		args = append(args, filter.Series)
+	}
+
+	from := `
+		FROM posts p
+		LEFT JOIN series s ON s.id = p.series_id
+		LEFT JOIN post_metadata m ON m.post_id = p.id
+	`
+	if len(where) > 0 {
+		from += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*)"+from, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	order := "m.published_at IS NULL, m.published_at DESC, p.id DESC"
+	if filter.Sort == "likes" {
+		order = "p.like_count DESC, p.id ASC"
+	}
+
+	rows, err := s.db.Query(
+		"SELECT p.id, p.path_identifier, p.like_count, COALESCE(s.slug, '')"+from+" ORDER BY "+order+" LIMIT ? OFFSET ?",
+		append(args, filter.Limit, filter.Offset)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	posts := []models.PostSummary{}
+	for rows.Next() {
+		var id int
+		var p models.PostSummary
+		if err := rows.Scan(&id, &p.Path, &p.LikeCount, &p.Series); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+		posts = append(posts, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	metadata, err := s.loadMetadata(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range ids {
+		posts[i].PostMetadata = *metadata[id]
+	}
+
+	return &models.PostListResponse{Posts: posts, Total: total}, nil
+}
+
+// ListTags returns every tag with the number of posts carrying it.
+func (s *PostService) ListTags() (*models.TagsResponse, error) {
+	rows, err := s.db.Query(`
+		SELECT tag, COUNT(*) FROM post_tags
+		GROUP BY tag
+		ORDER BY COUNT(*) DESC, tag ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.TagCount{}
+	for rows.Next() {
+		var t models.TagCount
+		if err := rows.Scan(&t.Tag, &t.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &models.TagsResponse{Tags: tags}, nil
+}
+
+// loadMetadata returns the metadata of each given post. Posts that were
+// never synced get empty metadata rather than a missing entry.
+func (s *PostService) loadMetadata(postIDs []int) (map[int]*models.PostMetadata, error) {
+	metadata := make(map[int]*models.PostMetadata, len(postIDs))
+	if len(postIDs) == 0 {
+		return metadata, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(postIDs)), ",")
+	args := make([]any, len(postIDs))
+	for i, id := range postIDs {
+		args[i] = id
+		metadata[id] = &models.PostMetadata{Tags: []string{}, Authors: []string{}}
+	}
+
+	rows, err := s.db.Query(`
+		SELECT post_id, title, summary, locale, published_at, updated_at, reading_time
+		FROM post_metadata WHERE post_id IN (`+placeholders+`)
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var m models.PostMetadata
+		if err := rows.Scan(&id, &m.Title, &m.Summary, &m.Locale, &m.PublishedAt, &m.UpdatedAt, &m.ReadingTime); err != nil {
+			return nil, err
+		}
+		m.Tags, m.Authors = metadata[id].Tags, metadata[id].Authors
+		metadata[id] = &m
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tagRows, err := s.db.Query(
+		"SELECT post_id, tag FROM post_tags WHERE post_id IN ("+placeholders+") ORDER BY tag",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer tagRows.Close()
+
+	for tagRows.Next() {
+		var id int
+		var tag string
+		if err := tagRows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		metadata[id].Tags = append(metadata[id].Tags, tag)
+	}
+	if err := tagRows.Err(); err != nil {
+		return nil, err
+	}
+
+	authorRows, err := s.db.Query(`
+		SELECT pa.post_id, w.address
+		FROM post_authors pa
+		JOIN wallets w ON w.id = pa.wallet_id
+		WHERE pa.post_id IN (`+placeholders+`)
+		ORDER BY pa.position
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer authorRows.Close()
+
+	for authorRows.Next() {
+		var id int
+		var address string
+		if err := authorRows.Scan(&id, &address); err != nil {
+			return nil, err
+		}
+		metadata[id].Authors = append(metadata[id].Authors, address)
+	}
+
+	return metadata, authorRows.Err()
+}
@@ -4,48 +4,86 @@ import (
 	"arkana/features/posts/models"
 	"database/sql"
 	"errors"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
 )
 
 type PostService struct {
-	db *sql.DB
+	db         *sql.DB
+	locales    map[string]bool
+	reactions  []string
+	autoCreate *AutoCreatePolicy
 }
 
 func NewPostService(db *sql.DB) *PostService {
-	return &PostService{db: db}
+	return &PostService{db: db, locales: map[string]bool{}, reactions: []string{LikeReaction}}
+}
+
+// SetSupportedLocales configures the locale prefixes (e.g. "en", "es") that
+// identify translations of a post rather than part of its path.
+func (s *PostService) SetSupportedLocales(locales []string) {
+	s.locales = make(map[string]bool, len(locales))
+	for _, l := range locales {
+		s.locales[strings.ToLower(l)] = true
+	}
 }
 
-// GetByPath finds a post by path_identifier.
+// SplitLocale separates a supported locale prefix from a path, so that
+// "es/series/post" yields ("es", "series/post"). Paths without a supported
+// prefix are returned unchanged with an empty locale.
+func (s *PostService) SplitLocale(path string) (locale, canonical string) {
+	first, rest, found := strings.Cut(path, "/")
+	if found && s.locales[first] {
+		return first, rest
+	}
+	return "", path
+}
+
+// GetByPath finds a post by path_identifier. Localized paths resolve to the
+// shared canonical post, with the requested locale set on the result, and
+// former paths of renamed or merged posts resolve through their aliases.
 // Returns ErrPostNotFound if the post doesn't exist.
 func (s *PostService) GetByPath(path string) (*models.Post, error) {
-	var p models.Post
-	err := s.db.QueryRow(
-		"SELECT id, path_identifier, like_count, created_at, updated_at FROM posts WHERE path_identifier = ?",
-		path,
-	).Scan(&p.ID, &p.PathIdentifier, &p.LikeCount, &p.CreatedAt, &p.UpdatedAt)
+	locale, canonical := s.SplitLocale(path)
+
+	p, err := scanPost(s.db.QueryRow(
+		"SELECT "+postColumns+" FROM posts WHERE path_identifier = ?",
+		canonical,
+	))
+	if err == sql.ErrNoRows {
+		p, err = scanPost(s.db.QueryRow(
+			"SELECT "+postColumns+" FROM posts WHERE id = (SELECT post_id FROM post_aliases WHERE path_identifier = ?)",
+			canonical,
+		))
+	}
 	if err == sql.ErrNoRows {
 		return nil, ErrPostNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
-	return &p, nil
+	p.Locale = locale
+	return p, nil
 }
 
 // GetOrCreateByPath finds a post by path_identifier, creating it if it doesn't exist.
+// A localized path creates the canonical post and registers the locale variant.
 func (s *PostService) GetOrCreateByPath(path string) (*models.Post, error) {
-	var p models.Post
-	err := s.db.QueryRow(
-		"SELECT id, path_identifier, like_count, created_at, updated_at FROM posts WHERE path_identifier = ?",
-		path,
-	).Scan(&p.ID, &p.PathIdentifier, &p.LikeCount, &p.CreatedAt, &p.UpdatedAt)
+	p, err := s.GetByPath(path)
 	if err == nil {
-		return &p, nil
+		return p, nil
 	}
-	if err != sql.ErrNoRows {
+	if err != ErrPostNotFound {
 		return nil, err
 	}
 
-	result, err := s.db.Exec("INSERT INTO posts (path_identifier) VALUES (?)", path)
+	locale, canonical := s.SplitLocale(path)
+	result, err := s.db.Exec("INSERT INTO posts (path_identifier) VALUES (?)", canonical)
+	if isUniqueViolation(err) {
+		// A concurrent request created the post first
+		return s.GetByPath(path)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +93,24 @@ func (s *PostService) GetOrCreateByPath(path string) (*models.Post, error) {
 		return nil, err
 	}
 
-	return s.getByID(int(id))
+	// New posts join the series named by their path prefix, if any
+	if err := assignSeriesFromPath(s.db, int(id), canonical); err != nil {
+		return nil, err
+	}
+
+	if locale != "" {
+		_, err := s.db.Exec("INSERT OR IGNORE INTO post_locales (post_id, locale) VALUES (?, ?)", id, locale)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p, err = s.getByID(int(id))
+	if err != nil {
+		return nil, err
+	}
+	p.Locale = locale
+	return p, nil
 }
 
 // ToggleLike adds or removes a like for the given wallet on the given post.
@@ -106,6 +161,10 @@ func (s *PostService) ToggleLike(postID, walletID int) (liked bool, likeCount in
 		liked = false
 	}
 
+	if err := recordLikeEvent(tx, postID, walletID, liked); err != nil {
+		return false, 0, err
+	}
+
 	// Read back the current count
 	err = tx.QueryRow("SELECT like_count FROM posts WHERE id = ?", postID).Scan(&likeCount)
 	if err != nil {
@@ -119,12 +178,80 @@ func (s *PostService) ToggleLike(postID, walletID int) (liked bool, likeCount in
 	return liked, likeCount, nil
 }
 
+// SetLike makes the given wallet like or not like the given post. Unlike
+// ToggleLike it is safe to retry: setting the current state changes nothing.
+// Returns the new like count.
+func (s *PostService) SetLike(postID, walletID int, liked bool) (likeCount int, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var result sql.Result
+	delta := 1
+	if liked {
+		result, err = tx.Exec(
+			"INSERT OR IGNORE INTO post_likes (post_id, wallet_id) VALUES (?, ?)",
+			postID, walletID,
+		)
+	} else {
+		delta = -1
+		result, err = tx.Exec(
+			"DELETE FROM post_likes WHERE post_id = ? AND wallet_id = ?",
+			postID, walletID,
+		)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	changed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if changed > 0 {
+		_, err = tx.Exec("UPDATE posts SET like_count = like_count + ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", delta, postID)
+		if err != nil {
+			return 0, err
+		}
+		if err := recordLikeEvent(tx, postID, walletID, liked); err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.QueryRow("SELECT like_count FROM posts WHERE id = ?", postID).Scan(&likeCount)
+	if err != nil {
+		return 0, err
+	}
+
+	return likeCount, tx.Commit()
+}
+
+// recordLikeEvent appends a like or unlike to the event log that feeds the
+// engagement rollups.
+func recordLikeEvent(tx *sql.Tx, postID, walletID int, liked bool) error {
+	kind := "unlike"
+	if liked {
+		kind = "like"
+	}
+	_, err := tx.Exec(
+		"INSERT INTO post_like_events (post_id, wallet_id, kind) VALUES (?, ?, ?)",
+		postID, walletID, kind,
+	)
+	return err
+}
+
 func (s *PostService) getByID(id int) (*models.Post, error) {
+	return scanPost(s.db.QueryRow("SELECT "+postColumns+" FROM posts WHERE id = ?", id))
+}
+
+// postColumns is the column list scanned by scanPost.
+const postColumns = "id, path_identifier, like_count, series_id, series_position, created_at, updated_at"
+
+func scanPost(row *sql.Row) (*models.Post, error) {
 	var p models.Post
-	err := s.db.QueryRow(
-		"SELECT id, path_identifier, like_count, created_at, updated_at FROM posts WHERE id = ?",
-		id,
-	).Scan(&p.ID, &p.PathIdentifier, &p.LikeCount, &p.CreatedAt, &p.UpdatedAt)
+	err := row.Scan(&p.ID, &p.PathIdentifier, &p.LikeCount, &p.SeriesID, &p.SeriesPosition, &p.CreatedAt, &p.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -133,25 +260,39 @@ func (s *PostService) getByID(id int) (*models.Post, error) {
 
 var ErrPostNotFound = errors.New("post not found")
 
-// GetPostInfo returns post info by path, including whether a specific wallet has liked it.
-// If walletAddress is empty, liked will always be false.
+// GetPostInfo returns post info by path, including its metadata and whether a
+// specific wallet has liked it. If walletAddress is empty, liked will always be false.
+// Wallets that hide their likes only reveal them when viewerAddress, the
+// authenticated caller, is that same wallet.
+// Likes are shared by all translations; a localized path returns the title
+// of that translation when one is known.
 // Returns ErrPostNotFound if the post doesn't exist.
-func (s *PostService) GetPostInfo(path string, walletAddress string) (*models.PostInfoResponse, error) {
-	var likeCount int
-	var postID int
-
-	err := s.db.QueryRow(
-		"SELECT id, like_count FROM posts WHERE path_identifier = ?",
-		path,
-	).Scan(&postID, &likeCount)
-
-	if err == sql.ErrNoRows {
-		return nil, ErrPostNotFound
-	}
+func (s *PostService) GetPostInfo(path, walletAddress, viewerAddress string) (*models.PostInfoResponse, error) {
+	post, err := s.GetByPath(path)
 	if err != nil {
 		return nil, err
 	}
 
+	if walletAddress != "" && !strings.EqualFold(walletAddress, viewerAddress) {
+		var hidden bool
+		err = s.db.QueryRow("SELECT hide_likes FROM wallets WHERE LOWER(address) = LOWER(?)", walletAddress).Scan(&hidden)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if hidden {
+			walletAddress = ""
+		}
+	}
+	postID, likeCount := post.ID, post.LikeCount
+
+	var series string
+	if post.SeriesID != nil {
+		err = s.db.QueryRow("SELECT slug FROM series WHERE id = ?", *post.SeriesID).Scan(&series)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Check if wallet has liked this post
 	var liked bool
 	if walletAddress != "" {
@@ -169,9 +310,88 @@ func (s *PostService) GetPostInfo(path string, walletAddress string) (*models.Po
 		}
 	}
 
-	return &models.PostInfoResponse{
-		Path:      path,
-		LikeCount: likeCount,
-		Liked:     liked,
-	}, nil
+	metadata, err := s.loadMetadata([]int{postID})
+	if err != nil {
+		return nil, err
+	}
+
+	var viewCount int
+	err = s.db.QueryRow("SELECT COALESCE(SUM(views), 0) FROM post_daily_views WHERE post_id = ?", postID).Scan(&viewCount)
+	if err != nil {
+		return nil, err
+	}
+
+	locales, err := s.loadLocales(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	reactions, err := s.reactionCounts(postID)
+	if err != nil {
+		return nil, err
+	}
+	myReactions, err := s.walletReactions(postID, walletAddress, liked)
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalPath := post.PathIdentifier
+	if post.Locale != "" {
+		canonicalPath = post.Locale + "/" + canonicalPath
+	}
+
+	info := &models.PostInfoResponse{
+		Path:          path,
+		CanonicalPath: canonicalPath,
+		LikeCount:     likeCount,
+		Liked:         liked,
+		ViewCount:     viewCount,
+		Series:        series,
+		Locales:       []string{},
+		Reactions:     reactions,
+		MyReactions:   myReactions,
+		PostMetadata:  *metadata[postID],
+	}
+	for _, l := range locales {
+		info.Locales = append(info.Locales, l.locale)
+		if l.locale == post.Locale && l.title != "" {
+			info.Title = l.title
+		}
+	}
+	if post.Locale != "" {
+		info.Locale = post.Locale
+	}
+
+	return info, nil
+}
+
+type postLocale struct {
+	locale string
+	title  string
+}
+
+// loadLocales returns the translations registered for a post, by locale.
+func (s *PostService) loadLocales(postID int) ([]postLocale, error) {
+	rows, err := s.db.Query("SELECT locale, title FROM post_locales WHERE post_id = ? ORDER BY locale", postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locales []postLocale
+	for rows.Next() {
+		var l postLocale
+		if err := rows.Scan(&l.locale, &l.title); err != nil {
+			return nil, err
+		}
+		locales = append(locales, l)
+	}
+
+	return locales, rows.Err()
+}
+
+// isUniqueViolation reports whether err is a UNIQUE constraint failure.
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
 }
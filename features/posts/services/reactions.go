@@ -0,0 +1,199 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"database/sql"
+	"errors"
+)
+
+// LikeReaction is the reaction backed by post_likes, so that the like
+// endpoint and the 👍 reaction stay the same thing.
+const LikeReaction = "👍"
+
+var ErrUnknownReaction = errors.New("unknown reaction")
+
+// SetReactions configures the set of reactions readers may use, in display
+// order. Duplicates are dropped.
+func (s *PostService) SetReactions(reactions []string) {
+	s.reactions = nil
+	seen := make(map[string]bool, len(reactions))
+	for _, r := range reactions {
+		if r != "" && !seen[r] {
+			seen[r] = true
+			s.reactions = append(s.reactions, r)
+		}
+	}
+}
+
+// Reactions returns the configured reaction set.
+func (s *PostService) Reactions() []string {
+	return s.reactions
+}
+
+func (s *PostService) isReaction(reaction string) bool {
+	for _, r := range s.reactions {
+		if r == reaction {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleReaction adds or removes a reaction of the given wallet on the given
+// post and returns the post's updated reaction counts. A wallet may hold
+// several different reactions on the same post.
+// Returns ErrUnknownReaction if the reaction is not configured.
+func (s *PostService) ToggleReaction(postID, walletID int, reaction string) (*models.ToggleReactionResponse, error) {
+	if !s.isReaction(reaction) {
+		return nil, ErrUnknownReaction
+	}
+
+	var reacted bool
+	if reaction == LikeReaction {
+		liked, _, err := s.ToggleLike(postID, walletID)
+		if err != nil {
+			return nil, err
+		}
+		reacted = liked
+	} else {
+		var err error
+		reacted, err = s.toggleReaction(postID, walletID, reaction)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	counts, err := s.reactionCounts(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ToggleReactionResponse{
+		Reaction:  reaction,
+		Reacted:   reacted,
+		Reactions: counts,
+	}, nil
+}
+
+func (s *PostService) toggleReaction(postID, walletID int, reaction string) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRow(
+		"SELECT 1 FROM post_reactions WHERE post_id = ? AND wallet_id = ? AND reaction = ?",
+		postID, walletID, reaction,
+	).Scan(&exists)
+
+	reacted := err == sql.ErrNoRows
+	if err != nil && !reacted {
+		return false, err
+	}
+
+	if reacted {
+		_, err = tx.Exec(
+			"INSERT INTO post_reactions (post_id, wallet_id, reaction) VALUES (?, ?, ?)",
+			postID, walletID, reaction,
+		)
+		if err != nil {
+			return false, err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO post_reaction_counts (post_id, reaction, count) VALUES (?, ?, 1)
+			ON CONFLICT(post_id, reaction) DO UPDATE SET count = count + 1
+		`, postID, reaction)
+	} else {
+		_, err = tx.Exec(
+			"DELETE FROM post_reactions WHERE post_id = ? AND wallet_id = ? AND reaction = ?",
+			postID, walletID, reaction,
+		)
+		if err != nil {
+			return false, err
+		}
+		_, err = tx.Exec(
+			"UPDATE post_reaction_counts SET count = count - 1 WHERE post_id = ? AND reaction = ?",
+			postID, reaction,
+		)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return reacted, tx.Commit()
+}
+
+// reactionCounts returns the count of every configured reaction on a post,
+// including those nobody used yet.
+func (s *PostService) reactionCounts(postID int) ([]models.ReactionCount, error) {
+	rows, err := s.db.Query("SELECT reaction, count FROM post_reaction_counts WHERE post_id = ?", postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byReaction := map[string]int{}
+	for rows.Next() {
+		var reaction string
+		var count int
+		if err := rows.Scan(&reaction, &count); err != nil {
+			return nil, err
+		}
+		byReaction[reaction] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if s.isReaction(LikeReaction) {
+		var likes int
+		if err := s.db.QueryRow("SELECT like_count FROM posts WHERE id = ?", postID).Scan(&likes); err != nil {
+			return nil, err
+		}
+		byReaction[LikeReaction] = likes
+	}
+
+	counts := make([]models.ReactionCount, 0, len(s.reactions))
+	for _, r := range s.reactions {
+		counts = append(counts, models.ReactionCount{Reaction: r, Count: byReaction[r]})
+	}
+	return counts, nil
+}
+
+// walletReactions returns the configured reactions the wallet holds on a
+// post, in display order. liked tells whether the wallet liked the post.
+func (s *PostService) walletReactions(postID int, walletAddress string, liked bool) ([]string, error) {
+	held := map[string]bool{LikeReaction: liked}
+	if walletAddress != "" {
+		rows, err := s.db.Query(`
+			SELECT r.reaction FROM post_reactions r
+			JOIN wallets w ON w.id = r.wallet_id
+			WHERE r.post_id = ? AND LOWER(w.address) = LOWER(?)
+		`, postID, walletAddress)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var reaction string
+			if err := rows.Scan(&reaction); err != nil {
+				return nil, err
+			}
+			held[reaction] = true
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	mine := []string{}
+	for _, r := range s.reactions {
+		if held[r] {
+			mine = append(mine, r)
+		}
+	}
+	return mine, nil
+}
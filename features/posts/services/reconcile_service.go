@@ -0,0 +1,202 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNoIntegrityReport = errors.New("no integrity check has run yet")
+
+// maxIntegrityReports is the number of past reports kept in the database.
+const maxIntegrityReports = 100
+
+// counterCheck compares a denormalized counter with its source table.
+// query selects (post_id, path, key, stored, actual) for every mismatch and
+// fix recomputes the counter from the source table. after, when set, runs
+// after fix to update values derived from the counter.
+type counterCheck struct {
+	counter string
+	query   string
+	fix     []string
+	after   func(tx *sql.Tx) error
+}
+
+// counterChecks lists every denormalized counter; new counters must be
+// added here to be reconciled.
+var counterChecks = []counterCheck{
+	{
+		counter: "posts.like_count",
+		query: `
+			SELECT id, path_identifier, '', stored, actual FROM (
+				SELECT p.id, p.path_identifier, p.like_count AS stored,
+					(SELECT COUNT(*) FROM post_likes pl WHERE pl.post_id = p.id) AS actual
+				FROM posts p
+			) WHERE stored != actual
+			ORDER BY id
+		`,
+		fix: []string{`
+			UPDATE posts SET like_count = (SELECT COUNT(*) FROM post_likes WHERE post_id = posts.id)
+			WHERE like_count != (SELECT COUNT(*) FROM post_likes WHERE post_id = posts.id)
+		`},
+	},
+	{
+		counter: "post_reaction_counts.count",
+		query: `
+			SELECT p.id, p.path_identifier, k.reaction, k.stored, k.actual FROM (
+				SELECT post_id, reaction, SUM(stored) AS stored, SUM(actual) AS actual FROM (
+					SELECT post_id, reaction, count AS stored, 0 AS actual FROM post_reaction_counts
+					UNION ALL
+					SELECT post_id, reaction, 0, COUNT(*) FROM post_reactions GROUP BY post_id, reaction
+				) GROUP BY post_id, reaction
+			) k
+			JOIN posts p ON p.id = k.post_id
+			WHERE k.stored != k.actual
+			ORDER BY p.id, k.reaction
+		`,
+		fix: []string{
+			"DELETE FROM post_reaction_counts",
+			`INSERT INTO post_reaction_counts (post_id, reaction, count)
+				SELECT post_id, reaction, COUNT(*) FROM post_reactions GROUP BY post_id, reaction`,
+		},
+	},
+	commentVoteCheck("comments.upvotes", "upvotes", 1),
+	commentVoteCheck("comments.downvotes", "downvotes", -1),
+}
+
+// commentVoteCheck checks a comment vote counter. Discrepancies are keyed
+// by comment ID, and fixing them recomputes the comment scores.
+func commentVoteCheck(counter, column string, value int) counterCheck {
+	actual := fmt.Sprintf("(SELECT COUNT(*) FROM comment_votes v WHERE v.comment_id = comments.id AND v.value = %d)", value)
+	return counterCheck{
+		counter: counter,
+		query: `
+			SELECT p.id, p.path_identifier, CAST(k.id AS TEXT), k.stored, k.actual FROM (
+				SELECT id, post_id, ` + column + ` AS stored, ` + actual + ` AS actual FROM comments
+			) k
+			JOIN posts p ON p.id = k.post_id
+			WHERE k.stored != k.actual
+			ORDER BY k.id
+		`,
+		fix: []string{
+			"UPDATE comments SET " + column + " = " + actual + " WHERE " + column + " != " + actual,
+		},
+		after: rescoreComments,
+	}
+}
+
+// ReconcileService checks denormalized counters against the tables they
+// summarize and optionally corrects them.
+type ReconcileService struct {
+	db *sql.DB
+}
+
+func NewReconcileService(db *sql.DB) *ReconcileService {
+	return &ReconcileService{db: db}
+}
+
+// Run checks every counter and records the report. When fix is true the
+// discrepancies are corrected in the same transaction.
+func (s *ReconcileService) Run(fix bool) (*models.IntegrityReport, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	report := &models.IntegrityReport{
+		CheckedAt:     time.Now().UTC(),
+		Counters:      []string{},
+		Discrepancies: []models.CounterDiscrepancy{},
+	}
+
+	for _, check := range counterChecks {
+		report.Counters = append(report.Counters, check.counter)
+
+		found, err := findDiscrepancies(tx, check)
+		if err != nil {
+			return nil, err
+		}
+		report.Discrepancies = append(report.Discrepancies, found...)
+
+		if fix && len(found) > 0 {
+			for _, stmt := range check.fix {
+				if _, err := tx.Exec(stmt); err != nil {
+					return nil, err
+				}
+			}
+			if check.after != nil {
+				if err := check.after(tx); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	report.Fixed = fix && len(report.Discrepancies) > 0
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(
+		"INSERT INTO integrity_reports (checked_at, fixed, discrepancies, report) VALUES (?, ?, ?, ?)",
+		report.CheckedAt, report.Fixed, len(report.Discrepancies), string(data),
+	)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(
+		"DELETE FROM integrity_reports WHERE id NOT IN (SELECT id FROM integrity_reports ORDER BY id DESC LIMIT ?)",
+		maxIntegrityReports,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// Latest returns the most recent integrity report.
+// Returns ErrNoIntegrityReport if no check has run yet.
+func (s *ReconcileService) Latest() (*models.IntegrityReport, error) {
+	var data string
+	err := s.db.QueryRow("SELECT report FROM integrity_reports ORDER BY id DESC LIMIT 1").Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoIntegrityReport
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var report models.IntegrityReport
+	if err := json.Unmarshal([]byte(data), &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func findDiscrepancies(tx *sql.Tx, check counterCheck) ([]models.CounterDiscrepancy, error) {
+	rows, err := tx.Query(check.query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []models.CounterDiscrepancy
+	for rows.Next() {
+		d := models.CounterDiscrepancy{Counter: check.counter}
+		if err := rows.Scan(&d.PostID, &d.Path, &d.Key, &d.Stored, &d.Actual); err != nil {
+			return nil, err
+		}
+		found = append(found, d)
+	}
+
+	return found, rows.Err()
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"math"
+	"sort"
+)
+
+// maxRelatedPerPost is the number of related posts stored for each post.
+const maxRelatedPerPost = 10
+
+// RelatedService recommends posts from co-engagement: wallets that liked or
+// commented on a post also engaged with its related posts.
+type RelatedService struct {
+	postService *PostService
+	minSupport  int
+	perSeries   int
+}
+
+// NewRelatedService creates the service. Pairs engaged with by fewer than
+// minSupport common wallets are ignored, and at most perSeries related
+// posts are kept from any one series.
+func NewRelatedService(ps *PostService, minSupport, perSeries int) *RelatedService {
+	return &RelatedService{postService: ps, minSupport: max(minSupport, 1), perSeries: max(perSeries, 1)}
+}
+
+type relatedCandidate struct {
+	postID  int
+	score   float64
+	support int
+}
+
+// Recompute rebuilds the related posts of every post. Similarity is the
+// cosine of the sets of wallets engaged with each post.
+func (s *RelatedService) Recompute() error {
+	db := s.postService.db
+
+	engaged := `(SELECT post_id, wallet_id FROM post_likes UNION SELECT post_id, wallet_id FROM comments WHERE ` + countedComments + `)`
+
+	readers := map[int]int{}
+	rows, err := db.Query("SELECT post_id, COUNT(*) FROM " + engaged + " GROUP BY post_id")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var postID, n int
+		if err := rows.Scan(&postID, &n); err != nil {
+			rows.Close()
+			return err
+		}
+		readers[postID] = n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	series := map[int]int{}
+	rows, err = db.Query("SELECT id, series_id FROM posts WHERE series_id IS NOT NULL")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var postID, seriesID int
+		if err := rows.Scan(&postID, &seriesID); err != nil {
+			rows.Close()
+			return err
+		}
+		series[postID] = seriesID
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = db.Query(`
+		SELECT a.post_id, b.post_id, COUNT(*) FROM `+engaged+` a
+		JOIN `+engaged+` b ON b.wallet_id = a.wallet_id AND b.post_id != a.post_id
+		GROUP BY a.post_id, b.post_id
+		HAVING COUNT(*) >= ?
+	`, s.minSupport)
+	if err != nil {
+		return err
+	}
+	candidates := map[int][]relatedCandidate{}
+	for rows.Next() {
+		var postID, relatedID, support int
+		if err := rows.Scan(&postID, &relatedID, &support); err != nil {
+			rows.Close()
+			return err
+		}
+		score := float64(support) / math.Sqrt(float64(readers[postID]*readers[relatedID]))
+		candidates[postID] = append(candidates[postID], relatedCandidate{relatedID, score, support})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM post_related"); err != nil {
+		return err
+	}
+	for postID, list := range candidates {
+		for rank, c := range s.diversify(list, series) {
+			_, err := tx.Exec(
+				"INSERT INTO post_related (post_id, related_post_id, rank, score, support) VALUES (?, ?, ?, ?, ?)",
+				postID, c.postID, rank+1, math.Round(c.score*1000)/1000, c.support,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// diversify orders candidates by score and keeps at most perSeries from
+// each series, so that one long series does not fill every slot.
+func (s *RelatedService) diversify(list []relatedCandidate, series map[int]int) []relatedCandidate {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].score != list[j].score {
+			return list[i].score > list[j].score
+		}
+		return list[i].postID < list[j].postID
+	})
+
+	perSeries := map[int]int{}
+	var kept []relatedCandidate
+	for _, c := range list {
+		if len(kept) == maxRelatedPerPost {
+			break
+		}
+		if seriesID, ok := series[c.postID]; ok {
+			if perSeries[seriesID] == s.perSeries {
+				continue
+			}
+			perSeries[seriesID]++
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
+
+// Related returns up to limit related posts of the given post, best first.
+func (s *RelatedService) Related(postID, limit int) ([]models.RelatedPost, error) {
+	rows, err := s.postService.db.Query(`
+		SELECT p.id, p.path_identifier, p.like_count, COALESCE(se.slug, ''), r.score, r.support
+		FROM post_related r
+		JOIN posts p ON p.id = r.related_post_id
+		LEFT JOIN series se ON se.id = p.series_id
+		WHERE r.post_id = ?
+		ORDER BY r.rank
+		LIMIT ?
+	`, postID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	related := []models.RelatedPost{}
+	for rows.Next() {
+		var id int
+		var r models.RelatedPost
+		if err := rows.Scan(&id, &r.Path, &r.LikeCount, &r.Series, &r.Score, &r.Support); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+		related = append(related, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	metadata, err := s.postService.loadMetadata(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range ids {
+		related[i].PostMetadata = *metadata[id]
+	}
+
+	return related, nil
+}
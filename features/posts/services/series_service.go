@@ -0,0 +1,363 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrSeriesNotFound = errors.New("series not found")
+
+type SeriesService struct {
+	db      *sql.DB
+	locales map[string]bool
+}
+
+func NewSeriesService(db *sql.DB) *SeriesService {
+	return &SeriesService{db: db, locales: map[string]bool{}}
+}
+
+// SetSupportedLocales configures the locale prefixes of post paths, which
+// are not series slugs.
+func (s *SeriesService) SetSupportedLocales(locales []string) {
+	s.locales = make(map[string]bool, len(locales))
+	for _, l := range locales {
+		s.locales[strings.ToLower(l)] = true
+	}
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// SeriesSlugFromPath returns the series slug encoded in a post path, i.e.
+// everything before the first slash. Returns "" for paths without a prefix.
+func SeriesSlugFromPath(path string) string {
+	slug, _, found := strings.Cut(path, "/")
+	if !found {
+		return ""
+	}
+	return slug
+}
+
+// titleFromSlug derives a default display title, e.g. "blockchain-101" → "Blockchain 101".
+func titleFromSlug(slug string) string {
+	words := strings.Fields(strings.ReplaceAll(slug, "-", " "))
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToUpper(r)) + w[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// ensureSeries returns the ID of the series with the given slug, creating it
+// with a title derived from the slug if it doesn't exist yet.
+func ensureSeries(db execer, slug string) (int, error) {
+	_, err := db.Exec(
+		"INSERT OR IGNORE INTO series (slug, title) VALUES (?, ?)",
+		slug, titleFromSlug(slug),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = db.QueryRow("SELECT id FROM series WHERE slug = ?", slug).Scan(&id)
+	return id, err
+}
+
+// assignSeriesFromPath links a post to the series named by its path prefix.
+// Posts without a prefix are left untouched.
+func assignSeriesFromPath(db execer, postID int, path string) error {
+	slug := SeriesSlugFromPath(path)
+	if slug == "" {
+		return nil
+	}
+
+	seriesID, err := ensureSeries(db, slug)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("UPDATE posts SET series_id = ? WHERE id = ?", seriesID, postID)
+	return err
+}
+
+// AssignFromPaths links every post that has no series yet to the series
+// named by its path prefix, creating series as needed. Localized paths are
+// left alone: they are folded into their canonical post at startup.
+// Returns the number of posts that were assigned.
+func (s *SeriesService) AssignFromPaths() (int, error) {
+	rows, err := s.db.Query("SELECT id, path_identifier FROM posts WHERE series_id IS NULL")
+	if err != nil {
+		return 0, err
+	}
+
+	type pending struct {
+		id   int
+		path string
+	}
+	var posts []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.path); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if slug := SeriesSlugFromPath(p.path); slug != "" && !s.locales[strings.ToLower(slug)] {
+			posts = append(posts, p)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, p := range posts {
+		if err := assignSeriesFromPath(tx, p.id, p.path); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(posts), nil
+}
+
+// GetBySlug finds a series by slug.
+// Returns ErrSeriesNotFound if the series doesn't exist.
+func (s *SeriesService) GetBySlug(slug string) (*models.Series, error) {
+	return s.scanSeries(s.db.QueryRow(
+		"SELECT id, slug, title, description, created_at, updated_at FROM series WHERE slug = ?",
+		slug,
+	))
+}
+
+func (s *SeriesService) getByID(id int) (*models.Series, error) {
+	return s.scanSeries(s.db.QueryRow(
+		"SELECT id, slug, title, description, created_at, updated_at FROM series WHERE id = ?",
+		id,
+	))
+}
+
+func (s *SeriesService) scanSeries(row *sql.Row) (*models.Series, error) {
+	var sr models.Series
+	err := row.Scan(&sr.ID, &sr.Slug, &sr.Title, &sr.Description, &sr.CreatedAt, &sr.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrSeriesNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sr, nil
+}
+
+// Update sets the title and description of a series, creating it if needed.
+func (s *SeriesService) Update(slug, title, description string) (*models.Series, error) {
+	if err := upsertSeries(s.db, slug, title, description); err != nil {
+		return nil, err
+	}
+	return s.GetBySlug(slug)
+}
+
+// SetPartOrder assigns explicit positions to the given posts, in order.
+// Every path must already belong to the series; parts not listed keep
+// their position.
+func (s *SeriesService) SetPartOrder(slug string, paths []string) error {
+	series, err := s.GetBySlug(slug)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setPartOrder(tx, series.ID, paths); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upsertSeries(db execer, slug, title, description string) error {
+	_, err := db.Exec(`
+		INSERT INTO series (slug, title, description) VALUES (?, ?, ?)
+		ON CONFLICT(slug) DO UPDATE SET
+			title = excluded.title,
+			description = excluded.description,
+			updated_at = CURRENT_TIMESTAMP
+	`, slug, title, description)
+	return err
+}
+
+func setPartOrder(db execer, seriesID int, paths []string) error {
+	for i, path := range paths {
+		result, err := db.Exec(
+			"UPDATE posts SET series_position = ? WHERE path_identifier = ? AND series_id = ?",
+			i+1, path, seriesID,
+		)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return ErrPostNotFound
+		}
+	}
+	return nil
+}
+
+// List returns all series with their number of posts, ordered by slug.
+func (s *SeriesService) List() (*models.SeriesListResponse, error) {
+	rows, err := s.db.Query(`
+		SELECT s.id, s.slug, s.title, s.description, s.created_at, s.updated_at, COUNT(p.id)
+		FROM series s
+		LEFT JOIN posts p ON p.series_id = s.id
+		GROUP BY s.id
+		ORDER BY s.slug ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	series := []models.SeriesSummary{}
+	for rows.Next() {
+		var sr models.SeriesSummary
+		if err := rows.Scan(&sr.ID, &sr.Slug, &sr.Title, &sr.Description, &sr.CreatedAt, &sr.UpdatedAt, &sr.PostCount); err != nil {
+			return nil, err
+		}
+		series = append(series, sr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &models.SeriesListResponse{Series: series}, nil
+}
+
+// GetDetail returns a series with its ordered parts and aggregate stats.
+func (s *SeriesService) GetDetail(slug string) (*models.SeriesResponse, error) {
+	series, err := s.GetBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := s.parts(series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.Stats(series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SeriesResponse{
+		Series: *series,
+		Parts:  parts,
+		Stats:  *stats,
+	}, nil
+}
+
+// parts returns the posts of a series in reading order. Posts with an
+// explicit position come first; the rest follow in creation order.
+func (s *SeriesService) parts(seriesID int) ([]models.SeriesPart, error) {
+	rows, err := s.db.Query(`
+		SELECT path_identifier, like_count
+		FROM posts
+		WHERE series_id = ?
+		ORDER BY series_position IS NULL, series_position, created_at, id
+	`, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	parts := []models.SeriesPart{}
+	for rows.Next() {
+		var p models.SeriesPart
+		if err := rows.Scan(&p.Path, &p.LikeCount); err != nil {
+			return nil, err
+		}
+		p.Position = len(parts) + 1
+		parts = append(parts, p)
+	}
+
+	return parts, rows.Err()
+}
+
+// Stats aggregates likes, comments and distinct engaged wallets for a series.
+func (s *SeriesService) Stats(seriesID int) (*models.SeriesStats, error) {
+	var stats models.SeriesStats
+	err := s.db.QueryRow(`
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(like_count), 0),
+			(SELECT COUNT(*) FROM comments c JOIN posts p ON p.id = c.post_id WHERE p.series_id = ?),
+			(SELECT COUNT(*) FROM (
+				SELECT pl.wallet_id FROM post_likes pl JOIN posts p ON p.id = pl.post_id WHERE p.series_id = ?
+				UNION
+				SELECT c.wallet_id FROM comments c JOIN posts p ON p.id = c.post_id WHERE p.series_id = ?
+			))
+		FROM posts
+		WHERE series_id = ?
+	`, seriesID, seriesID, seriesID, seriesID).Scan(&stats.PostCount, &stats.TotalLikes, &stats.TotalComments, &stats.Readers)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Navigation returns the previous and next parts around a post within its
+// series. Posts outside any series get an empty navigation.
+func (s *SeriesService) Navigation(post *models.Post) (*models.PostNavigationResponse, error) {
+	nav := &models.PostNavigationResponse{Path: post.PathIdentifier}
+	if post.SeriesID == nil {
+		return nav, nil
+	}
+
+	series, err := s.getByID(*post.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := s.parts(series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	nav.Series = series
+	nav.Total = len(parts)
+	for i := range parts {
+		if parts[i].Path != post.PathIdentifier {
+			continue
+		}
+		nav.Position = parts[i].Position
+		if i > 0 {
+			nav.Previous = &parts[i-1]
+		}
+		if i < len(parts)-1 {
+			nav.Next = &parts[i+1]
+		}
+		break
+	}
+
+	return nav, nil
+}
@@ -0,0 +1,523 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoManifestSource  = errors.New("no content manifest configured")
+	ErrInvalidSeriesPart = errors.New("series parts must be posts of their series")
+)
+
+// SyncService keeps the posts table in line with the frontend content
+// manifest.
+type SyncService struct {
+	db         *sql.DB
+	source     string
+	pathPrefix string
+}
+
+// NewSyncService creates a sync service. source and pathPrefix configure
+// SyncFromSource; see LoadManifest.
+func NewSyncService(db *sql.DB, source, pathPrefix string) *SyncService {
+	return &SyncService{db: db, source: source, pathPrefix: pathPrefix}
+}
+
+// SyncFromSource loads the configured manifest source and syncs it.
+// Returns ErrNoManifestSource if no source is configured.
+func (s *SyncService) SyncFromSource(dryRun bool) (*models.SyncReport, error) {
+	if s.source == "" {
+		return nil, ErrNoManifestSource
+	}
+
+	manifest, err := LoadManifest(s.source, s.pathPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Sync(manifest, dryRun)
+}
+
+// storedPost is the subset of a post compared against manifest entries.
+type storedPost struct {
+	id          int
+	seriesSlug  string
+	title       string
+	summary     string
+	locale      string
+	publishedAt *time.Time
+	updatedAt   *time.Time
+	readingTime int
+	tags        []string
+	authors     []string
+	locales     map[string]string // locale → title
+}
+
+// Sync upserts every manifest entry and reports added, changed and orphaned
+// posts, and entries listing a former path of a post as conflicts. With
+// dryRun set, the report is computed but nothing is written.
+// Entries sharing a path are translations of one post: the first provides
+// the post metadata and every entry with a locale registers a translation.
+func (s *SyncService) Sync(manifest *models.Manifest, dryRun bool) (*models.SyncReport, error) {
+	existing, err := s.loadStoredPosts()
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := s.loadAliases()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.SyncReport{
+		DryRun:       dryRun,
+		Added:        []string{},
+		Changed:      []models.SyncChange{},
+		Orphaned:     []string{},
+		Conflicts:    []models.SyncConflict{},
+		InvalidParts: []models.SyncSeriesPart{},
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	entries, translations := groupTranslations(manifest.Posts)
+	seen := map[string]bool{}
+	// series holds the series slug of every post once synced
+	series := map[string]string{}
+	for path, stored := range existing {
+		series[path] = stored.seriesSlug
+	}
+	for _, entry := range entries {
+		if current, ok := aliases[entry.Path]; ok {
+			report.Conflicts = append(report.Conflicts, models.SyncConflict{Path: entry.Path, Post: current})
+			continue
+		}
+		seen[entry.Path] = true
+		if slug := entrySeries(entry); slug != "" {
+			series[entry.Path] = slug
+		} else if _, ok := series[entry.Path]; !ok {
+			series[entry.Path] = ""
+		}
+		locales := translations[entry.Path]
+
+		stored, ok := existing[entry.Path]
+		if !ok {
+			report.Added = append(report.Added, entry.Path)
+			if !dryRun {
+				if err := insertManifestPost(tx, entry, locales); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		fields := changedFields(stored, entry, locales)
+		if len(fields) == 0 {
+			report.Unchanged++
+			continue
+		}
+
+		report.Changed = append(report.Changed, models.SyncChange{Path: entry.Path, Fields: fields})
+		if !dryRun {
+			if err := applyManifestEntry(tx, stored.id, entry, locales); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for path := range existing {
+		if !seen[path] {
+			report.Orphaned = append(report.Orphaned, path)
+		}
+	}
+	slices.Sort(report.Orphaned)
+
+	var invalid []string
+	for _, ms := range manifest.Series {
+		for _, part := range ms.Parts {
+			path := normalizePath(part)
+			if slug, ok := series[path]; !ok || slug != ms.Slug {
+				report.InvalidParts = append(report.InvalidParts, models.SyncSeriesPart{Series: ms.Slug, Path: path})
+				invalid = append(invalid, ms.Slug+": "+path)
+			}
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSeriesPart, strings.Join(invalid, ", "))
+	}
+
+	for _, ms := range manifest.Series {
+		if err := applySeries(tx, ms); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// groupTranslations returns the first entry of every path, in manifest
+// order, and the translations (locale → title) declared for each path.
+func groupTranslations(posts []models.ManifestEntry) ([]models.ManifestEntry, map[string]map[string]string) {
+	var entries []models.ManifestEntry
+	translations := map[string]map[string]string{}
+
+	for _, entry := range posts {
+		entry.Path = normalizePath(entry.Path)
+		if entry.Path == "" {
+			continue
+		}
+
+		locales, seen := translations[entry.Path]
+		if !seen {
+			locales = map[string]string{}
+			translations[entry.Path] = locales
+			entries = append(entries, entry)
+		}
+		if entry.Locale != "" {
+			if _, dup := locales[entry.Locale]; !dup || entry.Title != "" {
+				locales[entry.Locale] = entry.Title
+			}
+		}
+	}
+
+	return entries, translations
+}
+
+func applySeries(tx *sql.Tx, series models.ManifestSeries) error {
+	var seriesID int
+	var title, description string
+	err := tx.QueryRow(
+		"SELECT id, title, description FROM series WHERE slug = ?", series.Slug,
+	).Scan(&seriesID, &title, &description)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if series.Title != "" {
+		title = series.Title
+	}
+	if series.Description != "" {
+		description = series.Description
+	}
+	if title == "" {
+		title = titleFromSlug(series.Slug)
+	}
+
+	if err := upsertSeries(tx, series.Slug, title, description); err != nil {
+		return err
+	}
+
+	if len(series.Parts) == 0 {
+		return nil
+	}
+	if err := tx.QueryRow("SELECT id FROM series WHERE slug = ?", series.Slug).Scan(&seriesID); err != nil {
+		return err
+	}
+	parts := make([]string, len(series.Parts))
+	for i, p := range series.Parts {
+		parts[i] = normalizePath(p)
+	}
+	return setPartOrder(tx, seriesID, parts)
+}
+
+// loadAliases maps the former paths of renamed and merged posts to their
+// current path.
+func (s *SyncService) loadAliases() (map[string]string, error) {
+	rows, err := s.db.Query(`
+		SELECT a.path_identifier, p.path_identifier
+		FROM post_aliases a
+		JOIN posts p ON p.id = a.post_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := map[string]string{}
+	for rows.Next() {
+		var alias, path string
+		if err := rows.Scan(&alias, &path); err != nil {
+			return nil, err
+		}
+		aliases[alias] = path
+	}
+	return aliases, rows.Err()
+}
+
+func (s *SyncService) loadStoredPosts() (map[string]*storedPost, error) {
+	rows, err := s.db.Query(`
+		SELECT p.id, p.path_identifier, COALESCE(s.slug, ''), COALESCE(m.title, ''), COALESCE(m.summary, ''),
+			COALESCE(m.locale, ''), m.published_at, m.updated_at, COALESCE(m.reading_time, 0)
+		FROM posts p
+		LEFT JOIN series s ON s.id = p.series_id
+		LEFT JOIN post_metadata m ON m.post_id = p.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := map[string]*storedPost{}
+	byID := map[int]*storedPost{}
+	for rows.Next() {
+		var path string
+		p := &storedPost{}
+		if err := rows.Scan(&p.id, &path, &p.seriesSlug, &p.title, &p.summary, &p.locale, &p.publishedAt, &p.updatedAt, &p.readingTime); err != nil {
+			return nil, err
+		}
+		posts[path] = p
+		byID[p.id] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tagRows, err := s.db.Query("SELECT post_id, tag FROM post_tags ORDER BY tag")
+	if err != nil {
+		return nil, err
+	}
+	defer tagRows.Close()
+
+	for tagRows.Next() {
+		var postID int
+		var tag string
+		if err := tagRows.Scan(&postID, &tag); err != nil {
+			return nil, err
+		}
+		if p, ok := byID[postID]; ok {
+			p.tags = append(p.tags, tag)
+		}
+	}
+	if err := tagRows.Err(); err != nil {
+		return nil, err
+	}
+
+	authorRows, err := s.db.Query(`
+		SELECT pa.post_id, w.address
+		FROM post_authors pa
+		JOIN wallets w ON w.id = pa.wallet_id
+		ORDER BY pa.position
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer authorRows.Close()
+
+	for authorRows.Next() {
+		var postID int
+		var address string
+		if err := authorRows.Scan(&postID, &address); err != nil {
+			return nil, err
+		}
+		if p, ok := byID[postID]; ok {
+			p.authors = append(p.authors, address)
+		}
+	}
+	if err := authorRows.Err(); err != nil {
+		return nil, err
+	}
+
+	localeRows, err := s.db.Query("SELECT post_id, locale, title FROM post_locales")
+	if err != nil {
+		return nil, err
+	}
+	defer localeRows.Close()
+
+	for localeRows.Next() {
+		var postID int
+		var locale, title string
+		if err := localeRows.Scan(&postID, &locale, &title); err != nil {
+			return nil, err
+		}
+		if p, ok := byID[postID]; ok {
+			if p.locales == nil {
+				p.locales = map[string]string{}
+			}
+			p.locales[locale] = title
+		}
+	}
+
+	return posts, localeRows.Err()
+}
+
+// changedFields lists the manifest fields that differ from what is stored.
+// Fields the entry leaves empty are ignored.
+func changedFields(stored *storedPost, entry models.ManifestEntry, locales map[string]string) []string {
+	var fields []string
+
+	if series := entrySeries(entry); series != "" && series != stored.seriesSlug {
+		fields = append(fields, "series")
+	}
+	if entry.Title != "" && entry.Title != stored.title {
+		fields = append(fields, "title")
+	}
+	if entry.Summary != "" && entry.Summary != stored.summary {
+		fields = append(fields, "summary")
+	}
+	if entry.Locale != "" && entry.Locale != stored.locale {
+		fields = append(fields, "locale")
+	}
+	if !sameDate(entry.PublishedAt, stored.publishedAt) {
+		fields = append(fields, "published_at")
+	}
+	if !sameDate(entry.UpdatedAt, stored.updatedAt) {
+		fields = append(fields, "updated_at")
+	}
+	if entry.ReadingTime != 0 && entry.ReadingTime != stored.readingTime {
+		fields = append(fields, "reading_time")
+	}
+	if entry.Tags != nil && !slices.Equal(normalizeTags(entry.Tags), stored.tags) {
+		fields = append(fields, "tags")
+	}
+	if entry.Authors != nil && !slices.Equal(normalizeAuthors(entry.Authors), stored.authors) {
+		fields = append(fields, "authors")
+	}
+	for locale, title := range locales {
+		storedTitle, ok := stored.locales[locale]
+		if !ok || (title != "" && title != storedTitle) {
+			fields = append(fields, "locales")
+			break
+		}
+	}
+
+	return fields
+}
+
+// sameDate reports whether a manifest date matches the stored one. A nil
+// manifest date always matches since it leaves the stored value untouched.
+func sameDate(entry, stored *time.Time) bool {
+	if entry == nil {
+		return true
+	}
+	return stored != nil && entry.Equal(*stored)
+}
+
+// entrySeries returns the series slug of an entry, defaulting to the path prefix.
+func entrySeries(entry models.ManifestEntry) string {
+	if entry.Series != "" {
+		return entry.Series
+	}
+	return SeriesSlugFromPath(entry.Path)
+}
+
+// normalizeTags lowercases, de-duplicates and sorts tags.
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// normalizeAuthors lowercases author addresses, keeping their order.
+func normalizeAuthors(authors []string) []string {
+	normalized := make([]string, len(authors))
+	for i, a := range authors {
+		normalized[i] = strings.ToLower(strings.TrimSpace(a))
+	}
+	return normalized
+}
+
+func insertManifestPost(tx *sql.Tx, entry models.ManifestEntry, locales map[string]string) error {
+	result, err := tx.Exec("INSERT INTO posts (path_identifier) VALUES (?)", entry.Path)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	return applyManifestEntry(tx, int(id), entry, locales)
+}
+
+func applyManifestEntry(tx *sql.Tx, postID int, entry models.ManifestEntry, locales map[string]string) error {
+	if series := entrySeries(entry); series != "" {
+		seriesID, err := ensureSeries(tx, series)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE posts SET series_id = ? WHERE id = ?", seriesID, postID); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO post_metadata (post_id, title, summary, locale, published_at, updated_at, reading_time)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(post_id) DO UPDATE SET
+			title = CASE WHEN excluded.title != '' THEN excluded.title ELSE title END,
+			summary = CASE WHEN excluded.summary != '' THEN excluded.summary ELSE summary END,
+			locale = CASE WHEN excluded.locale != '' THEN excluded.locale ELSE locale END,
+			published_at = COALESCE(excluded.published_at, published_at),
+			updated_at = COALESCE(excluded.updated_at, updated_at),
+			reading_time = CASE WHEN excluded.reading_time != 0 THEN excluded.reading_time ELSE reading_time END
+	`, postID, entry.Title, entry.Summary, entry.Locale, entry.PublishedAt, entry.UpdatedAt, entry.ReadingTime)
+	if err != nil {
+		return err
+	}
+
+	for locale, title := range locales {
+		_, err := tx.Exec(`
+			INSERT INTO post_locales (post_id, locale, title) VALUES (?, ?, ?)
+			ON CONFLICT(post_id, locale) DO UPDATE SET
+				title = CASE WHEN excluded.title != '' THEN excluded.title ELSE title END
+		`, postID, locale, title)
+		if err != nil {
+			return err
+		}
+	}
+
+	if entry.Tags != nil {
+		if _, err := tx.Exec("DELETE FROM post_tags WHERE post_id = ?", postID); err != nil {
+			return err
+		}
+		for _, tag := range normalizeTags(entry.Tags) {
+			if _, err := tx.Exec("INSERT INTO post_tags (post_id, tag) VALUES (?, ?)", postID, tag); err != nil {
+				return err
+			}
+		}
+	}
+
+	if entry.Authors != nil {
+		if _, err := tx.Exec("DELETE FROM post_authors WHERE post_id = ?", postID); err != nil {
+			return err
+		}
+		for i, address := range normalizeAuthors(entry.Authors) {
+			// Authors may not have signed in yet; register their wallet upfront
+			if _, err := tx.Exec("INSERT OR IGNORE INTO wallets (address, system) VALUES (?, 'ethereum')", address); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`
+				INSERT OR IGNORE INTO post_authors (post_id, wallet_id, position)
+				SELECT ?, id, ? FROM wallets WHERE address = ?
+			`, postID, i, address)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"arkana/features/posts/models"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// trendingWindows are the windows trending can be computed over, by the
+// name clients pass as the window parameter.
+var trendingWindows = []struct {
+	name   string
+	window time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// Engagement weights of the trending score.
+const (
+	trendingLikeWeight    = 1.0
+	trendingCommentWeight = 2.0
+)
+
+var ErrInvalidWindow = errors.New("window must be one of: 24h, 7d, 30d")
+
+// ParseWindow parses a trending window name such as "7d".
+func ParseWindow(s string) (time.Duration, error) {
+	for _, w := range trendingWindows {
+		if w.name == s {
+			return w.window, nil
+		}
+	}
+	return 0, ErrInvalidWindow
+}
+
+// formatWindow returns the name of a trending window, or "" for windows
+// that are not supported.
+func formatWindow(window time.Duration) string {
+	for _, w := range trendingWindows {
+		if w.window == window {
+			return w.name
+		}
+	}
+	return ""
+}
+
+// trendingSnapshot is the cached ranking of one window.
+type trendingSnapshot struct {
+	computedAt time.Time
+	posts      []models.TrendingPost
+}
+
+// TrendingService ranks posts by likes and comments within a window, each
+// event weighing half as much every half-life. Rankings are cached per
+// window and recomputed once older than the refresh interval.
+type TrendingService struct {
+	postService *PostService
+	halfLife    time.Duration
+	refresh     time.Duration
+
+	// loads shares one recomputation of a window between concurrent callers
+	loads singleflight.Group
+
+	mu        sync.Mutex
+	snapshots map[time.Duration]*trendingSnapshot
+}
+
+// defaultHalfLife is used when no positive half-life is configured.
+const defaultHalfLife = 24 * time.Hour
+
+func NewTrendingService(ps *PostService, halfLife, refresh time.Duration) *TrendingService {
+	if halfLife <= 0 {
+		halfLife = defaultHalfLife
+	}
+	return &TrendingService{
+		postService: ps,
+		halfLife:    halfLife,
+		refresh:     refresh,
+		snapshots:   map[time.Duration]*trendingSnapshot{},
+	}
+}
+
+// Trending returns the top posts of the window whose path starts with
+// prefix, computing the ranking if the cached one is missing or stale.
+// Returns ErrInvalidWindow for windows other than trendingWindows.
+func (s *TrendingService) Trending(window time.Duration, prefix string, limit int) (*models.TrendingResponse, error) {
+	if formatWindow(window) == "" {
+		return nil, ErrInvalidWindow
+	}
+
+	s.mu.Lock()
+	snapshot := s.snapshots[window]
+	s.mu.Unlock()
+
+	if snapshot == nil || time.Since(snapshot.computedAt) > s.refresh {
+		var err error
+		if snapshot, err = s.recompute(window); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &models.TrendingResponse{
+		Window:     formatWindow(window),
+		ComputedAt: snapshot.computedAt,
+		Posts:      []models.TrendingPost{},
+	}
+	for _, p := range snapshot.posts {
+		if len(resp.Posts) == limit {
+			break
+		}
+		if strings.HasPrefix(p.Path, prefix) {
+			resp.Posts = append(resp.Posts, p)
+		}
+	}
+	return resp, nil
+}
+
+// Refresh recomputes every cached window. It is meant to run periodically
+// so that requests are served from a warm cache.
+func (s *TrendingService) Refresh() error {
+	s.mu.Lock()
+	windows := make([]time.Duration, 0, len(s.snapshots))
+	for w := range s.snapshots {
+		windows = append(windows, w)
+	}
+	s.mu.Unlock()
+
+	for _, w := range windows {
+		if _, err := s.recompute(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recompute ranks the posts of a window and caches the result. Concurrent
+// calls for the same window share one computation.
+func (s *TrendingService) recompute(window time.Duration) (*trendingSnapshot, error) {
+	snapshot, err, _ := s.loads.Do(formatWindow(window), func() (any, error) {
+		return s.compute(window)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.(*trendingSnapshot), nil
+}
+
+func (s *TrendingService) compute(window time.Duration) (*trendingSnapshot, error) {
+	now := time.Now().UTC()
+	since := now.Add(-window)
+
+	rows, err := s.postService.db.Query(`
+		SELECT post_id, CAST(strftime('%s', created_at) AS INTEGER), 'like' FROM post_likes WHERE created_at >= ?1
+		UNION ALL
+		SELECT post_id, CAST(strftime('%s', created_at) AS INTEGER), 'comment' FROM comments WHERE created_at >= ?1 AND `+countedComments+`
+	`, since.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scored := map[int]*models.TrendingPost{}
+	for rows.Next() {
+		var postID int
+		var at int64
+		var kind string
+		if err := rows.Scan(&postID, &at, &kind); err != nil {
+			return nil, err
+		}
+
+		p := scored[postID]
+		if p == nil {
+			p = &models.TrendingPost{}
+			scored[postID] = p
+		}
+
+		weight := trendingLikeWeight
+		if kind == "comment" {
+			weight = trendingCommentWeight
+			p.RecentComments++
+		} else {
+			p.RecentLikes++
+		}
+		age := now.Sub(time.Unix(at, 0))
+		p.Score += weight * math.Pow(0.5, age.Hours()/s.halfLife.Hours())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(scored))
+	for id := range scored {
+		ids = append(ids, id)
+	}
+	if err := s.loadSummaries(ids, scored); err != nil {
+		return nil, err
+	}
+
+	snapshot := &trendingSnapshot{computedAt: now, posts: make([]models.TrendingPost, 0, len(scored))}
+	for _, p := range scored {
+		if p.Path == "" {
+			continue
+		}
+		p.Score = math.Round(p.Score*1000) / 1000
+		snapshot.posts = append(snapshot.posts, *p)
+	}
+	sort.Slice(snapshot.posts, func(i, j int) bool {
+		a, b := snapshot.posts[i], snapshot.posts[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Path < b.Path
+	})
+
+	s.mu.Lock()
+	s.snapshots[window] = snapshot
+	s.mu.Unlock()
+
+	return snapshot, nil
+}
+
+// loadSummaries fills the listing fields of the scored posts.
+func (s *TrendingService) loadSummaries(ids []int, scored map[int]*models.TrendingPost) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := s.postService.db.Query(`
+		SELECT p.id, p.path_identifier, p.like_count, COALESCE(s.slug, '')
+		FROM posts p
+		LEFT JOIN series s ON s.id = p.series_id
+		WHERE p.id IN (`+placeholders+`)
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var summary models.PostSummary
+		if err := rows.Scan(&id, &summary.Path, &summary.LikeCount, &summary.Series); err != nil {
+			return err
+		}
+		scored[id].PostSummary = summary
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	metadata, err := s.postService.loadMetadata(ids)
+	if err != nil {
+		return err
+	}
+	for id, p := range scored {
+		p.PostMetadata = *metadata[id]
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// botUserAgent matches user agents of crawlers, link previewers and HTTP
+// libraries, whose requests are not counted as views.
+var botUserAgent = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|archiver|preview|fetch|scan|monitor|headless|lighthouse|curl|wget|python|go-http-client|java/|okhttp|axios|node-fetch`)
+
+// IsBot reports whether a user agent looks automated. Empty user agents are
+// treated as bots.
+func IsBot(userAgent string) bool {
+	return userAgent == "" || botUserAgent.MatchString(userAgent)
+}
+
+// ViewService counts unique daily views per post. Visitors are identified
+// by a hash of their IP and user agent salted with a random value that
+// changes every day; neither the raw identifiers nor past salts are kept,
+// so hashes cannot be linked across days or reversed.
+type ViewService struct {
+	db *sql.DB
+
+	mu      sync.Mutex
+	saltDay string
+	salt    []byte
+}
+
+func NewViewService(db *sql.DB) *ViewService {
+	return &ViewService{db: db}
+}
+
+// Record counts a view of the post by the visitor with the given IP and
+// user agent, once per visitor and day. It reports whether the view was
+// counted; views by bots and repeated views are not.
+func (s *ViewService) Record(postID int, ip, userAgent string) (bool, error) {
+	if IsBot(userAgent) {
+		return false, nil
+	}
+
+	day, salt, err := s.currentSalt()
+	if err != nil {
+		return false, err
+	}
+
+	hash := sha256.New()
+	hash.Write(salt)
+	hash.Write([]byte(strconv.Itoa(postID) + "\x00" + ip + "\x00" + userAgent))
+	visitor := hex.EncodeToString(hash.Sum(nil))
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT OR IGNORE INTO post_view_visitors (post_id, day, visitor_hash) VALUES (?, ?, ?)",
+		postID, day, visitor,
+	)
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO post_daily_views (post_id, day, views) VALUES (?, ?, 1)
+		ON CONFLICT(post_id, day) DO UPDATE SET views = views + 1
+	`, postID, day)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Rotate discards the salts and visitor hashes of previous days. It runs on
+// the first view of each day and periodically, so that no hash outlives its
+// day even without traffic.
+func (s *ViewService) Rotate() error {
+	_, _, err := s.currentSalt()
+	return err
+}
+
+// currentSalt returns today's salt, creating it and discarding older salts
+// and visitor hashes on the first call of the day.
+func (s *ViewService) currentSalt() (string, []byte, error) {
+	day := time.Now().UTC().Format(dateLayout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.saltDay == day {
+		return s.saltDay, s.salt, nil
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return "", nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", nil, err
+	}
+	defer tx.Rollback()
+
+	// Another instance may have created today's salt already
+	if _, err := tx.Exec("INSERT OR IGNORE INTO view_salts (day, salt) VALUES (?, ?)", day, salt); err != nil {
+		return "", nil, err
+	}
+	if err := tx.QueryRow("SELECT salt FROM view_salts WHERE day = ?", day).Scan(&salt); err != nil {
+		return "", nil, err
+	}
+	if _, err := tx.Exec("DELETE FROM view_salts WHERE day != ?", day); err != nil {
+		return "", nil, err
+	}
+	if _, err := tx.Exec("DELETE FROM post_view_visitors WHERE day != ?", day); err != nil {
+		return "", nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", nil, err
+	}
+
+	s.saltDay, s.salt = day, salt
+	return day, salt, nil
+}
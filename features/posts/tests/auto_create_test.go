@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"arkana/features/posts/services"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetOrAutoCreate(t *testing.T) {
+	db := setupTestDB(t)
+	svc := services.NewPostService(db)
+	svc.SetSupportedLocales([]string{"en", "es"})
+
+	t.Run("does not create posts without a policy", func(t *testing.T) {
+		if _, err := svc.GetOrAutoCreate("wtf-is/risc-v"); err != services.ErrPostNotFound {
+			t.Errorf("err = %v, want ErrPostNotFound", err)
+		}
+	})
+
+	policy, err := services.NewAutoCreatePolicy([]string{"wtf-is/*"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.SetAutoCreatePolicy(policy)
+
+	t.Run("creates paths matching a pattern", func(t *testing.T) {
+		post, err := svc.GetOrAutoCreate("es/wtf-is/risc-v")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.PathIdentifier != "wtf-is/risc-v" {
+			t.Errorf("path = %q, want %q", post.PathIdentifier, "wtf-is/risc-v")
+		}
+	})
+
+	t.Run("rejects paths outside the patterns", func(t *testing.T) {
+		for _, path := range []string{"spam/whatever", "wtf-is/a/b", "wtf-is/..", "wtf-is/./x"} {
+			if _, err := svc.GetOrAutoCreate(path); err != services.ErrPostNotFound {
+				t.Errorf("%s: err = %v, want ErrPostNotFound", path, err)
+			}
+		}
+	})
+
+	t.Run("rejects invalid patterns", func(t *testing.T) {
+		if _, err := services.NewAutoCreatePolicy([]string{"["}, nil); err == nil {
+			t.Error("err = nil, want an invalid pattern error")
+		}
+	})
+}
+
+func TestAutoCreateFromManifest(t *testing.T) {
+	db := setupTestDB(t)
+	svc := services.NewPostService(db)
+	dir := t.TempDir()
+	source := filepath.Join(dir, "manifest.json")
+	writeFile(t, source, `[{"path": "the-zk-chronicles/sum-check"}]`)
+
+	policy, err := services.NewAutoCreatePolicy(nil, services.NewManifestIndex(source, "", time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.SetAutoCreatePolicy(policy)
+
+	if _, err := svc.GetOrAutoCreate("the-zk-chronicles/sum-check"); err != nil {
+		t.Errorf("listed path: err = %v, want nil", err)
+	}
+	if _, err := svc.GetOrAutoCreate("the-zk-chronicles/unpublished"); err != services.ErrPostNotFound {
+		t.Errorf("unlisted path: err = %v, want ErrPostNotFound", err)
+	}
+
+	missing, _ := services.NewAutoCreatePolicy(nil, services.NewManifestIndex(filepath.Join(dir, "missing.json"), "", time.Hour))
+	svc.SetAutoCreatePolicy(missing)
+	if _, err := svc.GetOrAutoCreate("the-zk-chronicles/sum-check-2"); err != services.ErrPostNotFound {
+		t.Errorf("unloadable manifest: err = %v, want ErrPostNotFound", err)
+	}
+}
+
+func TestManifestIndexBacksOff(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "manifest.json")
+	index := services.NewManifestIndex(source, "", time.Hour)
+
+	if _, err := index.Contains("the-zk-chronicles/sum-check"); err == nil {
+		t.Fatal("missing manifest: err = nil, want load error")
+	}
+
+	// The manifest appears, but the failed load is not retried right away
+	writeFile(t, source, `[{"path": "the-zk-chronicles/sum-check"}]`)
+	ok, err := index.Contains("the-zk-chronicles/sum-check")
+	if err == nil || ok {
+		t.Errorf("during back-off: ok = %v, err = %v, want the previous load error", ok, err)
+	}
+}
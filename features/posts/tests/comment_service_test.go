@@ -1,8 +1,13 @@
 package tests
 
 import (
+	"arkana/features/posts/models"
 	"arkana/features/posts/services"
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestCreateComment(t *testing.T) {
@@ -13,7 +18,7 @@ func TestCreateComment(t *testing.T) {
 	post, _ := postSvc.GetOrCreateByPath("test-post")
 
 	t.Run("creates a top-level comment", func(t *testing.T) {
-		comment, err := commentSvc.Create(post.ID, walletID, "hello world", nil)
+		comment, err := commentSvc.Create(post.ID, walletID, "hello world", nil, "")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -32,9 +37,9 @@ func TestCreateComment(t *testing.T) {
 	})
 
 	t.Run("creates a reply", func(t *testing.T) {
-		parent, _ := commentSvc.Create(post.ID, walletID, "parent", nil)
+		parent, _ := commentSvc.Create(post.ID, walletID, "parent", nil, "")
 
-		reply, err := commentSvc.Create(post.ID, walletID, "reply", &parent.ID)
+		reply, err := commentSvc.Create(post.ID, walletID, "reply", &parent.ID, "")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -45,7 +50,7 @@ func TestCreateComment(t *testing.T) {
 
 	t.Run("rejects reply to nonexistent parent", func(t *testing.T) {
 		badID := 9999
-		_, err := commentSvc.Create(post.ID, walletID, "orphan reply", &badID)
+		_, err := commentSvc.Create(post.ID, walletID, "orphan reply", &badID, "")
 		if err == nil {
 			t.Error("expected error for nonexistent parent")
 		}
@@ -53,11 +58,464 @@ func TestCreateComment(t *testing.T) {
 
 	t.Run("rejects reply to comment on different post", func(t *testing.T) {
 		otherPost, _ := postSvc.GetOrCreateByPath("other-post")
-		otherComment, _ := commentSvc.Create(otherPost.ID, walletID, "other", nil)
+		otherComment, _ := commentSvc.Create(otherPost.ID, walletID, "other", nil, "")
 
-		_, err := commentSvc.Create(post.ID, walletID, "cross-post reply", &otherComment.ID)
+		_, err := commentSvc.Create(post.ID, walletID, "cross-post reply", &otherComment.ID, "")
 		if err == nil {
 			t.Error("expected error for cross-post reply")
 		}
 	})
 }
+
+func TestCommentLocales(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	walletID := insertTestWallet(t, db, "0xabc")
+	post, _ := postSvc.GetOrCreateByPath("test-post")
+
+	commentSvc.Create(post.ID, walletID, "hello", nil, "en")
+	commentSvc.Create(post.ID, walletID, "hola", nil, "es")
+
+	t.Run("returns every locale without filter", func(t *testing.T) {
+		resp, err := commentSvc.GetByPostID(post.ID, "", services.CommentViewer{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Total != 2 {
+			t.Errorf("total = %d, want 2", resp.Total)
+		}
+	})
+
+	t.Run("filters by locale", func(t *testing.T) {
+		resp, err := commentSvc.GetByPostID(post.ID, "es", services.CommentViewer{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Total != 1 || resp.Comments[0].Body != "hola" || resp.Comments[0].Locale != "es" {
+			t.Errorf("comments = %+v, want only hola", resp.Comments)
+		}
+	})
+}
+
+func TestEditComment(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	commentSvc.SetEditWindow(15 * time.Minute)
+	author := insertTestWallet(t, db, "0xabc")
+	other := insertTestWallet(t, db, "0xdef")
+	post, _ := postSvc.GetOrCreateByPath("test-post")
+	comment, _ := commentSvc.Create(post.ID, author, "frist", nil, "")
+
+	t.Run("keeps the previous body as a revision", func(t *testing.T) {
+		edited, err := commentSvc.Edit(comment.ID, author, "first", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if edited.Body != "first" || edited.Revision != 2 || edited.EditedAt == nil {
+			t.Errorf("comment = %+v, want revision 2 with edited_at", edited)
+		}
+
+		history, err := commentSvc.History(comment.ID, services.CommentViewer{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history.Revisions) != 2 || history.Revisions[0].Body != "frist" || history.Revisions[1].Body != "first" {
+			t.Errorf("revisions = %+v", history.Revisions)
+		}
+	})
+
+	t.Run("rejects stale revisions", func(t *testing.T) {
+		if _, err := commentSvc.Edit(comment.ID, author, "first!", 1); !errors.Is(err, services.ErrRevisionConflict) {
+			t.Errorf("err = %v, want ErrRevisionConflict", err)
+		}
+	})
+
+	t.Run("only the author may edit", func(t *testing.T) {
+		if _, err := commentSvc.Edit(comment.ID, other, "mine now", 2); !errors.Is(err, services.ErrNotCommentAuthor) {
+			t.Errorf("err = %v, want ErrNotCommentAuthor", err)
+		}
+	})
+
+	t.Run("closes after the edit window", func(t *testing.T) {
+		db.Exec("UPDATE comments SET created_at = datetime('now', '-1 hour') WHERE id = ?", comment.ID)
+		if _, err := commentSvc.Edit(comment.ID, author, "too late", 2); !errors.Is(err, services.ErrEditWindowClosed) {
+			t.Errorf("err = %v, want ErrEditWindowClosed", err)
+		}
+	})
+
+	t.Run("lists edits in post comments", func(t *testing.T) {
+		resp, _ := commentSvc.GetByPostID(post.ID, "", services.CommentViewer{})
+		if len(resp.Comments) != 1 || resp.Comments[0].Revision != 2 || resp.Comments[0].EditedAt == nil {
+			t.Errorf("comments = %+v", resp.Comments)
+		}
+	})
+
+	t.Run("returns ErrCommentNotFound", func(t *testing.T) {
+		if _, err := commentSvc.History(9999, services.CommentViewer{}); !errors.Is(err, services.ErrCommentNotFound) {
+			t.Errorf("err = %v, want ErrCommentNotFound", err)
+		}
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	commentSvc.SetEditWindow(15 * time.Minute)
+	author := insertTestWallet(t, db, "0xabc")
+	replier := insertTestWallet(t, db, "0xdef")
+	post, _ := postSvc.GetOrCreateByPath("test-post")
+
+	root, _ := commentSvc.Create(post.ID, author, "root", nil, "")
+	commentSvc.Edit(root.ID, author, "root, edited", 1)
+	reply, _ := commentSvc.Create(post.ID, replier, "reply", &root.ID, "")
+
+	t.Run("only the author may delete", func(t *testing.T) {
+		if _, err := commentSvc.Delete(root.ID, replier); !errors.Is(err, services.ErrNotCommentAuthor) {
+			t.Errorf("err = %v, want ErrNotCommentAuthor", err)
+		}
+	})
+
+	t.Run("leaves a tombstone for comments with replies", func(t *testing.T) {
+		purged, err := commentSvc.Delete(root.ID, author)
+		if err != nil || purged {
+			t.Fatalf("purged = %v, err = %v, want a tombstone", purged, err)
+		}
+
+		resp, _ := commentSvc.GetByPostID(post.ID, "", services.CommentViewer{})
+		if len(resp.Comments) != 2 {
+			t.Fatalf("comments = %+v, want the tombstone and its reply", resp.Comments)
+		}
+		c := resp.Comments[0]
+		if !c.Deleted || c.Body != services.DeletedCommentBody || c.AuthorAddress != "" {
+			t.Errorf("tombstone = %+v", c)
+		}
+
+		var revisions int
+		db.QueryRow("SELECT COUNT(*) FROM comment_revisions WHERE comment_id = ?", root.ID).Scan(&revisions)
+		if revisions != 0 {
+			t.Errorf("revisions = %d, want the history cleared", revisions)
+		}
+	})
+
+	t.Run("rejects edits, replies and repeated deletes of tombstones", func(t *testing.T) {
+		if _, err := commentSvc.Edit(root.ID, author, "back", 2); !errors.Is(err, services.ErrCommentDeleted) {
+			t.Errorf("edit: err = %v, want ErrCommentDeleted", err)
+		}
+		if _, err := commentSvc.Create(post.ID, replier, "hello?", &root.ID, ""); err == nil {
+			t.Error("reply to a tombstone succeeded")
+		}
+		if _, err := commentSvc.Delete(root.ID, author); !errors.Is(err, services.ErrCommentDeleted) {
+			t.Errorf("delete: err = %v, want ErrCommentDeleted", err)
+		}
+	})
+
+	t.Run("purges the last reply and its tombstone", func(t *testing.T) {
+		purged, err := commentSvc.Delete(reply.ID, replier)
+		if err != nil || !purged {
+			t.Fatalf("purged = %v, err = %v, want purged", purged, err)
+		}
+
+		resp, _ := commentSvc.GetByPostID(post.ID, "", services.CommentViewer{})
+		if len(resp.Comments) != 0 {
+			t.Errorf("comments = %+v, want none", resp.Comments)
+		}
+	})
+}
+
+func TestCommentTree(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	wallet := insertTestWallet(t, db, "0xabc")
+	post, _ := postSvc.GetOrCreateByPath("test-post")
+
+	// first -> a -> a1 -> a1x, first -> b, first -> c; second; third -> d
+	first, _ := commentSvc.Create(post.ID, wallet, "first", nil, "")
+	second, _ := commentSvc.Create(post.ID, wallet, "second", nil, "")
+	third, _ := commentSvc.Create(post.ID, wallet, "third", nil, "")
+	a, _ := commentSvc.Create(post.ID, wallet, "a", &first.ID, "")
+	b, _ := commentSvc.Create(post.ID, wallet, "b", &first.ID, "")
+	c, _ := commentSvc.Create(post.ID, wallet, "c", &first.ID, "")
+	commentSvc.Create(post.ID, wallet, "d", &third.ID, "")
+	a1, _ := commentSvc.Create(post.ID, wallet, "a1", &a.ID, "")
+	commentSvc.Create(post.ID, wallet, "a1x", &a1.ID, "")
+
+	ids := func(nodes []models.CommentNode) []int {
+		out := []int{}
+		for _, n := range nodes {
+			out = append(out, n.ID)
+		}
+		return out
+	}
+
+	t.Run("nests replies up to the depth", func(t *testing.T) {
+		tree, err := commentSvc.Tree(post.ID, services.CommentTreeOptions{Depth: 3, Limit: 10, Replies: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(tree.Comments); !reflect.DeepEqual(got, []int{first.ID, second.ID, third.ID}) {
+			t.Fatalf("threads = %v", got)
+		}
+		root := tree.Comments[0]
+		if root.ReplyCount != 3 || !reflect.DeepEqual(ids(root.Replies), []int{a.ID, b.ID, c.ID}) {
+			t.Fatalf("replies = %v, reply_count = %d", ids(root.Replies), root.ReplyCount)
+		}
+		leaf := root.Replies[0].Replies[0]
+		if leaf.ID != a1.ID || leaf.ReplyCount != 1 || len(leaf.Replies) != 0 {
+			t.Errorf("third level = %+v, want a1 with its reply counted but not embedded", leaf)
+		}
+		if tree.NextCursor != "" {
+			t.Errorf("next_cursor = %q, want none", tree.NextCursor)
+		}
+	})
+
+	t.Run("pages threads with a cursor", func(t *testing.T) {
+		opts := services.CommentTreeOptions{Depth: 1, Limit: 2}
+		page, _ := commentSvc.Tree(post.ID, opts)
+		if !reflect.DeepEqual(ids(page.Comments), []int{first.ID, second.ID}) || page.NextCursor == "" {
+			t.Fatalf("page = %v, cursor = %q", ids(page.Comments), page.NextCursor)
+		}
+
+		opts.Cursor = page.NextCursor
+		page, _ = commentSvc.Tree(post.ID, opts)
+		if !reflect.DeepEqual(ids(page.Comments), []int{third.ID}) || page.NextCursor != "" {
+			t.Errorf("page = %v, cursor = %q", ids(page.Comments), page.NextCursor)
+		}
+	})
+
+	t.Run("loads more replies from the replies cursor", func(t *testing.T) {
+		tree, _ := commentSvc.Tree(post.ID, services.CommentTreeOptions{Depth: 2, Limit: 1, Replies: 2})
+		root := tree.Comments[0]
+		if !reflect.DeepEqual(ids(root.Replies), []int{a.ID, b.ID}) || root.RepliesCursor == "" {
+			t.Fatalf("replies = %v, cursor = %q", ids(root.Replies), root.RepliesCursor)
+		}
+
+		more, err := commentSvc.Replies(first.ID, services.CommentTreeOptions{Depth: 1, Limit: 10, Cursor: root.RepliesCursor})
+		if err != nil || !reflect.DeepEqual(ids(more.Comments), []int{c.ID}) {
+			t.Errorf("more = %+v, err = %v", more, err)
+		}
+	})
+
+	t.Run("sorts newest and top", func(t *testing.T) {
+		newest, _ := commentSvc.Tree(post.ID, services.CommentTreeOptions{Sort: "newest", Depth: 2, Limit: 10, Replies: 5})
+		if got := ids(newest.Comments); !reflect.DeepEqual(got, []int{third.ID, second.ID, first.ID}) {
+			t.Errorf("newest = %v", got)
+		}
+		if got := ids(newest.Comments[2].Replies); !reflect.DeepEqual(got, []int{c.ID, b.ID, a.ID}) {
+			t.Errorf("newest replies = %v", got)
+		}
+
+		for i := 0; i < 2; i++ {
+			voter := insertTestWallet(t, db, fmt.Sprintf("0xvoter%d", i))
+			commentSvc.Vote(third.ID, services.CommentViewer{WalletID: voter}, 1)
+			commentSvc.Vote(second.ID, services.CommentViewer{WalletID: voter}, -1)
+		}
+		commentSvc.Vote(first.ID, services.CommentViewer{WalletID: insertTestWallet(t, db, "0xvoter2")}, 1)
+
+		opts := services.CommentTreeOptions{Sort: "top", Depth: 1, Limit: 2}
+		top, _ := commentSvc.Tree(post.ID, opts)
+		if got := ids(top.Comments); !reflect.DeepEqual(got, []int{third.ID, first.ID}) {
+			t.Errorf("top = %v", got)
+		}
+		opts.Cursor = top.NextCursor
+		top, _ = commentSvc.Tree(post.ID, opts)
+		if got := ids(top.Comments); !reflect.DeepEqual(got, []int{second.ID}) {
+			t.Errorf("top page 2 = %v", got)
+		}
+	})
+
+	t.Run("embeds the replies of every thread on a level", func(t *testing.T) {
+		tree, _ := commentSvc.Tree(post.ID, services.CommentTreeOptions{Depth: 4, Limit: 10, Replies: 5})
+		if got := ids(tree.Comments[2].Replies); len(got) != 1 {
+			t.Errorf("third replies = %v, want d", got)
+		}
+		if got := tree.Comments[0].Replies[0].Replies[0].Replies; len(got) != 1 || got[0].Body != "a1x" {
+			t.Errorf("fourth level = %+v, want a1x", got)
+		}
+	})
+
+	t.Run("counts replies in the requested locale", func(t *testing.T) {
+		other, _ := postSvc.GetOrCreateByPath("localized-post")
+		root, _ := commentSvc.Create(other.ID, wallet, "root", nil, "en")
+		commentSvc.Create(other.ID, wallet, "reply", &root.ID, "en")
+		commentSvc.Create(other.ID, wallet, "respuesta", &root.ID, "es")
+
+		tree, err := commentSvc.Tree(other.ID, services.CommentTreeOptions{Locale: "en", Depth: 2, Limit: 10, Replies: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := tree.Comments[0]; n.ReplyCount != 1 || len(n.Replies) != 1 {
+			t.Errorf("reply_count = %d, replies = %d, want 1 and 1", n.ReplyCount, len(n.Replies))
+		}
+	})
+
+	t.Run("rejects invalid options", func(t *testing.T) {
+		if _, err := commentSvc.Tree(post.ID, services.CommentTreeOptions{Sort: "random"}); !errors.Is(err, services.ErrInvalidCommentSort) {
+			t.Errorf("sort: err = %v", err)
+		}
+		if _, err := commentSvc.Tree(post.ID, services.CommentTreeOptions{Cursor: "!!"}); !errors.Is(err, services.ErrInvalidCursor) {
+			t.Errorf("cursor: err = %v", err)
+		}
+		if _, err := commentSvc.Replies(9999, services.CommentTreeOptions{}); !errors.Is(err, services.ErrCommentNotFound) {
+			t.Errorf("replies: err = %v", err)
+		}
+	})
+}
+
+func TestCommentContext(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	wallet := insertTestWallet(t, db, "0xabc")
+	post, _ := postSvc.GetOrCreateByPath("test-post")
+
+	root, _ := commentSvc.Create(post.ID, wallet, "root", nil, "")
+	parent, _ := commentSvc.Create(post.ID, wallet, "parent", &root.ID, "")
+	var siblings []int
+	for i := 0; i < 5; i++ {
+		c, _ := commentSvc.Create(post.ID, wallet, fmt.Sprintf("sibling %d", i), &parent.ID, "")
+		siblings = append(siblings, c.ID)
+	}
+	target := siblings[2]
+	reply, _ := commentSvc.Create(post.ID, wallet, "reply", &target, "")
+
+	ids := func(nodes []models.CommentNode) []int {
+		out := []int{}
+		for _, n := range nodes {
+			out = append(out, n.ID)
+		}
+		return out
+	}
+
+	t.Run("returns ancestors, siblings and replies", func(t *testing.T) {
+		ctx, err := commentSvc.Context(target, 1, services.CommentTreeOptions{Depth: 2, Replies: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ctx.PostPath != "test-post" || ctx.Comment.ID != target {
+			t.Errorf("post_path = %q, comment = %d", ctx.PostPath, ctx.Comment.ID)
+		}
+		if got := ids(ctx.Ancestors); !reflect.DeepEqual(got, []int{root.ID, parent.ID}) {
+			t.Errorf("ancestors = %v", got)
+		}
+		if got := ids(ctx.SiblingsBefore); !reflect.DeepEqual(got, []int{siblings[1]}) || !ctx.MoreBefore {
+			t.Errorf("before = %v, more = %v", got, ctx.MoreBefore)
+		}
+		if got := ids(ctx.SiblingsAfter); !reflect.DeepEqual(got, []int{siblings[3]}) || !ctx.MoreAfter {
+			t.Errorf("after = %v, more = %v", got, ctx.MoreAfter)
+		}
+		if got := ids(ctx.Comment.Replies); !reflect.DeepEqual(got, []int{reply.ID}) {
+			t.Errorf("replies = %v", got)
+		}
+	})
+
+	t.Run("follows the sort order", func(t *testing.T) {
+		ctx, _ := commentSvc.Context(target, 5, services.CommentTreeOptions{Sort: "newest"})
+		if got := ids(ctx.SiblingsBefore); !reflect.DeepEqual(got, []int{siblings[4], siblings[3]}) || ctx.MoreBefore {
+			t.Errorf("before = %v, more = %v", got, ctx.MoreBefore)
+		}
+		if got := ids(ctx.SiblingsAfter); !reflect.DeepEqual(got, []int{siblings[1], siblings[0]}) || ctx.MoreAfter {
+			t.Errorf("after = %v, more = %v", got, ctx.MoreAfter)
+		}
+	})
+
+	t.Run("top-level comments have top-level siblings", func(t *testing.T) {
+		other, _ := commentSvc.Create(post.ID, wallet, "other", nil, "")
+		ctx, _ := commentSvc.Context(root.ID, 2, services.CommentTreeOptions{})
+		if len(ctx.Ancestors) != 0 || !reflect.DeepEqual(ids(ctx.SiblingsAfter), []int{other.ID}) {
+			t.Errorf("ancestors = %v, after = %v", ids(ctx.Ancestors), ids(ctx.SiblingsAfter))
+		}
+	})
+
+	t.Run("returns ErrCommentNotFound", func(t *testing.T) {
+		if _, err := commentSvc.Context(9999, 2, services.CommentTreeOptions{}); !errors.Is(err, services.ErrCommentNotFound) {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
+
+func TestCommentVotes(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	author := insertTestWallet(t, db, "0xabc")
+	voter := insertTestWallet(t, db, "0xdef")
+	post, _ := postSvc.GetOrCreateByPath("test-post")
+	comment, _ := commentSvc.Create(post.ID, author, "hi", nil, "")
+
+	t.Run("rejects self and invalid votes", func(t *testing.T) {
+		if _, err := commentSvc.Vote(comment.ID, services.CommentViewer{WalletID: author}, 1); !errors.Is(err, services.ErrSelfVote) {
+			t.Errorf("self vote: err = %v", err)
+		}
+		if _, err := commentSvc.Vote(comment.ID, services.CommentViewer{WalletID: voter}, 2); !errors.Is(err, services.ErrInvalidVote) {
+			t.Errorf("vote 2: err = %v", err)
+		}
+	})
+
+	t.Run("changes and withdraws a vote", func(t *testing.T) {
+		vote, _ := commentSvc.Vote(comment.ID, services.CommentViewer{WalletID: voter}, 1)
+		if vote.Upvotes != 1 || vote.Downvotes != 0 || vote.Score != services.WilsonScore(1, 0) {
+			t.Errorf("upvote = %+v", vote)
+		}
+		vote, _ = commentSvc.Vote(comment.ID, services.CommentViewer{WalletID: voter}, -1)
+		if vote.Upvotes != 0 || vote.Downvotes != 1 || vote.Score != 0 {
+			t.Errorf("downvote = %+v", vote)
+		}
+		vote, _ = commentSvc.Vote(comment.ID, services.CommentViewer{WalletID: voter}, -1)
+		if vote.Downvotes != 1 {
+			t.Errorf("repeated downvote = %+v", vote)
+		}
+		vote, _ = commentSvc.Vote(comment.ID, services.CommentViewer{WalletID: voter}, 0)
+		if vote.Upvotes != 0 || vote.Downvotes != 0 || vote.Vote != 0 {
+			t.Errorf("withdrawn = %+v", vote)
+		}
+	})
+
+	t.Run("ranks by Wilson score and collapses downvoted comments", func(t *testing.T) {
+		// 1 up ranks below 8 up and 1 down; 6 down collapses
+		single, _ := commentSvc.Create(post.ID, author, "single", nil, "")
+		popular, _ := commentSvc.Create(post.ID, author, "popular", nil, "")
+		disliked, _ := commentSvc.Create(post.ID, author, "disliked", nil, "")
+		commentSvc.Vote(single.ID, services.CommentViewer{WalletID: voter}, 1)
+		for i := 0; i < 8; i++ {
+			v := insertTestWallet(t, db, fmt.Sprintf("0xup%d", i))
+			commentSvc.Vote(popular.ID, services.CommentViewer{WalletID: v}, 1)
+			if i < 6 {
+				commentSvc.Vote(disliked.ID, services.CommentViewer{WalletID: v}, -1)
+			}
+		}
+		commentSvc.Vote(popular.ID, services.CommentViewer{WalletID: voter}, -1)
+
+		resp, err := commentSvc.List(post.ID, "", "top", services.CommentViewer{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int
+		for _, c := range resp.Comments {
+			got = append(got, c.ID)
+		}
+		if want := []int{popular.ID, single.ID, comment.ID, disliked.ID}; !reflect.DeepEqual(got, want) {
+			t.Errorf("top = %v, want %v", got, want)
+		}
+		if !resp.Comments[3].Collapsed || resp.Comments[0].Collapsed {
+			t.Errorf("collapsed = %v, %v", resp.Comments[0].Collapsed, resp.Comments[3].Collapsed)
+		}
+
+		if _, err := commentSvc.List(post.ID, "", "random", services.CommentViewer{}); !errors.Is(err, services.ErrInvalidCommentSort) {
+			t.Errorf("invalid sort: err = %v", err)
+		}
+	})
+
+	t.Run("purging a comment removes its votes", func(t *testing.T) {
+		commentSvc.Vote(comment.ID, services.CommentViewer{WalletID: voter}, 1)
+		commentSvc.Delete(comment.ID, author)
+		var n int
+		db.QueryRow("SELECT COUNT(*) FROM comment_votes WHERE comment_id = ?", comment.ID).Scan(&n)
+		if n != 0 {
+			t.Errorf("votes = %d, want 0", n)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"arkana/features/posts/services"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func insertLikeEvent(t *testing.T, db *sql.DB, postID, walletID int, kind, at string) {
+	t.Helper()
+	_, err := db.Exec(
+		"INSERT INTO post_like_events (post_id, wallet_id, kind, created_at) VALUES (?, ?, ?, ?)",
+		postID, walletID, kind, at,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngagementRollups(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	svc := services.NewEngagementService(db)
+	w1 := insertTestWallet(t, db, "0xabc")
+	w2 := insertTestWallet(t, db, "0xdef")
+
+	p1, _ := postSvc.GetOrCreateByPath("wtf-is/risc-v")
+	p2, _ := postSvc.GetOrCreateByPath("wtf-is/the-internet")
+
+	// Monday 2026-10-05 and Wednesday 2026-10-07 fall in the same week
+	insertLikeEvent(t, db, p1.ID, w1, "like", "2026-10-05 09:00:00")
+	insertLikeEvent(t, db, p1.ID, w1, "unlike", "2026-10-05 10:00:00")
+	insertLikeEvent(t, db, p1.ID, w2, "like", "2026-10-07 12:00:00")
+	insertLikeEvent(t, db, p2.ID, w1, "like", "2026-10-07 13:00:00")
+	db.Exec("INSERT INTO comments (post_id, wallet_id, body, created_at) VALUES (?, ?, 'hi', '2026-10-07 14:00:00')", p1.ID, w2)
+
+	if err := svc.Aggregate(); err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 7, 0, 0, 0, 0, time.UTC)
+
+	t.Run("daily buckets include empty days", func(t *testing.T) {
+		stats, err := svc.PostStats(p1.ID, from, to, "day")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stats.Points) != 3 {
+			t.Fatalf("points = %d, want 3", len(stats.Points))
+		}
+		mon, tue, wed := stats.Points[0], stats.Points[1], stats.Points[2]
+		if mon.Likes != 1 || mon.Unlikes != 1 || mon.EngagedWallets != 1 {
+			t.Errorf("monday = %+v", mon)
+		}
+		if tue.Date != "2026-10-06" || tue.Likes != 0 {
+			t.Errorf("tuesday = %+v, want empty", tue)
+		}
+		if wed.Likes != 1 || wed.Comments != 1 || wed.EngagedWallets != 1 {
+			t.Errorf("wednesday = %+v", wed)
+		}
+	})
+
+	t.Run("weekly buckets count wallets once", func(t *testing.T) {
+		stats, err := svc.PostStats(p1.ID, from, to, "week")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stats.Points) != 1 {
+			t.Fatalf("points = %v, want one week", stats.Points)
+		}
+		week := stats.Points[0]
+		if week.Date != "2026-10-05" || week.Likes != 2 || week.EngagedWallets != 2 {
+			t.Errorf("week = %+v", week)
+		}
+	})
+
+	t.Run("series rollups span all parts", func(t *testing.T) {
+		stats, err := svc.SeriesStats(*p1.SeriesID, from, to, "month")
+		if err != nil {
+			t.Fatal(err)
+		}
+		month := stats.Points[0]
+		if month.Date != "2026-10-01" || month.Likes != 3 || month.EngagedWallets != 2 {
+			t.Errorf("month = %+v", month)
+		}
+	})
+
+	t.Run("refreshes the latest bucket", func(t *testing.T) {
+		insertLikeEvent(t, db, p1.ID, w1, "like", "2026-10-07 18:00:00")
+		if err := svc.Aggregate(); err != nil {
+			t.Fatal(err)
+		}
+		stats, _ := svc.PostStats(p1.ID, to, to, "day")
+		if stats.Points[0].Likes != 2 || stats.Points[0].EngagedWallets != 2 {
+			t.Errorf("wednesday = %+v, want the new like", stats.Points[0])
+		}
+	})
+
+	t.Run("rejects invalid queries", func(t *testing.T) {
+		if _, err := svc.PostStats(p1.ID, from, to, "hour"); err != services.ErrInvalidInterval {
+			t.Errorf("err = %v, want ErrInvalidInterval", err)
+		}
+		if _, err := svc.PostStats(p1.ID, to, from, "day"); err != services.ErrInvalidStatsRange {
+			t.Errorf("err = %v, want ErrInvalidStatsRange", err)
+		}
+	})
+}
+
+func TestLikesAreLogged(t *testing.T) {
+	db := setupTestDB(t)
+	svc := services.NewPostService(db)
+	walletID := insertTestWallet(t, db, "0xabc")
+	post, _ := svc.GetOrCreateByPath("test-post")
+
+	svc.ToggleLike(post.ID, walletID)
+	svc.ToggleLike(post.ID, walletID)
+	svc.SetLike(post.ID, walletID, false)
+	svc.SetLike(post.ID, walletID, true)
+
+	var likes, unlikes int
+	db.QueryRow("SELECT SUM(kind = 'like'), SUM(kind = 'unlike') FROM post_like_events").Scan(&likes, &unlikes)
+	if likes != 2 || unlikes != 1 {
+		t.Errorf("likes = %d, unlikes = %d, want 2, 1", likes, unlikes)
+	}
+}
+
+func TestMergeRebuildsRollups(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	svc := services.NewEngagementService(db)
+	w1 := insertTestWallet(t, db, "0xabc")
+	w2 := insertTestWallet(t, db, "0xdef")
+
+	target, _ := postSvc.GetOrCreateByPath("blog/new-slug")
+	source, _ := postSvc.GetOrCreateByPath("blog/old-slug")
+	insertLikeEvent(t, db, target.ID, w1, "like", "2026-10-05 09:00:00")
+	insertLikeEvent(t, db, source.ID, w2, "like", "2026-10-05 10:00:00")
+	insertLikeEvent(t, db, target.ID, w2, "like", "2026-10-07 12:00:00")
+	if err := svc.Aggregate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := postSvc.Merge("blog/old-slug", "blog/new-slug"); err != nil {
+		t.Fatal(err)
+	}
+	// Monday is older than the latest bucket, so Aggregate would keep it
+	if err := svc.Aggregate(); err != nil {
+		t.Fatal(err)
+	}
+
+	monday := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	stats, err := svc.PostStats(target.ID, monday, monday, "day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := stats.Points[0]; p.Likes != 2 || p.EngagedWallets != 2 {
+		t.Errorf("monday = %+v, want the merged like", p)
+	}
+}
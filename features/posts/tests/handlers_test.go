@@ -2,12 +2,15 @@ package tests
 
 import (
 	"arkana/features/posts/models"
+	"arkana/features/posts/services"
+	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestToggleLikeHandler(t *testing.T) {
@@ -329,6 +332,45 @@ func TestGetPostInfoHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("keeps hidden likes private to their wallet", func(t *testing.T) {
+		postID := insertTestPost(t, db, "private-post")
+		key, addr := generateTestKey(t)
+		walletID := insertTestWallet(t, db, addr)
+		db.Exec("UPDATE wallets SET hide_likes = 1 WHERE id = ?", walletID)
+		services.NewPostService(db).ToggleLike(postID, walletID)
+
+		info := func(authKey *ecdsa.PrivateKey) models.PostInfoResponse {
+			t.Helper()
+			req := httptest.NewRequest("GET", "/api/posts/private-post/info?wallet="+addr, nil)
+			if authKey != nil {
+				req.Header.Set("Authorization", "Bearer "+signJWS(t, authKey, map[string]any{"action": "GET_POST_INFO"}))
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+			}
+			var resp models.PostInfoResponse
+			json.NewDecoder(rec.Body).Decode(&resp)
+			return resp
+		}
+
+		if resp := info(nil); resp.Liked || len(resp.MyReactions) != 0 {
+			t.Errorf("anonymous: liked = %v, my_reactions = %v, want neither", resp.Liked, resp.MyReactions)
+		}
+		otherKey, otherAddr := generateTestKey(t)
+		insertTestWallet(t, db, otherAddr)
+		if resp := info(otherKey); resp.Liked || len(resp.MyReactions) != 0 {
+			t.Errorf("other wallet: liked = %v, my_reactions = %v, want neither", resp.Liked, resp.MyReactions)
+		}
+		if resp := info(key); !resp.Liked || len(resp.MyReactions) != 1 {
+			t.Errorf("own wallet: liked = %v, my_reactions = %v, want the like", resp.Liked, resp.MyReactions)
+		}
+		if resp := info(nil); resp.LikeCount != 1 {
+			t.Errorf("like_count = %d, want 1", resp.LikeCount)
+		}
+	})
+
 	t.Run("handles paths with slashes", func(t *testing.T) {
 		insertTestPost(t, db, "category/my-post")
 
@@ -348,3 +390,975 @@ func TestGetPostInfoHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestSeriesHandlers(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	ps := services.NewPostService(db)
+	ps.GetOrCreateByPath("wtf-is/quantum-computing")
+	ps.GetOrCreateByPath("wtf-is/risc-v")
+
+	t.Run("returns series detail", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/series/wtf-is", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+		}
+
+		var resp models.SeriesResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if resp.Slug != "wtf-is" {
+			t.Errorf("slug = %q, want %q", resp.Slug, "wtf-is")
+		}
+		if len(resp.Parts) != 2 {
+			t.Errorf("parts = %d, want 2", len(resp.Parts))
+		}
+	})
+
+	t.Run("returns 404 for unknown series", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/series/unknown", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404; body: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("returns navigation for a post", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts/wtf-is/quantum-computing/navigation", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+		}
+
+		var resp models.PostNavigationResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if resp.Series == nil || resp.Series.Slug != "wtf-is" {
+			t.Errorf("series = %v, want wtf-is", resp.Series)
+		}
+		if resp.Next == nil || resp.Next.Path != "wtf-is/risc-v" {
+			t.Errorf("next = %v, want wtf-is/risc-v", resp.Next)
+		}
+	})
+}
+
+func TestSyncPostsHandler(t *testing.T) {
+	db := setupTestDB(t)
+	adminKey, adminAddr := generateTestKey(t)
+	userKey, userAddr := generateTestKey(t)
+	insertTestWallet(t, db, adminAddr)
+	insertTestWallet(t, db, userAddr)
+	router := setupRouter(t, db, adminAddr)
+
+	manifest := map[string]any{"posts": []map[string]any{{"path": "wtf-is/risc-v", "title": "RISC-V"}}}
+
+	t.Run("rejects non-admin wallets", func(t *testing.T) {
+		jws := signJWS(t, userKey, map[string]any{"action": "SYNC_POSTS", "manifest": manifest})
+		req := httptest.NewRequest("POST", "/api/admin/posts/sync", strings.NewReader(jws))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want 403; body: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("rejects other actions", func(t *testing.T) {
+		jws := signJWS(t, adminKey, map[string]any{"action": "LIKE_POST", "manifest": manifest})
+		req := httptest.NewRequest("POST", "/api/admin/posts/sync", strings.NewReader(jws))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400; body: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("syncs an inline manifest", func(t *testing.T) {
+		jws := signJWS(t, adminKey, map[string]any{"action": "SYNC_POSTS", "manifest": manifest})
+		req := httptest.NewRequest("POST", "/api/admin/posts/sync", strings.NewReader(jws))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+		}
+
+		var report models.SyncReport
+		json.NewDecoder(rec.Body).Decode(&report)
+		if len(report.Added) != 1 || report.Added[0] != "wtf-is/risc-v" {
+			t.Errorf("added = %v, want [wtf-is/risc-v]", report.Added)
+		}
+	})
+
+	t.Run("rejects unknown series parts", func(t *testing.T) {
+		broken := map[string]any{
+			"posts":  manifest["posts"],
+			"series": []map[string]any{{"slug": "wtf-is", "parts": []string{"wtf-is/missing"}}},
+		}
+		jws := signJWS(t, adminKey, map[string]any{"action": "SYNC_POSTS", "manifest": broken})
+		req := httptest.NewRequest("POST", "/api/admin/posts/sync", strings.NewReader(jws))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400; body: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("requires a manifest source when none is embedded", func(t *testing.T) {
+		jws := signJWS(t, adminKey, map[string]any{"action": "SYNC_POSTS"})
+		req := httptest.NewRequest("POST", "/api/admin/posts/sync", strings.NewReader(jws))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400; body: %s", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+func TestListPostsHandler(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	seedMetadata(t, services.NewSyncService(db, "", ""))
+
+	t.Run("filters by tag", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts?tag=hardware", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+		}
+
+		var resp models.PostListResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if resp.Total != 1 || resp.Posts[0].Title != "RISC-V" {
+			t.Errorf("resp = %+v, want RISC-V only", resp)
+		}
+	})
+
+	t.Run("rejects invalid pagination", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts?limit=-1", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+}
+
+func TestLocalizedCommentsHandler(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	key, addr := generateTestKey(t)
+	insertTestWallet(t, db, addr)
+	insertTestPost(t, db, "wtf-is/risc-v")
+
+	for _, path := range []string{"en/wtf-is/risc-v", "es/wtf-is/risc-v"} {
+		jws := signJWS(t, key, map[string]any{"action": "CREATE_COMMENT", "body": "from " + path})
+		req := httptest.NewRequest("POST", "/api/posts/"+path+"/comments", strings.NewReader(jws))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want 201; body: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/posts/wtf-is/risc-v/comments?locale=es", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp models.CommentsResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Total != 1 || resp.Comments[0].Locale != "es" {
+		t.Errorf("comments = %+v, want one es comment", resp.Comments)
+	}
+}
+
+func TestRenamePostHandler(t *testing.T) {
+	db := setupTestDB(t)
+	adminKey, adminAddr := generateTestKey(t)
+	insertTestWallet(t, db, adminAddr)
+	insertTestPost(t, db, "blog/old-slug")
+	insertTestPost(t, db, "blog/taken")
+	router := setupRouter(t, db, adminAddr)
+
+	rename := func(to string) *httptest.ResponseRecorder {
+		jws := signJWS(t, adminKey, map[string]any{"action": "RENAME_POST", "from": "blog/old-slug", "to": to})
+		req := httptest.NewRequest("POST", "/api/admin/posts/rename", strings.NewReader(jws))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("rejects a taken path", func(t *testing.T) {
+		rec := rename("blog/taken")
+		if rec.Code != http.StatusConflict {
+			t.Errorf("status = %d, want 409; body: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("renames and redirects the old path", func(t *testing.T) {
+		rec := rename("blog/new-slug")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+		}
+
+		req := httptest.NewRequest("GET", "/api/posts/blog/old-slug/info", nil)
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var info models.PostInfoResponse
+		json.NewDecoder(rec.Body).Decode(&info)
+		if info.Path != "blog/old-slug" || info.CanonicalPath != "blog/new-slug" {
+			t.Errorf("path = %q, canonical_path = %q", info.Path, info.CanonicalPath)
+		}
+	})
+}
+
+func TestToggleReactionHandler(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	key, addr := generateTestKey(t)
+	insertTestWallet(t, db, addr)
+	insertTestPost(t, db, "test-post")
+
+	reactAs := func(action, reaction string) *httptest.ResponseRecorder {
+		jws := signJWS(t, key, map[string]any{"action": action, "reaction": reaction})
+		req := httptest.NewRequest("POST", "/api/posts/test-post/reactions", strings.NewReader(jws))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	react := func(reaction string) *httptest.ResponseRecorder { return reactAs("TOGGLE_REACTION", reaction) }
+
+	t.Run("toggles a configured reaction", func(t *testing.T) {
+		rec := react("🧠")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+		}
+		var resp models.ToggleReactionResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if !resp.Reacted || len(resp.Reactions) != 4 {
+			t.Errorf("resp = %+v", resp)
+		}
+	})
+
+	t.Run("rejects unknown reactions", func(t *testing.T) {
+		rec := react("💩")
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("rejects other actions", func(t *testing.T) {
+		if rec := reactAs("GET_BOOKMARKS", "🧠"); rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+}
+
+func TestSetLikeHandler(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	key, addr := generateTestKey(t)
+	walletID := insertTestWallet(t, db, addr)
+	insertTestPost(t, db, "test-post")
+
+	send := func(method string, payload map[string]any, idempotencyKey string) *httptest.ResponseRecorder {
+		jws := signJWS(t, key, payload)
+		req := httptest.NewRequest(method, "/api/posts/test-post/like", strings.NewReader(jws))
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) models.ToggleLikeResponse {
+		t.Helper()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+		}
+		var resp models.ToggleLikeResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return resp
+	}
+
+	t.Run("PUT is safe to repeat", func(t *testing.T) {
+		for range 2 {
+			resp := decode(send("PUT", map[string]any{"action": "SET_LIKE"}, ""))
+			if !resp.Liked || resp.LikeCount != 1 {
+				t.Errorf("resp = %+v, want liked with count 1", resp)
+			}
+		}
+	})
+
+	t.Run("DELETE is safe to repeat", func(t *testing.T) {
+		for range 2 {
+			resp := decode(send("DELETE", map[string]any{"action": "SET_LIKE"}, ""))
+			if resp.Liked || resp.LikeCount != 0 {
+				t.Errorf("resp = %+v, want unliked with count 0", resp)
+			}
+		}
+	})
+
+	t.Run("PUT and DELETE reject other actions", func(t *testing.T) {
+		for _, method := range []string{"PUT", "DELETE"} {
+			if rec := send(method, map[string]any{"action": "GET_BOOKMARKS"}, ""); rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: status = %d, want 400", method, rec.Code)
+			}
+		}
+	})
+
+	t.Run("POST honours the desired state", func(t *testing.T) {
+		for range 2 {
+			resp := decode(send("POST", map[string]any{"action": "LIKE_POST", "desired": true}, ""))
+			if !resp.Liked || resp.LikeCount != 1 {
+				t.Errorf("resp = %+v, want liked with count 1", resp)
+			}
+		}
+	})
+
+	t.Run("toggle retried with an idempotency key is replayed", func(t *testing.T) {
+		first := decode(send("POST", map[string]any{"action": "LIKE_POST"}, "retry-1"))
+		rec := send("POST", map[string]any{"action": "LIKE_POST"}, "retry-1")
+		if rec.Header().Get("Idempotent-Replayed") != "true" {
+			t.Error("Idempotent-Replayed header missing on retry")
+		}
+		retry := decode(rec)
+		if first != retry || first.Liked {
+			t.Errorf("first = %+v, retry = %+v, want the same unlike", first, retry)
+		}
+
+		var count int
+		db.QueryRow("SELECT like_count FROM posts WHERE path_identifier = 'test-post'").Scan(&count)
+		if count != 0 {
+			t.Errorf("like_count = %d, want 0", count)
+		}
+	})
+
+	t.Run("accepts the key in the payload", func(t *testing.T) {
+		first := decode(send("POST", map[string]any{"action": "LIKE_POST", "idempotency_key": "retry-2"}, ""))
+		retry := decode(send("POST", map[string]any{"action": "LIKE_POST", "idempotency_key": "retry-2"}, ""))
+		if first != retry || !first.Liked {
+			t.Errorf("first = %+v, retry = %+v, want the same like", first, retry)
+		}
+	})
+
+	t.Run("replays a retry signed again later", func(t *testing.T) {
+		first := decode(send("POST", map[string]any{"action": "LIKE_POST", "timestamp": time.Now().Add(-2 * time.Minute).Unix()}, "retry-4"))
+		rec := send("POST", map[string]any{"action": "LIKE_POST", "idempotency_key": "retry-4"}, "")
+		if rec.Header().Get("Idempotent-Replayed") != "true" || decode(rec) != first {
+			t.Errorf("status = %d, want the first response replayed", rec.Code)
+		}
+	})
+
+	t.Run("reclaims reservations left in progress", func(t *testing.T) {
+		// Keys reserved by requests that never completed
+		for _, key := range []string{"crashed", "running"} {
+			decode(send("PUT", map[string]any{"action": "SET_LIKE"}, key))
+		}
+		db.Exec("UPDATE idempotency_keys SET status_code = 0, created_at = ? WHERE wallet_id = ? AND key = 'crashed'",
+			time.Now().UTC().Add(-2*services.IdempotencyLease), walletID)
+		db.Exec("UPDATE idempotency_keys SET status_code = 0 WHERE wallet_id = ? AND key = 'running'", walletID)
+
+		rec := send("PUT", map[string]any{"action": "SET_LIKE"}, "crashed")
+		if rec.Code != http.StatusOK || rec.Header().Get("Idempotent-Replayed") != "" {
+			t.Errorf("expired lease: status = %d, want the request processed again", rec.Code)
+		}
+		if rec := send("PUT", map[string]any{"action": "SET_LIKE"}, "running"); rec.Code != http.StatusConflict {
+			t.Errorf("live lease: status = %d, want 409", rec.Code)
+		}
+	})
+
+	t.Run("rejects a key reused for another request", func(t *testing.T) {
+		rec := send("DELETE", map[string]any{"action": "SET_LIKE"}, "retry-2")
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("status = %d, want 422", rec.Code)
+		}
+	})
+
+	t.Run("rejects a key reused with another payload", func(t *testing.T) {
+		decode(send("POST", map[string]any{"action": "LIKE_POST", "desired": true}, "retry-3"))
+		rec := send("POST", map[string]any{"action": "LIKE_POST", "desired": false}, "retry-3")
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("status = %d, want 422", rec.Code)
+		}
+	})
+}
+
+func TestLikeHistoryHandlers(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	walletID := insertTestWallet(t, db, "0xabc")
+	postID := insertTestPost(t, db, "test-post")
+	services.NewPostService(db).ToggleLike(postID, walletID)
+
+	t.Run("lists who liked a post", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts/test-post/likes", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var resp models.PostLikesResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || resp.Total != 1 || resp.Likes[0].Address != "0xabc" {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("lists what a wallet liked", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/wallets/0xabc/likes", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var resp models.WalletLikesResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || resp.Total != 1 || resp.Likes[0].Path != "test-post" {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("returns 404 for unknown wallets", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/wallets/0xnobody/likes", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
+
+func TestIntegrityHandler(t *testing.T) {
+	db := setupTestDB(t)
+	adminKey, adminAddr := generateTestKey(t)
+	insertTestWallet(t, db, adminAddr)
+	router := setupRouter(t, db, adminAddr)
+
+	getAs := func(action string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/admin/integrity", nil)
+		req.Header.Set("Authorization", "Bearer "+signJWS(t, adminKey, map[string]any{"action": action}))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	get := func() *httptest.ResponseRecorder { return getAs("GET_INTEGRITY") }
+
+	if rec := getAs("RECONCILE"); rec.Code != http.StatusBadRequest {
+		t.Errorf("status with another action = %d, want 400", rec.Code)
+	}
+	if rec := get(); rec.Code != http.StatusNotFound {
+		t.Errorf("status before first run = %d, want 404", rec.Code)
+	}
+
+	jws := signJWS(t, adminKey, map[string]any{"action": "RECONCILE", "fix": true})
+	req := httptest.NewRequest("POST", "/api/admin/integrity", strings.NewReader(jws))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("run status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = get()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+	var report models.IntegrityReport
+	json.NewDecoder(rec.Body).Decode(&report)
+	if len(report.Counters) == 0 {
+		t.Errorf("counters = %v, want the checked counters", report.Counters)
+	}
+}
+
+func TestPostStatsHandler(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	insertTestPost(t, db, "test-post")
+
+	t.Run("returns a daily series", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts/test-post/stats?from=2026-10-01&to=2026-10-07", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var stats models.EngagementStats
+		json.NewDecoder(rec.Body).Decode(&stats)
+		if rec.Code != http.StatusOK || len(stats.Points) != 7 || stats.Interval != "day" {
+			t.Errorf("status = %d, stats = %+v", rec.Code, stats)
+		}
+	})
+
+	t.Run("rejects bad dates", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts/test-post/stats?from=yesterday", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+}
+
+func TestTrendingHandler(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	walletID := insertTestWallet(t, db, "0xabc")
+	postID := insertTestPost(t, db, "test-post")
+	services.NewPostService(db).ToggleLike(postID, walletID)
+
+	t.Run("lists trending posts", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts/trending?window=24h", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var resp models.TrendingResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || len(resp.Posts) != 1 || resp.Posts[0].Path != "test-post" {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("rejects invalid windows", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts/trending?window=forever", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+}
+
+func TestRelatedHandler(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	ps := services.NewPostService(db)
+	first := insertTestPost(t, db, "first-post")
+	second := insertTestPost(t, db, "second-post")
+	for _, address := range []string{"0xabc", "0xdef"} {
+		walletID := insertTestWallet(t, db, address)
+		ps.ToggleLike(first, walletID)
+		ps.ToggleLike(second, walletID)
+	}
+	if err := services.NewRelatedService(ps, 2, 2).Recompute(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("lists related posts", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts/first-post/related", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var resp models.RelatedResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || len(resp.Posts) != 1 || resp.Posts[0].Path != "second-post" {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("returns 404 for unknown posts", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/posts/missing/related", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
+
+func TestViewHandler(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	insertTestPost(t, db, "test-post")
+
+	view := func(path, ua string, header map[string]string) int {
+		req := httptest.NewRequest("POST", "/api/posts/"+path+"/view", nil)
+		req.Header.Set("User-Agent", ua)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("counts views", func(t *testing.T) {
+		if code := view("test-post", "Mozilla/5.0", nil); code != http.StatusNoContent {
+			t.Fatalf("status = %d, want 204", code)
+		}
+		view("test-post", "Mozilla/5.0", nil)
+		view("test-post", "Mozilla/5.0", map[string]string{"Sec-Purpose": "prefetch"})
+
+		info, _ := services.NewPostService(db).GetPostInfo("test-post", "", "")
+		if info.ViewCount != 1 {
+			t.Errorf("view_count = %d, want 1", info.ViewCount)
+		}
+	})
+
+	t.Run("returns 404 for unknown posts", func(t *testing.T) {
+		if code := view("missing", "Mozilla/5.0", nil); code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", code)
+		}
+	})
+}
+
+func TestEditCommentHandler(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	key, addr := generateTestKey(t)
+	walletID := insertTestWallet(t, db, addr)
+	postID := insertTestPost(t, db, "test-post")
+	comment, _ := services.NewCommentService(db).Create(postID, walletID, "helo", nil, "")
+	url := fmt.Sprintf("/api/comments/%d", comment.ID)
+
+	edit := func(payload map[string]any) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", url, strings.NewReader(signJWS(t, key, payload)))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("edits a comment", func(t *testing.T) {
+		rec := edit(map[string]any{"action": "EDIT_COMMENT", "body": "hello", "revision": 1})
+		var resp models.CommentResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || resp.Body != "hello" || resp.BodyHTML != "<p>hello</p>\n" || resp.Revision != 2 || resp.EditedAt == nil {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("returns 409 for stale revisions", func(t *testing.T) {
+		if rec := edit(map[string]any{"action": "EDIT_COMMENT", "body": "hi", "revision": 1}); rec.Code != http.StatusConflict {
+			t.Errorf("status = %d, want 409", rec.Code)
+		}
+	})
+
+	t.Run("requires the EDIT_COMMENT action", func(t *testing.T) {
+		if rec := edit(map[string]any{"action": "CREATE_COMMENT", "body": "hi", "revision": 2}); rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("serves the history", func(t *testing.T) {
+		req := httptest.NewRequest("GET", url+"/history", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var history models.CommentHistoryResponse
+		json.NewDecoder(rec.Body).Decode(&history)
+		if rec.Code != http.StatusOK || len(history.Revisions) != 2 || history.Revisions[0].Body != "helo" {
+			t.Errorf("status = %d, history = %+v", rec.Code, history)
+		}
+	})
+}
+
+func TestDeleteCommentHandler(t *testing.T) {
+	db := setupTestDB(t)
+	adminKey, adminAddr := generateTestKey(t)
+	router := setupRouter(t, db, adminAddr)
+	key, addr := generateTestKey(t)
+	walletID := insertTestWallet(t, db, addr)
+	insertTestWallet(t, db, adminAddr)
+	postID := insertTestPost(t, db, "test-post")
+	commentSvc := services.NewCommentService(db)
+	first, _ := commentSvc.Create(postID, walletID, "first", nil, "")
+	second, _ := commentSvc.Create(postID, walletID, "second", nil, "")
+
+	remove := func(url string, key *ecdsa.PrivateKey, reason ...string) *httptest.ResponseRecorder {
+		payload := map[string]any{"action": "DELETE_COMMENT"}
+		if len(reason) > 0 {
+			payload["reason"] = reason[0]
+		}
+		req := httptest.NewRequest("DELETE", url, strings.NewReader(signJWS(t, key, payload)))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("author deletes a comment", func(t *testing.T) {
+		rec := remove(fmt.Sprintf("/api/comments/%d", first.ID), key)
+		var resp models.DeleteCommentResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || !resp.Purged {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("others cannot delete it", func(t *testing.T) {
+		if rec := remove(fmt.Sprintf("/api/comments/%d", second.ID), adminKey); rec.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want 403", rec.Code)
+		}
+	})
+
+	t.Run("admins delete any comment", func(t *testing.T) {
+		url := fmt.Sprintf("/api/admin/comments/%d", second.ID)
+		if rec := remove(url, key, "spam"); rec.Code != http.StatusForbidden {
+			t.Errorf("non-admin: status = %d, want 403", rec.Code)
+		}
+		if rec := remove(url, adminKey); rec.Code != http.StatusBadRequest {
+			t.Errorf("no reason: status = %d, want 400", rec.Code)
+		}
+		if rec := remove(url, adminKey, "spam"); rec.Code != http.StatusOK {
+			t.Errorf("status = %d, body: %s", rec.Code, rec.Body.String())
+		}
+
+		var reason string
+		db.QueryRow("SELECT reason FROM comment_moderation_log WHERE comment_id = ? AND action = 'delete'", second.ID).Scan(&reason)
+		if reason != "spam" {
+			t.Errorf("logged reason = %q, want spam", reason)
+		}
+	})
+}
+
+func TestCommentTreeHandler(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	walletID := insertTestWallet(t, db, "0xabc")
+	postID := insertTestPost(t, db, "test-post")
+	commentSvc := services.NewCommentService(db)
+	root, _ := commentSvc.Create(postID, walletID, "root", nil, "")
+	commentSvc.Create(postID, walletID, "other", nil, "")
+	for i := 0; i < 3; i++ {
+		commentSvc.Create(postID, walletID, fmt.Sprintf("reply %d", i), &root.ID, "")
+	}
+
+	get := func(url string) (*httptest.ResponseRecorder, models.CommentTreeResponse) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		var resp models.CommentTreeResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	t.Run("returns nested threads", func(t *testing.T) {
+		rec, resp := get("/api/posts/test-post/comments?view=tree&limit=1&replies=2")
+		if rec.Code != http.StatusOK || len(resp.Comments) != 1 || resp.NextCursor == "" {
+			t.Fatalf("status = %d, body: %s", rec.Code, rec.Body.String())
+		}
+		node := resp.Comments[0]
+		if node.ReplyCount != 3 || len(node.Replies) != 2 || node.RepliesCursor == "" {
+			t.Errorf("root = %+v", node)
+		}
+
+		rec, more := get(fmt.Sprintf("/api/comments/%d/replies?cursor=%s", root.ID, node.RepliesCursor))
+		if rec.Code != http.StatusOK || len(more.Comments) != 1 || more.Comments[0].Body != "reply 2" {
+			t.Errorf("more replies: status = %d, body: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"sort=random", "cursor=!!", "depth=0", "limit=x"} {
+			if rec, _ := get("/api/posts/test-post/comments?view=tree&" + query); rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: status = %d, want 400", query, rec.Code)
+			}
+		}
+	})
+
+	t.Run("returns 404 for replies of unknown comments", func(t *testing.T) {
+		if rec, _ := get("/api/comments/9999/replies"); rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
+
+func TestGetCommentHandler(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	walletID := insertTestWallet(t, db, "0xabc")
+	postID := insertTestPost(t, db, "test-post")
+	commentSvc := services.NewCommentService(db)
+	root, _ := commentSvc.Create(postID, walletID, "root", nil, "")
+	reply, _ := commentSvc.Create(postID, walletID, "reply", &root.ID, "")
+
+	t.Run("returns the comment in context", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", fmt.Sprintf("/api/comments/%d", reply.ID), nil))
+		var resp models.CommentContextResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || resp.PostPath != "test-post" || len(resp.Ancestors) != 1 || resp.Ancestors[0].ID != root.ID {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("rejects invalid siblings", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", fmt.Sprintf("/api/comments/%d?siblings=-1", reply.ID), nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("returns 404 for unknown comments", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/comments/9999", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
+
+func TestVoteCommentHandler(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	key, addr := generateTestKey(t)
+	insertTestWallet(t, db, addr)
+	authorID := insertTestWallet(t, db, "0xabc")
+	postID := insertTestPost(t, db, "test-post")
+	comment, _ := services.NewCommentService(db).Create(postID, authorID, "hi", nil, "")
+	url := fmt.Sprintf("/api/comments/%d/vote", comment.ID)
+
+	vote := func(method string, payload map[string]any) *httptest.ResponseRecorder {
+		payload["action"] = "VOTE_COMMENT"
+		req := httptest.NewRequest(method, url, strings.NewReader(signJWS(t, key, payload)))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("sets and withdraws a vote", func(t *testing.T) {
+		rec := vote("PUT", map[string]any{"value": -1})
+		var resp models.CommentVoteResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || resp.Vote != -1 || resp.Downvotes != 1 {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+
+		rec = vote("DELETE", map[string]any{})
+		resp = models.CommentVoteResponse{}
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || resp.Vote != 0 || resp.Downvotes != 0 {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+	})
+
+	t.Run("rejects invalid values", func(t *testing.T) {
+		for _, payload := range []map[string]any{{"value": 2}, {}} {
+			if rec := vote("PUT", payload); rec.Code != http.StatusBadRequest {
+				t.Errorf("%v: status = %d, want 400", payload, rec.Code)
+			}
+		}
+	})
+
+	t.Run("sorts comments by votes", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/posts/test-post/comments?sort=top", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d", rec.Code)
+		}
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/posts/test-post/comments?sort=best", nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("invalid sort: status = %d, want 400", rec.Code)
+		}
+	})
+}
+
+func TestModerationHandlers(t *testing.T) {
+	db := setupTestDB(t)
+	modKey, modAddr := generateTestKey(t)
+	router := setupRouter(t, db, modAddr)
+	insertTestWallet(t, db, modAddr)
+	authorKey, authorAddr := generateTestKey(t)
+	authorID := insertTestWallet(t, db, authorAddr)
+	postID := insertTestPost(t, db, "test-post")
+	comment, _ := services.NewCommentService(db).Create(postID, authorID, "hi", nil, "")
+
+	send := func(method, url string, key *ecdsa.PrivateKey, payload map[string]any) *httptest.ResponseRecorder {
+		jws := signJWS(t, key, payload)
+		var req *http.Request
+		if method == "GET" {
+			req = httptest.NewRequest(method, url, nil)
+			req.Header.Set("Authorization", "Bearer "+jws)
+		} else {
+			req = httptest.NewRequest(method, url, strings.NewReader(jws))
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	reportURL := fmt.Sprintf("/api/comments/%d/report", comment.ID)
+	moderateURL := fmt.Sprintf("/api/admin/comments/%d/moderate", comment.ID)
+
+	t.Run("reports comments", func(t *testing.T) {
+		rec := send("POST", reportURL, modKey, map[string]any{"action": "REPORT_COMMENT", "reason": "spam"})
+		var resp models.CommentReportResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || resp.Reason != "spam" {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+
+		if rec := send("POST", reportURL, modKey, map[string]any{"action": "REPORT_COMMENT", "reason": "meh"}); rec.Code != http.StatusBadRequest {
+			t.Errorf("invalid reason: status = %d, want 400", rec.Code)
+		}
+		if rec := send("POST", reportURL, authorKey, map[string]any{"action": "REPORT_COMMENT", "reason": "spam"}); rec.Code != http.StatusForbidden {
+			t.Errorf("own comment: status = %d, want 403", rec.Code)
+		}
+	})
+
+	t.Run("serves the queue to moderators only", func(t *testing.T) {
+		if rec := send("GET", "/api/admin/comments/reports", authorKey, map[string]any{"action": "GET_MODERATION_QUEUE"}); rec.Code != http.StatusForbidden {
+			t.Errorf("author: status = %d, want 403", rec.Code)
+		}
+
+		if rec := send("GET", "/api/admin/comments/reports", modKey, map[string]any{"action": "GET_REPORTS"}); rec.Code != http.StatusBadRequest {
+			t.Errorf("other action: status = %d, want 400", rec.Code)
+		}
+
+		rec := send("GET", "/api/admin/comments/reports", modKey, map[string]any{"action": "GET_MODERATION_QUEUE"})
+		var queue models.ModerationQueueResponse
+		json.NewDecoder(rec.Body).Decode(&queue)
+		if rec.Code != http.StatusOK || queue.Total != 1 || queue.Comments[0].Reports != 1 {
+			t.Errorf("status = %d, queue = %+v", rec.Code, queue)
+		}
+	})
+
+	t.Run("hides comments", func(t *testing.T) {
+		rec := send("POST", moderateURL, modKey, map[string]any{"action": "MODERATE_COMMENT", "decision": "hide", "reason": "spam"})
+		var resp models.ModerationResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != http.StatusOK || resp.Status != "hidden" {
+			t.Errorf("status = %d, resp = %+v", rec.Code, resp)
+		}
+		if rec := send("POST", moderateURL, modKey, map[string]any{"action": "MODERATE_COMMENT", "decision": "hide"}); rec.Code != http.StatusBadRequest {
+			t.Errorf("missing reason: status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("shows hidden comments to their author only", func(t *testing.T) {
+		list := func(key *ecdsa.PrivateKey) models.CommentsResponse {
+			var rec *httptest.ResponseRecorder
+			if key == nil {
+				rec = httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/posts/test-post/comments", nil))
+			} else {
+				rec = send("GET", "/api/posts/test-post/comments", key, map[string]any{"action": "GET_COMMENTS"})
+			}
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", rec.Code, rec.Body.String())
+			}
+			var resp models.CommentsResponse
+			json.NewDecoder(rec.Body).Decode(&resp)
+			return resp
+		}
+
+		if resp := list(nil); resp.Total != 0 {
+			t.Errorf("anonymous: %+v, want none", resp)
+		}
+		if resp := list(authorKey); resp.Total != 1 || resp.Comments[0].Status != "hidden" {
+			t.Errorf("author: %+v, want the hidden comment", resp)
+		}
+		if resp := list(modKey); resp.Total != 1 {
+			t.Errorf("moderator: %+v, want the hidden comment", resp)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"arkana/features/posts/services"
+	walletsvc "arkana/features/wallet/services"
+	"testing"
+)
+
+func TestLikeHistory(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	walletSvc := walletsvc.NewWalletService(db)
+	alice := insertTestWallet(t, db, "0xa11ce")
+	bob := insertTestWallet(t, db, "0xb0b")
+	carol := insertTestWallet(t, db, "0xca401")
+
+	name := "Alice"
+	if _, err := walletSvc.UpdateProfile(alice, &name, nil); err != nil {
+		t.Fatal(err)
+	}
+	hide := true
+	if _, err := walletSvc.UpdateProfile(carol, nil, &hide); err != nil {
+		t.Fatal(err)
+	}
+
+	p1, _ := postSvc.GetOrCreateByPath("wtf-is/risc-v")
+	p2, _ := postSvc.GetOrCreateByPath("wtf-is/the-internet")
+	for _, w := range []int{alice, bob, carol} {
+		postSvc.ToggleLike(p1.ID, w)
+	}
+	postSvc.ToggleLike(p2.ID, alice)
+
+	t.Run("lists likers with profiles", func(t *testing.T) {
+		resp, err := postSvc.ListLikers(p1.ID, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Total != 2 || resp.Hidden != 1 {
+			t.Errorf("total = %d, hidden = %d, want 2, 1", resp.Total, resp.Hidden)
+		}
+		if len(resp.Likes) != 2 {
+			t.Fatalf("likes = %v, want 2 entries", resp.Likes)
+		}
+		for _, l := range resp.Likes {
+			if l.Address == "0xca401" {
+				t.Error("hidden wallet listed")
+			}
+			if l.Address == "0xa11ce" && l.DisplayName != "Alice" {
+				t.Errorf("display_name = %q, want Alice", l.DisplayName)
+			}
+		}
+	})
+
+	t.Run("paginates likers", func(t *testing.T) {
+		resp, err := postSvc.ListLikers(p1.ID, 1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Likes) != 1 || resp.Total != 2 {
+			t.Errorf("likes = %d, total = %d, want 1, 2", len(resp.Likes), resp.Total)
+		}
+	})
+
+	t.Run("lists the posts a wallet liked", func(t *testing.T) {
+		resp, err := postSvc.ListWalletLikes("0xA11CE", 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Total != 2 || len(resp.Likes) != 2 {
+			t.Errorf("total = %d, likes = %v, want 2", resp.Total, resp.Likes)
+		}
+	})
+
+	t.Run("honours hidden likes", func(t *testing.T) {
+		if _, err := postSvc.ListWalletLikes("0xca401", 10, 0); err != services.ErrLikesHidden {
+			t.Errorf("err = %v, want ErrLikesHidden", err)
+		}
+	})
+
+	t.Run("returns ErrWalletNotFound for unknown wallets", func(t *testing.T) {
+		if _, err := postSvc.ListWalletLikes("0xnobody", 10, 0); err != services.ErrWalletNotFound {
+			t.Errorf("err = %v, want ErrWalletNotFound", err)
+		}
+	})
+}
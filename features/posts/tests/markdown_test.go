@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"arkana/features/posts/services"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"emphasis and strikethrough", "*a* **b** ~~c~~", "<p><em>a</em> <strong>b</strong> <del>c</del></p>"},
+		{"hard wraps", "a\nb", "<p>a<br>\nb</p>"},
+		{"inline math", "$a_1 * b_2$", `<p><span class="math inline">a_1 * b_2</span></p>`},
+		{"display math", "$$\n\\frac{a}{b} < c\n$$", `<p><span class="math display">\frac{a}{b} &lt; c</span></p>`},
+		{"prices are not math", "$5 and $10", "<p>$5 and $10</p>"},
+		{"escaped dollars", `\$x\$`, "<p>$x$</p>"},
+		{"math in code spans", "`$x$`", "<p><code>$x$</code></p>"},
+		{"code blocks", "```go\nx := 1\n```", "<pre><code class=\"language-go\">x := 1\n</code></pre>"},
+		{"links", "[a](https://example.com)", `<p><a href="https://example.com" rel="nofollow ugc">a</a></p>`},
+		{"autolinks", "https://example.com", `<p><a href="https://example.com" rel="nofollow ugc">https://example.com</a></p>`},
+		{"images become links", "![a](https://example.com/a.png)", `<p><a href="https://example.com/a.png" rel="nofollow ugc">a</a></p>`},
+		{"javascript urls", "[a](javascript:alert(1))", `<p><a rel="nofollow ugc">a</a></p>`},
+		{"raw html", "<b onclick=\"x()\">a</b>", "<p>a</p>"},
+		{"html blocks", "<script>alert(1)</script>", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.TrimSpace(services.RenderMarkdown(tt.source)); got != tt.want {
+				t.Errorf("RenderMarkdown(%q) = %q, want %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommentRendering(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	commentSvc.SetEditWindow(15 * time.Minute)
+	walletID := insertTestWallet(t, db, "0xabc")
+	post, _ := postSvc.GetOrCreateByPath("test-post")
+
+	comment, _ := commentSvc.Create(post.ID, walletID, "*hi*", nil, "")
+
+	t.Run("renders on create and edit", func(t *testing.T) {
+		if comment.BodyHTML != "<p><em>hi</em></p>\n" {
+			t.Errorf("body_html = %q", comment.BodyHTML)
+		}
+		edited, _ := commentSvc.Edit(comment.ID, walletID, "**hi**", comment.Revision)
+		if edited.Body != "**hi**" || edited.BodyHTML != "<p><strong>hi</strong></p>\n" {
+			t.Errorf("edited = %+v", edited)
+		}
+
+		resp, _ := commentSvc.GetByPostID(post.ID, "", services.CommentViewer{})
+		if resp.Comments[0].BodyHTML != edited.BodyHTML {
+			t.Errorf("listed body_html = %q", resp.Comments[0].BodyHTML)
+		}
+
+		var stored string
+		db.QueryRow("SELECT body_html FROM comment_revisions WHERE comment_id = ?", comment.ID).Scan(&stored)
+		if stored != "<p><em>hi</em></p>\n" {
+			t.Errorf("revision body_html = %q, want the replaced rendering", stored)
+		}
+	})
+
+	t.Run("re-renders stale comments", func(t *testing.T) {
+		db.Exec("UPDATE comments SET body_html = 'stale', render_version = 0")
+		db.Exec("UPDATE comment_revisions SET body_html = '', render_version = 0")
+
+		n, err := commentSvc.RenderStale()
+		if err != nil || n != 2 {
+			t.Fatalf("n = %d, err = %v, want the comment and its revision", n, err)
+		}
+		resp, _ := commentSvc.GetByPostID(post.ID, "", services.CommentViewer{})
+		if resp.Comments[0].BodyHTML != "<p><strong>hi</strong></p>\n" {
+			t.Errorf("body_html = %q", resp.Comments[0].BodyHTML)
+		}
+		history, _ := commentSvc.History(comment.ID, services.CommentViewer{})
+		if history.Revisions[0].BodyHTML != "<p><em>hi</em></p>\n" {
+			t.Errorf("revision body_html = %q", history.Revisions[0].BodyHTML)
+		}
+		if n, _ := commentSvc.RenderStale(); n != 0 {
+			t.Errorf("second pass rendered %d comments", n)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"arkana/features/posts/models"
+	"arkana/features/posts/services"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeResolver map[string]string
+
+func (r fakeResolver) ResolveName(ctx context.Context, name string) (string, error) {
+	if address, ok := r[name]; ok {
+		return address, nil
+	}
+	return "", errors.New("not found")
+}
+
+type recordingNotifier struct {
+	events []services.CommentEvent
+}
+
+func (n *recordingNotifier) Notify(event services.CommentEvent) {
+	n.events = append(n.events, event)
+}
+
+func TestCommentMentions(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	commentSvc.SetEditWindow(15 * time.Minute)
+	commentSvc.SetNameResolver(fakeResolver{
+		"bob.eth": "0xBBBB000000000000000000000000000000000002",
+		"bob.com": "0xBBBB000000000000000000000000000000000002",
+	})
+	notifier := &recordingNotifier{}
+	commentSvc.SetNotifier(notifier)
+
+	author := insertTestWallet(t, db, "0xaaaa000000000000000000000000000000000001")
+	bob := insertTestWallet(t, db, "0xbbbb000000000000000000000000000000000002")
+	carol := insertTestWallet(t, db, "0xcccc000000000000000000000000000000000003")
+	db.Exec("UPDATE wallets SET display_name = 'Carol Ann' WHERE id = ?", carol)
+	post, _ := postSvc.GetOrCreateByPath("test-post")
+	commentSvc.Create(post.ID, carol, "hello", nil, "")
+
+	t.Run("resolves addresses, ENS names and display names", func(t *testing.T) {
+		body := "@0xAAAA000000000000000000000000000000000001, @bob.eth. and (@carol_ann)"
+		c, err := commentSvc.Create(post.ID, author, body, nil, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []models.Mention{
+			{Offset: 0, Length: 43, Address: "0xaaaa000000000000000000000000000000000001"},
+			{Offset: 45, Length: 8, Address: "0xbbbb000000000000000000000000000000000002"},
+			{Offset: 60, Length: 10, Address: "0xcccc000000000000000000000000000000000003", DisplayName: "Carol Ann"},
+		}
+		if !reflect.DeepEqual(c.Mentions, want) {
+			t.Fatalf("mentions = %+v", c.Mentions)
+		}
+		for _, m := range c.Mentions {
+			if body[m.Offset] != '@' {
+				t.Errorf("offset %d doesn't point at a mention", m.Offset)
+			}
+		}
+
+		resp, _ := commentSvc.GetByPostID(post.ID, "", services.CommentViewer{})
+		if !reflect.DeepEqual(resp.Comments[1].Mentions, want) {
+			t.Errorf("listed mentions = %+v", resp.Comments[1].Mentions)
+		}
+
+		// The author doesn't notify themselves
+		last := notifier.events[len(notifier.events)-1]
+		if last.Kind != services.EventMention || last.CommentID != c.ID || !reflect.DeepEqual(last.Recipients, []int{bob, carol}) {
+			t.Errorf("event = %+v", last)
+		}
+	})
+
+	t.Run("ignores code, e-mail addresses, unknown names and non-ENS domains", func(t *testing.T) {
+		before := len(notifier.events)
+		c, _ := commentSvc.Create(post.ID, author, "`@bob.eth` mail@bob.eth @nobody @alice.eth @bob.com\n\n    @carol_ann", nil, "")
+		if len(c.Mentions) != 0 || len(notifier.events) != before {
+			t.Errorf("mentions = %+v, events = %d", c.Mentions, len(notifier.events)-before)
+		}
+	})
+
+	t.Run("edits notify newly mentioned wallets only", func(t *testing.T) {
+		c, _ := commentSvc.Create(post.ID, author, "hi @bob.eth", nil, "")
+		before := len(notifier.events)
+
+		edited, err := commentSvc.Edit(c.ID, author, "hi @bob.eth and @carol_ann", c.Revision)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(edited.Mentions) != 2 || len(notifier.events) != before+1 {
+			t.Fatalf("mentions = %+v, events = %d", edited.Mentions, len(notifier.events)-before)
+		}
+		if got := notifier.events[before].Recipients; !reflect.DeepEqual(got, []int{carol}) {
+			t.Errorf("recipients = %v, want carol only", got)
+		}
+	})
+
+	t.Run("ambiguous display names resolve to nobody", func(t *testing.T) {
+		other := insertTestWallet(t, db, "0xdddd000000000000000000000000000000000004")
+		db.Exec("UPDATE wallets SET display_name = 'carol ann' WHERE id = ?", other)
+		commentSvc.Create(post.ID, other, "me too", nil, "")
+
+		c, _ := commentSvc.Create(post.ID, author, "@carol_ann?", nil, "")
+		if len(c.Mentions) != 0 {
+			t.Errorf("mentions = %+v", c.Mentions)
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"arkana/features/posts/services"
+	"errors"
+	"testing"
+)
+
+func TestCommentModeration(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	commentSvc.SetReportThreshold(2)
+
+	author := insertTestWallet(t, db, "0xaaaa000000000000000000000000000000000001")
+	bob := insertTestWallet(t, db, "0xbbbb000000000000000000000000000000000002")
+	carol := insertTestWallet(t, db, "0xcccc000000000000000000000000000000000003")
+	moderator := insertTestWallet(t, db, "0xdddd000000000000000000000000000000000004")
+	post, _ := postSvc.GetOrCreateByPath("test-post")
+	root, _ := commentSvc.Create(post.ID, author, "buy cheap tokens", nil, "")
+	reply, _ := commentSvc.Create(post.ID, bob, "no thanks", &root.ID, "")
+
+	visible := func(viewer services.CommentViewer) []int {
+		resp, err := commentSvc.GetByPostID(post.ID, "", viewer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, c := range resp.Comments {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+
+	t.Run("validates reports", func(t *testing.T) {
+		if _, err := commentSvc.Report(root.ID, services.CommentViewer{WalletID: bob}, "boring"); !errors.Is(err, services.ErrInvalidReportReason) {
+			t.Errorf("invalid reason: err = %v", err)
+		}
+		if _, err := commentSvc.Report(root.ID, services.CommentViewer{WalletID: author}, "spam"); !errors.Is(err, services.ErrSelfReport) {
+			t.Errorf("own comment: err = %v", err)
+		}
+		if _, err := commentSvc.Report(999, services.CommentViewer{WalletID: bob}, "spam"); !errors.Is(err, services.ErrCommentNotFound) {
+			t.Errorf("unknown comment: err = %v", err)
+		}
+	})
+
+	t.Run("hides comments reported by enough wallets", func(t *testing.T) {
+		commentSvc.Report(root.ID, services.CommentViewer{WalletID: bob}, "spam")
+		commentSvc.Report(root.ID, services.CommentViewer{WalletID: bob}, "harassment")
+		if ids := visible(services.CommentViewer{}); len(ids) != 2 {
+			t.Fatalf("one wallet reporting twice hid the comment: %v", ids)
+		}
+
+		commentSvc.Report(root.ID, services.CommentViewer{WalletID: carol}, "spam")
+		if ids := visible(services.CommentViewer{}); len(ids) != 1 || ids[0] != reply.ID {
+			t.Errorf("anonymous = %v, want only the reply", ids)
+		}
+		if ids := visible(services.CommentViewer{WalletID: author}); len(ids) != 2 {
+			t.Errorf("author = %v, want both", ids)
+		}
+		resp, _ := commentSvc.GetByPostID(post.ID, "", services.CommentViewer{Moderator: true})
+		if len(resp.Comments) != 2 || resp.Comments[0].Status != "hidden" || resp.Comments[0].Body != "buy cheap tokens" {
+			t.Errorf("moderator = %+v", resp.Comments)
+		}
+	})
+
+	t.Run("shows hidden comments in threads like the list", func(t *testing.T) {
+		tree, err := commentSvc.Tree(post.ID, services.CommentTreeOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tree.Comments) != 0 {
+			t.Errorf("anonymous tree = %+v, want the hidden root left out", tree.Comments)
+		}
+		tree, _ = commentSvc.Tree(post.ID, services.CommentTreeOptions{Depth: 2, Viewer: services.CommentViewer{WalletID: author}})
+		if len(tree.Comments) != 1 || tree.Comments[0].Body != "buy cheap tokens" || len(tree.Comments[0].Replies) != 1 {
+			t.Errorf("author tree = %+v, want the root with its reply", tree.Comments)
+		}
+
+		if _, err := commentSvc.Context(root.ID, 2, services.CommentTreeOptions{}); !errors.Is(err, services.ErrCommentNotFound) {
+			t.Errorf("anonymous permalink: err = %v, want ErrCommentNotFound", err)
+		}
+		ctx, err := commentSvc.Context(reply.ID, 2, services.CommentTreeOptions{})
+		if err != nil || len(ctx.Ancestors) != 0 {
+			t.Errorf("reply permalink = %+v, %v; want no ancestors", ctx, err)
+		}
+		ctx, _ = commentSvc.Context(reply.ID, 2, services.CommentTreeOptions{Viewer: services.CommentViewer{Moderator: true}})
+		if len(ctx.Ancestors) != 1 || ctx.Ancestors[0].ID != root.ID {
+			t.Errorf("moderator ancestors = %+v, want the root", ctx.Ancestors)
+		}
+
+		if _, err := commentSvc.History(root.ID, services.CommentViewer{WalletID: bob}); !errors.Is(err, services.ErrCommentNotFound) {
+			t.Errorf("history for another wallet: err = %v, want ErrCommentNotFound", err)
+		}
+		if _, err := commentSvc.History(root.ID, services.CommentViewer{WalletID: author}); err != nil {
+			t.Errorf("history for the author: err = %v", err)
+		}
+		if _, err := commentSvc.Vote(root.ID, services.CommentViewer{WalletID: carol}, 1); !errors.Is(err, services.ErrCommentNotFound) {
+			t.Errorf("vote from another wallet: err = %v, want ErrCommentNotFound", err)
+		}
+		if _, err := commentSvc.Report(root.ID, services.CommentViewer{WalletID: carol}, "spam"); !errors.Is(err, services.ErrCommentNotFound) {
+			t.Errorf("report from another wallet: err = %v, want ErrCommentNotFound", err)
+		}
+		if _, err := commentSvc.Vote(root.ID, services.CommentViewer{WalletID: carol, Moderator: true}, 1); err != nil {
+			t.Errorf("vote from a moderator: err = %v", err)
+		}
+		commentSvc.Vote(root.ID, services.CommentViewer{WalletID: carol, Moderator: true}, 0)
+
+		if _, err := commentSvc.Create(post.ID, carol, "me too", &root.ID, ""); err == nil {
+			t.Error("replied to a hidden comment")
+		}
+	})
+
+	t.Run("aggregates reports in the queue", func(t *testing.T) {
+		commentSvc.Report(reply.ID, services.CommentViewer{WalletID: carol}, "off_topic")
+
+		queue, err := commentSvc.ModerationQueue(10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if queue.Total != 2 || len(queue.Comments) != 2 {
+			t.Fatalf("queue = %+v, want both comments", queue)
+		}
+		first := queue.Comments[0]
+		if first.CommentID != root.ID || first.Reports != 2 || first.Reasons["spam"] != 1 || first.Reasons["harassment"] != 1 ||
+			first.PostPath != "test-post" || first.LastReportedAt.IsZero() {
+			t.Errorf("first = %+v, want the root with 2 reports", first)
+		}
+	})
+
+	t.Run("restores comments and resolves their reports", func(t *testing.T) {
+		if _, err := commentSvc.Moderate(root.ID, moderator, "restore", " "); !errors.Is(err, services.ErrInvalidModerationReason) {
+			t.Errorf("blank reason: err = %v", err)
+		}
+		if _, err := commentSvc.Moderate(root.ID, moderator, "shadowban", "spam"); !errors.Is(err, services.ErrInvalidDecision) {
+			t.Errorf("unknown decision: err = %v", err)
+		}
+
+		resp, err := commentSvc.Moderate(root.ID, moderator, "restore", "satire")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status != "visible" || len(visible(services.CommentViewer{})) != 2 {
+			t.Errorf("resp = %+v, want the comment visible again", resp)
+		}
+		queue, _ := commentSvc.ModerationQueue(10, 0)
+		if queue.Total != 1 || queue.Comments[0].CommentID != reply.ID {
+			t.Errorf("queue = %+v, want only the reply", queue)
+		}
+
+		// Overruled reports can't be opened again to re-hide the comment
+		if _, err := commentSvc.Report(root.ID, services.CommentViewer{WalletID: bob}, "spam"); !errors.Is(err, services.ErrReportResolved) {
+			t.Errorf("report again: err = %v, want ErrReportResolved", err)
+		}
+		commentSvc.Report(root.ID, services.CommentViewer{WalletID: carol}, "harassment")
+		if len(visible(services.CommentViewer{})) != 2 {
+			t.Error("hidden again by resolved reports")
+		}
+	})
+
+	t.Run("bans authors", func(t *testing.T) {
+		resp, err := commentSvc.Moderate(root.ID, moderator, "ban", "spam bot")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status != "hidden" {
+			t.Errorf("resp = %+v, want the comment hidden", resp)
+		}
+		if _, err := commentSvc.Create(post.ID, author, "again", nil, ""); !errors.Is(err, services.ErrWalletBanned) {
+			t.Errorf("create: err = %v, want ErrWalletBanned", err)
+		}
+		if _, err := commentSvc.Vote(reply.ID, services.CommentViewer{WalletID: author}, 1); !errors.Is(err, services.ErrWalletBanned) {
+			t.Errorf("vote: err = %v, want ErrWalletBanned", err)
+		}
+		if _, err := commentSvc.Report(reply.ID, services.CommentViewer{WalletID: author}, "spam"); !errors.Is(err, services.ErrWalletBanned) {
+			t.Errorf("report: err = %v, want ErrWalletBanned", err)
+		}
+	})
+
+	t.Run("deletes comments and keeps the log", func(t *testing.T) {
+		resp, err := commentSvc.Moderate(reply.ID, moderator, "delete", "off topic")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Purged {
+			t.Errorf("resp = %+v, want the reply purged", resp)
+		}
+		if _, err := commentSvc.Moderate(reply.ID, moderator, "hide", "again"); !errors.Is(err, services.ErrCommentNotFound) {
+			t.Errorf("purged comment: err = %v", err)
+		}
+
+		var actions, automatic int
+		db.QueryRow("SELECT COUNT(*), COUNT(*) - COUNT(moderator_id) FROM comment_moderation_log").Scan(&actions, &automatic)
+		if actions != 4 || automatic != 1 {
+			t.Errorf("log has %d actions, %d automatic; want 4, 1", actions, automatic)
+		}
+		if queue, _ := commentSvc.ModerationQueue(10, 0); queue.Total != 0 {
+			t.Errorf("queue = %+v, want empty", queue)
+		}
+	})
+
+	t.Run("keeps comments whose deletion could not be logged", func(t *testing.T) {
+		c, _ := commentSvc.Create(post.ID, carol, "ok", nil, "")
+		db.Exec("DROP TABLE comment_moderation_log")
+
+		if _, err := commentSvc.Moderate(c.ID, moderator, "delete", "off topic"); err == nil {
+			t.Fatal("deleted without a log")
+		}
+		if ids := visible(services.CommentViewer{}); len(ids) != 1 || ids[0] != c.ID {
+			t.Errorf("comments = %v, want the comment kept", ids)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"arkana/features/posts/services"
+	"errors"
+	"testing"
+)
+
+func TestRenamePost(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	walletID := insertTestWallet(t, db, "0xabc")
+
+	post, _ := postSvc.GetOrCreateByPath("wtf-is/riscv")
+	postSvc.ToggleLike(post.ID, walletID)
+	commentSvc.Create(post.ID, walletID, "nice", nil, "")
+
+	renamed, err := postSvc.Rename("wtf-is/riscv", "wtf-is/risc-v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.ID != post.ID || renamed.PathIdentifier != "wtf-is/risc-v" {
+		t.Fatalf("renamed = %+v, want post %d at wtf-is/risc-v", renamed, post.ID)
+	}
+
+	t.Run("old path resolves to the renamed post", func(t *testing.T) {
+		info, err := postSvc.GetPostInfo("wtf-is/riscv", "0xabc", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.CanonicalPath != "wtf-is/risc-v" {
+			t.Errorf("canonical_path = %q, want %q", info.CanonicalPath, "wtf-is/risc-v")
+		}
+		if info.LikeCount != 1 || !info.Liked {
+			t.Errorf("like_count = %d, liked = %v, want 1, true", info.LikeCount, info.Liked)
+		}
+	})
+
+	t.Run("rejects a path used by another post", func(t *testing.T) {
+		postSvc.GetOrCreateByPath("wtf-is/the-internet")
+		_, err := postSvc.Rename("wtf-is/risc-v", "wtf-is/the-internet")
+		if err != services.ErrPathTaken {
+			t.Errorf("err = %v, want ErrPathTaken", err)
+		}
+	})
+
+	t.Run("renaming back reuses the former path", func(t *testing.T) {
+		if _, err := postSvc.Rename("wtf-is/risc-v", "wtf-is/riscv"); err != nil {
+			t.Fatal(err)
+		}
+		p, err := postSvc.GetByPath("wtf-is/risc-v")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.PathIdentifier != "wtf-is/riscv" {
+			t.Errorf("path = %q, want %q", p.PathIdentifier, "wtf-is/riscv")
+		}
+	})
+
+	t.Run("moving to another prefix changes series", func(t *testing.T) {
+		p, err := postSvc.Rename("wtf-is/riscv", "hardware/risc-v")
+		if err != nil {
+			t.Fatal(err)
+		}
+		series, err := services.NewSeriesService(db).GetBySlug("hardware")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.SeriesID == nil || *p.SeriesID != series.ID {
+			t.Errorf("series_id = %v, want %d", p.SeriesID, series.ID)
+		}
+	})
+}
+
+func TestMergePosts(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	w1 := insertTestWallet(t, db, "0xabc")
+	w2 := insertTestWallet(t, db, "0xdef")
+
+	old, _ := postSvc.GetOrCreateByPath("blog/old-slug")
+	current, _ := postSvc.GetOrCreateByPath("blog/new-slug")
+	postSvc.ToggleLike(old.ID, w1)
+	postSvc.ToggleLike(old.ID, w2)
+	postSvc.ToggleLike(current.ID, w1)
+	commentSvc.Create(old.ID, w2, "first", nil, "")
+
+	merged, err := postSvc.Merge("blog/old-slug", "blog/new-slug")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("deduplicates likes", func(t *testing.T) {
+		if merged.LikeCount != 2 {
+			t.Errorf("like_count = %d, want 2", merged.LikeCount)
+		}
+	})
+
+	t.Run("re-points comments", func(t *testing.T) {
+		resp, err := commentSvc.GetByPostID(current.ID, "", services.CommentViewer{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Total != 1 {
+			t.Errorf("total = %d, want 1", resp.Total)
+		}
+	})
+
+	t.Run("source path becomes an alias", func(t *testing.T) {
+		p, err := postSvc.GetByPath("blog/old-slug")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.ID != current.ID {
+			t.Errorf("id = %d, want %d", p.ID, current.ID)
+		}
+	})
+
+	t.Run("rejects merging a post into itself", func(t *testing.T) {
+		_, err := postSvc.Merge("blog/old-slug", "blog/new-slug")
+		if err != services.ErrSelfMerge {
+			t.Errorf("err = %v, want ErrSelfMerge", err)
+		}
+	})
+}
+
+func TestFoldLocalePaths(t *testing.T) {
+	db := setupTestDB(t)
+	svc := services.NewPostService(db)
+	svc.SetSupportedLocales([]string{"en", "es"})
+	seriesSvc := services.NewSeriesService(db)
+	seriesSvc.SetSupportedLocales([]string{"en", "es"})
+	alice := insertTestWallet(t, db, "0xabc")
+	bob := insertTestWallet(t, db, "0xdef")
+
+	// Rows written before translations shared their canonical post, when
+	// locale prefixes were taken for series
+	canonical := insertTestPost(t, db, "wtf-is/risc-v")
+	spanish := insertTestPost(t, db, "es/wtf-is/risc-v")
+	orphan := insertTestPost(t, db, "en/wtf-is/the-internet")
+	if _, err := services.NewSeriesService(db).AssignFromPaths(); err != nil {
+		t.Fatal(err)
+	}
+	svc.ToggleLike(canonical, alice)
+	svc.ToggleLike(spanish, alice)
+	svc.ToggleLike(spanish, bob)
+	services.NewCommentService(db).Create(spanish, bob, "hola", nil, "")
+
+	dry, err := svc.FoldLocalePaths(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dry.Folded) != 2 || !dry.Folded[0].Merged || dry.Folded[1].Merged {
+		t.Errorf("dry run = %+v, want a merge and a rename", dry.Folded)
+	}
+	if _, err := svc.GetByPath("wtf-is/the-internet"); !errors.Is(err, services.ErrPostNotFound) {
+		t.Errorf("dry run renamed a post: err = %v", err)
+	}
+
+	report, err := svc.FoldLocalePaths(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Folded) != 2 || report.Folded[0].Path != "es/wtf-is/risc-v" || report.Folded[0].Canonical != "wtf-is/risc-v" {
+		t.Errorf("folded = %+v, want both localized posts", report.Folded)
+	}
+
+	t.Run("merges into the canonical post", func(t *testing.T) {
+		post, err := svc.GetByPath("es/wtf-is/risc-v")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.ID != canonical || post.LikeCount != 2 {
+			t.Errorf("post = %+v, want the canonical post with 2 likes", post)
+		}
+		resp, _ := services.NewCommentService(db).GetByPostID(canonical, "es", services.CommentViewer{})
+		if resp.Total != 1 {
+			t.Errorf("comments = %+v, want the Spanish comment", resp.Comments)
+		}
+	})
+
+	t.Run("renames posts without a canonical post", func(t *testing.T) {
+		post, err := svc.GetByPath("wtf-is/the-internet")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.ID != orphan || post.SeriesID == nil {
+			t.Errorf("post = %+v, want the renamed post in its series", post)
+		}
+	})
+
+	t.Run("drops series named after locales", func(t *testing.T) {
+		if _, err := seriesSvc.GetBySlug("es"); !errors.Is(err, services.ErrSeriesNotFound) {
+			t.Errorf("err = %v, want ErrSeriesNotFound", err)
+		}
+		insertTestPost(t, db, "es/wtf-is/tcp")
+		if n, _ := seriesSvc.AssignFromPaths(); n != 0 {
+			t.Errorf("assigned %d localized posts to series, want 0", n)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"arkana/features/posts/models"
+	"arkana/features/posts/services"
+	"slices"
+	"testing"
+)
+
+const testAuthor = "0x00000000000000000000000000000000000000aa"
+
+func seedMetadata(t *testing.T, svc *services.SyncService) {
+	t.Helper()
+	manifest, err := services.ParseJSONManifest([]byte(`[
+		{"path": "cryptography-101/hashing", "title": "Hashing", "summary": "Digests", "tags": ["crypto", "hashing"],
+		 "authors": ["0x00000000000000000000000000000000000000AA"], "published_at": "2024-03-01", "reading_time": 9},
+		{"path": "cryptography-101/rings", "title": "Rings", "tags": ["crypto", "math"], "published_at": "2024-04-01"},
+		{"path": "wtf-is/risc-v", "title": "RISC-V", "tags": ["hardware"], "published_at": "2025-01-10"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Sync(manifest, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostInfoMetadata(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	seedMetadata(t, services.NewSyncService(db, "", ""))
+
+	info, err := postSvc.GetPostInfo("cryptography-101/hashing", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Title != "Hashing" || info.Summary != "Digests" || info.ReadingTime != 9 {
+		t.Errorf("metadata = %+v", info.PostMetadata)
+	}
+	if info.Series != "cryptography-101" {
+		t.Errorf("series = %q, want %q", info.Series, "cryptography-101")
+	}
+	if !slices.Equal(info.Tags, []string{"crypto", "hashing"}) {
+		t.Errorf("tags = %v, want [crypto hashing]", info.Tags)
+	}
+	if !slices.Equal(info.Authors, []string{testAuthor}) {
+		t.Errorf("authors = %v, want [%s]", info.Authors, testAuthor)
+	}
+}
+
+func TestListPosts(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	seedMetadata(t, services.NewSyncService(db, "", ""))
+
+	paths := func(resp *models.PostListResponse) []string {
+		var out []string
+		for _, p := range resp.Posts {
+			out = append(out, p.Path)
+		}
+		return out
+	}
+
+	t.Run("lists newest first", func(t *testing.T) {
+		resp, err := postSvc.List(models.PostFilter{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"wtf-is/risc-v", "cryptography-101/rings", "cryptography-101/hashing"}
+		if !slices.Equal(paths(resp), want) {
+			t.Errorf("paths = %v, want %v", paths(resp), want)
+		}
+	})
+
+	t.Run("filters by tag", func(t *testing.T) {
+		resp, err := postSvc.List(models.PostFilter{Tag: "Crypto", Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Total != 2 {
+			t.Errorf("total = %d, want 2", resp.Total)
+		}
+	})
+
+	t.Run("filters by author", func(t *testing.T) {
+		resp, err := postSvc.List(models.PostFilter{Author: testAuthor, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(paths(resp), []string{"cryptography-101/hashing"}) {
+			t.Errorf("paths = %v, want [cryptography-101/hashing]", paths(resp))
+		}
+	})
+
+	t.Run("filters by series and paginates", func(t *testing.T) {
+		resp, err := postSvc.List(models.PostFilter{Series: "cryptography-101", Limit: 1, Offset: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Total != 2 || !slices.Equal(paths(resp), []string{"cryptography-101/hashing"}) {
+			t.Errorf("total = %d, paths = %v", resp.Total, paths(resp))
+		}
+	})
+
+	t.Run("counts tags", func(t *testing.T) {
+		resp, err := postSvc.ListTags()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Tags) != 4 || resp.Tags[0] != (models.TagCount{Tag: "crypto", Count: 2}) {
+			t.Errorf("tags = %+v", resp.Tags)
+		}
+	})
+}
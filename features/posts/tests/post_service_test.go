@@ -84,3 +84,89 @@ func TestToggleLike(t *testing.T) {
 		}
 	})
 }
+
+func TestLocalizedPaths(t *testing.T) {
+	db := setupTestDB(t)
+	svc := services.NewPostService(db)
+	svc.SetSupportedLocales([]string{"en", "es"})
+	walletID := insertTestWallet(t, db, "0xabc")
+	wallet2 := insertTestWallet(t, db, "0xdef")
+
+	t.Run("creates the canonical post from a localized path", func(t *testing.T) {
+		post, err := svc.GetOrCreateByPath("es/cryptography-101/hashing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.PathIdentifier != "cryptography-101/hashing" {
+			t.Errorf("path = %q, want %q", post.PathIdentifier, "cryptography-101/hashing")
+		}
+		if post.Locale != "es" {
+			t.Errorf("locale = %q, want %q", post.Locale, "es")
+		}
+	})
+
+	t.Run("resolves every locale to the same post", func(t *testing.T) {
+		en, err := svc.GetByPath("en/cryptography-101/hashing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		es, err := svc.GetByPath("es/cryptography-101/hashing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		plain, err := svc.GetByPath("cryptography-101/hashing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if en.ID != es.ID || en.ID != plain.ID {
+			t.Errorf("IDs differ: en=%d es=%d plain=%d", en.ID, es.ID, plain.ID)
+		}
+	})
+
+	t.Run("aggregates likes across locales", func(t *testing.T) {
+		en, _ := svc.GetByPath("en/cryptography-101/hashing")
+		es, _ := svc.GetByPath("es/cryptography-101/hashing")
+		svc.ToggleLike(en.ID, walletID)
+		svc.ToggleLike(es.ID, wallet2)
+
+		info, err := svc.GetPostInfo("es/cryptography-101/hashing", "0xabc", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.LikeCount != 2 {
+			t.Errorf("like_count = %d, want 2", info.LikeCount)
+		}
+		if !info.Liked {
+			t.Error("liked = false, want true")
+		}
+		if info.Locale != "es" || len(info.Locales) != 1 || info.Locales[0] != "es" {
+			t.Errorf("locale = %q, locales = %v", info.Locale, info.Locales)
+		}
+	})
+
+	t.Run("leaves unsupported prefixes in the path", func(t *testing.T) {
+		if _, err := svc.GetByPath("fr/cryptography-101/hashing"); err != services.ErrPostNotFound {
+			t.Errorf("err = %v, want ErrPostNotFound", err)
+		}
+	})
+}
+
+func TestSetLike(t *testing.T) {
+	db := setupTestDB(t)
+	svc := services.NewPostService(db)
+	walletID := insertTestWallet(t, db, "0xabc")
+	post, _ := svc.GetOrCreateByPath("test-post")
+
+	for i, tc := range []struct {
+		liked bool
+		want  int
+	}{{true, 1}, {true, 1}, {false, 0}, {false, 0}} {
+		count, err := svc.SetLike(post.ID, walletID, tc.liked)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != tc.want {
+			t.Errorf("step %d: SetLike(%v) count = %d, want %d", i, tc.liked, count, tc.want)
+		}
+	}
+}
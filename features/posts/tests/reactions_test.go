@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"arkana/features/posts/models"
+	"arkana/features/posts/services"
+	"slices"
+	"testing"
+)
+
+func TestToggleReaction(t *testing.T) {
+	db := setupTestDB(t)
+	svc := services.NewPostService(db)
+	svc.SetReactions([]string{"👍", "🤯", "🧠"})
+	w1 := insertTestWallet(t, db, "0xabc")
+	w2 := insertTestWallet(t, db, "0xdef")
+	post, _ := svc.GetOrCreateByPath("test-post")
+
+	t.Run("allows several reactions per wallet", func(t *testing.T) {
+		if _, err := svc.ToggleReaction(post.ID, w1, "🤯"); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := svc.ToggleReaction(post.ID, w1, "🧠")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Reacted {
+			t.Error("reacted = false, want true")
+		}
+		want := []models.ReactionCount{{Reaction: "👍", Count: 0}, {Reaction: "🤯", Count: 1}, {Reaction: "🧠", Count: 1}}
+		if !slices.Equal(resp.Reactions, want) {
+			t.Errorf("reactions = %v, want %v", resp.Reactions, want)
+		}
+	})
+
+	t.Run("thumbs up is the like", func(t *testing.T) {
+		resp, err := svc.ToggleReaction(post.ID, w2, "👍")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Reactions[0].Count != 1 {
+			t.Errorf("👍 count = %d, want 1", resp.Reactions[0].Count)
+		}
+		liked, count, _ := svc.ToggleLike(post.ID, w2)
+		if liked || count != 0 {
+			t.Errorf("liked = %v, count = %d, want the like removed", liked, count)
+		}
+	})
+
+	t.Run("toggling again removes the reaction", func(t *testing.T) {
+		resp, err := svc.ToggleReaction(post.ID, w1, "🤯")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Reacted || resp.Reactions[1].Count != 0 {
+			t.Errorf("resp = %+v, want 🤯 removed", resp)
+		}
+	})
+
+	t.Run("rejects reactions outside the set", func(t *testing.T) {
+		if _, err := svc.ToggleReaction(post.ID, w1, "💩"); err != services.ErrUnknownReaction {
+			t.Errorf("err = %v, want ErrUnknownReaction", err)
+		}
+	})
+
+	t.Run("info lists counts and the viewer's reactions", func(t *testing.T) {
+		svc.ToggleLike(post.ID, w1)
+		info, err := svc.GetPostInfo("test-post", "0xabc", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(info.MyReactions, []string{"👍", "🧠"}) {
+			t.Errorf("my_reactions = %v, want [👍 🧠]", info.MyReactions)
+		}
+		if len(info.Reactions) != 3 || info.Reactions[0].Count != 1 {
+			t.Errorf("reactions = %v", info.Reactions)
+		}
+	})
+}
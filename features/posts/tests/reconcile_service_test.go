@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"arkana/features/posts/services"
+	"testing"
+)
+
+func TestReconcileCounters(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	postSvc.SetReactions([]string{"👍", "🧠"})
+	svc := services.NewReconcileService(db)
+	walletID := insertTestWallet(t, db, "0xabc")
+
+	post, _ := postSvc.GetOrCreateByPath("test-post")
+	postSvc.ToggleLike(post.ID, walletID)
+	postSvc.ToggleReaction(post.ID, walletID, "🧠")
+
+	t.Run("returns ErrNoIntegrityReport before the first run", func(t *testing.T) {
+		if _, err := svc.Latest(); err != services.ErrNoIntegrityReport {
+			t.Errorf("err = %v, want ErrNoIntegrityReport", err)
+		}
+	})
+
+	t.Run("reports nothing for consistent counters", func(t *testing.T) {
+		report, err := svc.Run(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Discrepancies) != 0 {
+			t.Errorf("discrepancies = %v, want none", report.Discrepancies)
+		}
+	})
+
+	db.Exec("UPDATE posts SET like_count = 7 WHERE id = ?", post.ID)
+	db.Exec("DELETE FROM post_reaction_counts WHERE post_id = ?", post.ID)
+
+	t.Run("reports drifted counters without fixing them", func(t *testing.T) {
+		report, err := svc.Run(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Discrepancies) != 2 || report.Fixed {
+			t.Fatalf("report = %+v, want 2 unfixed discrepancies", report)
+		}
+		like := report.Discrepancies[0]
+		if like.Counter != "posts.like_count" || like.Stored != 7 || like.Actual != 1 {
+			t.Errorf("like discrepancy = %+v", like)
+		}
+		reaction := report.Discrepancies[1]
+		if reaction.Key != "🧠" || reaction.Stored != 0 || reaction.Actual != 1 {
+			t.Errorf("reaction discrepancy = %+v", reaction)
+		}
+	})
+
+	t.Run("fixes drifted counters", func(t *testing.T) {
+		report, err := svc.Run(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !report.Fixed {
+			t.Error("fixed = false, want true")
+		}
+
+		info, _ := postSvc.GetPostInfo("test-post", "", "")
+		if info.LikeCount != 1 || info.Reactions[1].Count != 1 {
+			t.Errorf("like_count = %d, reactions = %v, want repaired counts", info.LikeCount, info.Reactions)
+		}
+
+		report, _ = svc.Run(false)
+		if len(report.Discrepancies) != 0 {
+			t.Errorf("discrepancies after fix = %v, want none", report.Discrepancies)
+		}
+	})
+
+	t.Run("keeps the latest report", func(t *testing.T) {
+		latest, err := svc.Latest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if latest.Fixed || len(latest.Discrepancies) != 0 {
+			t.Errorf("latest = %+v, want the last clean run", latest)
+		}
+	})
+}
+
+func TestReconcileCommentVotes(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	svc := services.NewReconcileService(db)
+	author := insertTestWallet(t, db, "0xabc")
+	voter := insertTestWallet(t, db, "0xdef")
+
+	post, _ := postSvc.GetOrCreateByPath("test-post")
+	comment, _ := commentSvc.Create(post.ID, author, "hi", nil, "")
+	commentSvc.Vote(comment.ID, services.CommentViewer{WalletID: voter}, 1)
+	db.Exec("UPDATE comments SET upvotes = 3, downvotes = 1, score = 0.5 WHERE id = ?", comment.ID)
+
+	report, err := svc.Run(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Discrepancies) != 2 || report.Discrepancies[0].Counter != "comments.upvotes" {
+		t.Fatalf("discrepancies = %+v", report.Discrepancies)
+	}
+
+	var up, down int
+	var score float64
+	db.QueryRow("SELECT upvotes, downvotes, score FROM comments WHERE id = ?", comment.ID).Scan(&up, &down, &score)
+	if up != 1 || down != 0 || score != services.WilsonScore(1, 0) {
+		t.Errorf("upvotes = %d, downvotes = %d, score = %v", up, down, score)
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"arkana/features/posts/services"
+	"fmt"
+	"testing"
+)
+
+func TestRelated(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	svc := services.NewRelatedService(postSvc, 2, 1)
+
+	var wallets []int
+	for i := 0; i < 4; i++ {
+		wallets = append(wallets, insertTestWallet(t, db, fmt.Sprintf("0x%d", i)))
+	}
+	start, _ := postSvc.GetOrCreateByPath("wtf-is/risc-v")
+	partOne, _ := postSvc.GetOrCreateByPath("blockchain-101/genesis")
+	partTwo, _ := postSvc.GetOrCreateByPath("blockchain-101/blocks")
+	lonely, _ := postSvc.GetOrCreateByPath("essays/alone")
+
+	for _, w := range wallets[:3] {
+		postSvc.ToggleLike(start.ID, w)
+		postSvc.ToggleLike(partTwo.ID, w)
+	}
+	// Commenting counts as engagement too
+	db.Exec("INSERT INTO comments (post_id, wallet_id, body) VALUES (?, ?, 'nice')", partOne.ID, wallets[0])
+	postSvc.ToggleLike(partOne.ID, wallets[1])
+	// A single shared reader is below the minimum support
+	postSvc.ToggleLike(lonely.ID, wallets[0])
+
+	if err := svc.Recompute(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("ranks by co-engagement and keeps one post per series", func(t *testing.T) {
+		related, err := svc.Related(start.ID, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(related) != 1 || related[0].Path != "blockchain-101/blocks" {
+			t.Fatalf("related = %+v, want only blockchain-101/blocks", related)
+		}
+		if related[0].Support != 3 || related[0].Score != 1 {
+			t.Errorf("support = %d, score = %v, want 3 and 1", related[0].Support, related[0].Score)
+		}
+	})
+
+	t.Run("requires minimum support", func(t *testing.T) {
+		related, _ := svc.Related(lonely.ID, 5)
+		if len(related) != 0 {
+			t.Errorf("related = %+v, want none", related)
+		}
+	})
+
+	t.Run("drops merged posts", func(t *testing.T) {
+		if _, err := postSvc.Merge("blockchain-101/blocks", "blockchain-101/genesis"); err != nil {
+			t.Fatal(err)
+		}
+		related, _ := svc.Related(start.ID, 5)
+		if len(related) != 0 {
+			t.Errorf("related = %+v, want none until the next recompute", related)
+		}
+	})
+}
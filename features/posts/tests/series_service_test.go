@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"arkana/features/posts/services"
+	"testing"
+)
+
+func TestSeriesAssignment(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	seriesSvc := services.NewSeriesService(db)
+
+	t.Run("new posts join the series named by their prefix", func(t *testing.T) {
+		post, err := postSvc.GetOrCreateByPath("blockchain-101/how-it-all-began")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.SeriesID == nil {
+			t.Fatal("series_id = nil, want a series")
+		}
+
+		series, err := seriesSvc.GetBySlug("blockchain-101")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if series.ID != *post.SeriesID {
+			t.Errorf("series_id = %d, want %d", *post.SeriesID, series.ID)
+		}
+		if series.Title != "Blockchain 101" {
+			t.Errorf("title = %q, want %q", series.Title, "Blockchain 101")
+		}
+	})
+
+	t.Run("titles slugs starting with multi-byte letters", func(t *testing.T) {
+		postSvc.GetOrCreateByPath("éléments-de-cryptographie/introduction")
+		series, err := seriesSvc.GetBySlug("éléments-de-cryptographie")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if series.Title != "Éléments De Cryptographie" {
+			t.Errorf("title = %q, want %q", series.Title, "Éléments De Cryptographie")
+		}
+	})
+
+	t.Run("posts without a prefix have no series", func(t *testing.T) {
+		post, err := postSvc.GetOrCreateByPath("standalone")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.SeriesID != nil {
+			t.Errorf("series_id = %d, want nil", *post.SeriesID)
+		}
+	})
+
+	t.Run("assigns existing posts from their paths", func(t *testing.T) {
+		insertTestPost(t, db, "wtf-is/risc-v")
+		insertTestPost(t, db, "wtf-is/the-internet")
+
+		n, err := seriesSvc.AssignFromPaths()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 {
+			t.Errorf("assigned = %d, want 2", n)
+		}
+
+		detail, err := seriesSvc.GetDetail("wtf-is")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(detail.Parts) != 2 {
+			t.Errorf("parts = %d, want 2", len(detail.Parts))
+		}
+	})
+
+	t.Run("returns ErrSeriesNotFound for unknown slug", func(t *testing.T) {
+		_, err := seriesSvc.GetBySlug("nope")
+		if err != services.ErrSeriesNotFound {
+			t.Errorf("err = %v, want ErrSeriesNotFound", err)
+		}
+	})
+}
+
+func TestSeriesNavigation(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	seriesSvc := services.NewSeriesService(db)
+
+	var paths []string
+	for _, p := range []string{"part-1", "part-2", "part-10"} {
+		path := "elliptic-curves-in-depth/" + p
+		if _, err := postSvc.GetOrCreateByPath(path); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	if err := seriesSvc.SetPartOrder("elliptic-curves-in-depth", paths); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("first part has only next", func(t *testing.T) {
+		post, _ := postSvc.GetByPath(paths[0])
+		nav, err := seriesSvc.Navigation(post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nav.Previous != nil {
+			t.Errorf("previous = %v, want nil", nav.Previous)
+		}
+		if nav.Next == nil || nav.Next.Path != paths[1] {
+			t.Errorf("next = %v, want %s", nav.Next, paths[1])
+		}
+		if nav.Position != 1 || nav.Total != 3 {
+			t.Errorf("position = %d/%d, want 1/3", nav.Position, nav.Total)
+		}
+	})
+
+	t.Run("middle part has both neighbours", func(t *testing.T) {
+		post, _ := postSvc.GetByPath(paths[1])
+		nav, err := seriesSvc.Navigation(post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nav.Previous == nil || nav.Previous.Path != paths[0] {
+			t.Errorf("previous = %v, want %s", nav.Previous, paths[0])
+		}
+		if nav.Next == nil || nav.Next.Path != paths[2] {
+			t.Errorf("next = %v, want %s", nav.Next, paths[2])
+		}
+	})
+
+	t.Run("rejects ordering posts from another series", func(t *testing.T) {
+		postSvc.GetOrCreateByPath("wtf-is/risc-v")
+		err := seriesSvc.SetPartOrder("elliptic-curves-in-depth", []string{"wtf-is/risc-v"})
+		if err != services.ErrPostNotFound {
+			t.Errorf("err = %v, want ErrPostNotFound", err)
+		}
+	})
+}
+
+func TestSeriesStats(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	commentSvc := services.NewCommentService(db)
+	seriesSvc := services.NewSeriesService(db)
+
+	w1 := insertTestWallet(t, db, "0xabc")
+	w2 := insertTestWallet(t, db, "0xdef")
+	p1, _ := postSvc.GetOrCreateByPath("the-zk-chronicles/first-steps")
+	p2, _ := postSvc.GetOrCreateByPath("the-zk-chronicles/sum-check")
+
+	postSvc.ToggleLike(p1.ID, w1)
+	postSvc.ToggleLike(p2.ID, w1)
+	commentSvc.Create(p2.ID, w2, "nice", nil, "")
+
+	stats, err := seriesSvc.Stats(*p1.SeriesID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.PostCount != 2 {
+		t.Errorf("post_count = %d, want 2", stats.PostCount)
+	}
+	if stats.TotalLikes != 2 {
+		t.Errorf("total_likes = %d, want 2", stats.TotalLikes)
+	}
+	if stats.TotalComments != 1 {
+		t.Errorf("total_comments = %d, want 1", stats.TotalComments)
+	}
+	if stats.Readers != 2 {
+		t.Errorf("readers = %d, want 2", stats.Readers)
+	}
+}
@@ -36,15 +36,65 @@ func setupTestDB(t *testing.T) *sql.DB {
 			address TEXT UNIQUE NOT NULL,
 			system TEXT NOT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			display_name TEXT NOT NULL DEFAULT '',
+			hide_likes INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE TABLE series (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			slug TEXT UNIQUE NOT NULL,
+			title TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
 		CREATE TABLE posts (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			path_identifier TEXT UNIQUE NOT NULL,
 			like_count INTEGER NOT NULL DEFAULT 0,
+			series_id INTEGER REFERENCES series(id),
+			series_position INTEGER,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
+		CREATE TABLE post_metadata (
+			post_id INTEGER PRIMARY KEY,
+			title TEXT NOT NULL DEFAULT '',
+			locale TEXT NOT NULL DEFAULT '',
+			published_at TIMESTAMP,
+			summary TEXT NOT NULL DEFAULT '',
+			updated_at TIMESTAMP,
+			reading_time INTEGER NOT NULL DEFAULT 0,
+			FOREIGN KEY (post_id) REFERENCES posts(id)
+		);
+		CREATE TABLE post_tags (
+			post_id INTEGER NOT NULL,
+			tag TEXT NOT NULL,
+			PRIMARY KEY (post_id, tag),
+			FOREIGN KEY (post_id) REFERENCES posts(id)
+		);
+		CREATE TABLE post_authors (
+			post_id INTEGER NOT NULL,
+			wallet_id INTEGER NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (post_id, wallet_id),
+			FOREIGN KEY (post_id) REFERENCES posts(id),
+			FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+		);
+		CREATE TABLE post_locales (
+			post_id INTEGER NOT NULL,
+			locale TEXT NOT NULL,
+			title TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (post_id, locale),
+			FOREIGN KEY (post_id) REFERENCES posts(id)
+		);
+		CREATE TABLE post_aliases (
+			path_identifier TEXT PRIMARY KEY,
+			post_id INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (post_id) REFERENCES posts(id)
+		);
 		CREATE TABLE post_likes (
 			post_id INTEGER NOT NULL,
 			wallet_id INTEGER NOT NULL,
@@ -53,17 +103,202 @@ func setupTestDB(t *testing.T) *sql.DB {
 			FOREIGN KEY (post_id) REFERENCES posts(id),
 			FOREIGN KEY (wallet_id) REFERENCES wallets(id)
 		);
+		CREATE TABLE post_reactions (
+			post_id INTEGER NOT NULL,
+			wallet_id INTEGER NOT NULL,
+			reaction TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (post_id, wallet_id, reaction),
+			FOREIGN KEY (post_id) REFERENCES posts(id),
+			FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+		);
+		CREATE TABLE post_reaction_counts (
+			post_id INTEGER NOT NULL,
+			reaction TEXT NOT NULL,
+			count INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (post_id, reaction),
+			FOREIGN KEY (post_id) REFERENCES posts(id)
+		);
+		CREATE TABLE idempotency_keys (
+			wallet_id INTEGER NOT NULL,
+			key TEXT NOT NULL,
+			fingerprint TEXT NOT NULL,
+			status_code INTEGER NOT NULL DEFAULT 0,
+			response BLOB,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (wallet_id, key),
+			FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+		);
+		CREATE TABLE integrity_reports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			checked_at TIMESTAMP NOT NULL,
+			fixed INTEGER NOT NULL DEFAULT 0,
+			discrepancies INTEGER NOT NULL DEFAULT 0,
+			report TEXT NOT NULL
+		);
+		CREATE TABLE post_like_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			post_id INTEGER NOT NULL,
+			wallet_id INTEGER NOT NULL,
+			kind TEXT NOT NULL CHECK (kind IN ('like', 'unlike')),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (post_id) REFERENCES posts(id),
+			FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+		);
+		CREATE TABLE post_engagement_rollups (
+			post_id INTEGER NOT NULL,
+			interval TEXT NOT NULL,
+			bucket TEXT NOT NULL,
+			likes INTEGER NOT NULL DEFAULT 0,
+			unlikes INTEGER NOT NULL DEFAULT 0,
+			comments INTEGER NOT NULL DEFAULT 0,
+			engaged_wallets INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (post_id, interval, bucket),
+			FOREIGN KEY (post_id) REFERENCES posts(id)
+		);
+		CREATE TABLE series_engagement_rollups (
+			series_id INTEGER NOT NULL,
+			interval TEXT NOT NULL,
+			bucket TEXT NOT NULL,
+			likes INTEGER NOT NULL DEFAULT 0,
+			unlikes INTEGER NOT NULL DEFAULT 0,
+			comments INTEGER NOT NULL DEFAULT 0,
+			engaged_wallets INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (series_id, interval, bucket),
+			FOREIGN KEY (series_id) REFERENCES series(id)
+		);
+		CREATE TABLE post_related (
+			post_id INTEGER NOT NULL,
+			related_post_id INTEGER NOT NULL,
+			rank INTEGER NOT NULL,
+			score REAL NOT NULL,
+			support INTEGER NOT NULL,
+			PRIMARY KEY (post_id, related_post_id),
+			FOREIGN KEY (post_id) REFERENCES posts(id),
+			FOREIGN KEY (related_post_id) REFERENCES posts(id)
+		);
+		CREATE TABLE view_salts (
+			day TEXT PRIMARY KEY,
+			salt BLOB NOT NULL
+		);
+		CREATE TABLE post_view_visitors (
+			post_id INTEGER NOT NULL,
+			day TEXT NOT NULL,
+			visitor_hash TEXT NOT NULL,
+			PRIMARY KEY (post_id, day, visitor_hash),
+			FOREIGN KEY (post_id) REFERENCES posts(id)
+		);
+		CREATE TABLE post_daily_views (
+			post_id INTEGER NOT NULL,
+			day TEXT NOT NULL,
+			views INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (post_id, day),
+			FOREIGN KEY (post_id) REFERENCES posts(id)
+		);
+		CREATE TABLE reading_progress (
+			wallet_id INTEGER NOT NULL,
+			post_id INTEGER NOT NULL,
+			position REAL NOT NULL DEFAULT 0,
+			section TEXT NOT NULL DEFAULT '',
+			completed_at TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (wallet_id, post_id),
+			FOREIGN KEY (wallet_id) REFERENCES wallets(id),
+			FOREIGN KEY (post_id) REFERENCES posts(id)
+		);
+		CREATE TABLE bookmarks (
+			wallet_id INTEGER NOT NULL,
+			post_id INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (wallet_id, post_id)
+		);
+		CREATE TABLE reading_list_items (
+			list_id INTEGER NOT NULL,
+			post_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (list_id, post_id)
+		);
+		CREATE TABLE notifications (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			wallet_id INTEGER NOT NULL,
+			kind TEXT NOT NULL,
+			actor_id INTEGER NOT NULL,
+			comment_id INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			read_at TIMESTAMP,
+			UNIQUE (wallet_id, kind, actor_id, comment_id)
+		);
+		CREATE TABLE comment_mentions (
+			comment_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			length INTEGER NOT NULL,
+			wallet_id INTEGER NOT NULL,
+			PRIMARY KEY (comment_id, position)
+		);
+		CREATE TABLE comment_votes (
+			comment_id INTEGER NOT NULL,
+			wallet_id INTEGER NOT NULL,
+			value INTEGER NOT NULL CHECK (value IN (-1, 1)),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (comment_id, wallet_id)
+		);
+		CREATE TABLE comment_reports (
+			comment_id INTEGER NOT NULL,
+			wallet_id INTEGER NOT NULL,
+			reason TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			resolved_at TIMESTAMP,
+			PRIMARY KEY (comment_id, wallet_id)
+		);
+		CREATE TABLE comment_moderation_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			comment_id INTEGER NOT NULL,
+			author_id INTEGER NOT NULL,
+			moderator_id INTEGER,
+			action TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE comment_bans (
+			wallet_id INTEGER PRIMARY KEY,
+			moderator_id INTEGER NOT NULL,
+			reason TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
 		CREATE TABLE comments (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			post_id INTEGER NOT NULL,
 			wallet_id INTEGER NOT NULL,
 			parent_id INTEGER,
 			body TEXT NOT NULL,
+			locale TEXT NOT NULL DEFAULT '',
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			revision INTEGER NOT NULL DEFAULT 1,
+			edited_at TIMESTAMP,
+			deleted_at TIMESTAMP,
+			deleted_by TEXT,
+			body_html TEXT NOT NULL DEFAULT '',
+			render_version INTEGER NOT NULL DEFAULT 0,
+			upvotes INTEGER NOT NULL DEFAULT 0,
+			downvotes INTEGER NOT NULL DEFAULT 0,
+			score REAL NOT NULL DEFAULT 0,
+			status TEXT NOT NULL DEFAULT 'visible',
 			FOREIGN KEY (post_id) REFERENCES posts(id),
 			FOREIGN KEY (wallet_id) REFERENCES wallets(id),
 			FOREIGN KEY (parent_id) REFERENCES comments(id)
 		);
+		CREATE TABLE comment_revisions (
+			comment_id INTEGER NOT NULL,
+			revision INTEGER NOT NULL,
+			body TEXT NOT NULL,
+			body_html TEXT NOT NULL DEFAULT '',
+			render_version INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (comment_id, revision),
+			FOREIGN KEY (comment_id) REFERENCES comments(id)
+		);
 	`)
 	if err != nil {
 		t.Fatal(err)
@@ -97,14 +332,30 @@ func insertTestPost(t *testing.T, db *sql.DB, path string) int {
 	return int(id)
 }
 
-func setupRouter(t *testing.T, db *sql.DB) *mux.Router {
+func setupRouter(t *testing.T, db *sql.DB, admins ...string) *mux.Router {
 	t.Helper()
 	router := mux.NewRouter()
 	ws := walletsvc.NewWalletService(db)
-	auth := walletmw.NewAuthMiddleware(ws)
+	auth := walletmw.NewAuthMiddleware(ws, admins)
 	ps := services.NewPostService(db)
+	ps.SetSupportedLocales([]string{"en", "es"})
+	ps.SetReactions([]string{"👍", "🤯", "🧠", "❓"})
+	ss := services.NewSeriesService(db)
 	cs := services.NewCommentService(db)
-	handlers.RegisterRoutes(router, ps, cs, auth)
+	cs.SetEditWindow(15 * time.Minute)
+	cs.SetReportThreshold(3)
+	handlers.RegisterRoutes(router, handlers.Services{
+		Posts:       ps,
+		Comments:    cs,
+		Series:      ss,
+		Sync:        services.NewSyncService(db, "", ""),
+		Idempotency: services.NewIdempotencyService(db),
+		Reconcile:   services.NewReconcileService(db),
+		Engagement:  services.NewEngagementService(db),
+		Trending:    services.NewTrendingService(ps, 24*time.Hour, time.Minute),
+		Related:     services.NewRelatedService(ps, 2, 2),
+		Views:       services.NewViewService(db),
+	}, auth)
 	return router
 }
 
@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"arkana/features/posts/models"
+	"arkana/features/posts/services"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseManifests(t *testing.T) {
+	t.Run("parses a JSON manifest with plain dates", func(t *testing.T) {
+		manifest, err := services.ParseJSONManifest([]byte(`{
+			"posts": [{"path": "/cryptography-101/hashing/", "title": "Hashing", "tags": ["Crypto"], "published_at": "2024-03-25"}],
+			"series": [{"slug": "cryptography-101", "title": "Cryptography 101"}]
+		}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(manifest.Posts) != 1 || len(manifest.Series) != 1 {
+			t.Fatalf("posts = %d, series = %d, want 1 and 1", len(manifest.Posts), len(manifest.Series))
+		}
+		entry := manifest.Posts[0]
+		if entry.Path != "cryptography-101/hashing" {
+			t.Errorf("path = %q, want %q", entry.Path, "cryptography-101/hashing")
+		}
+		if entry.PublishedAt == nil || entry.PublishedAt.Format("2006-01-02") != "2024-03-25" {
+			t.Errorf("published_at = %v, want 2024-03-25", entry.PublishedAt)
+		}
+	})
+
+	t.Run("splits locale segments off JSON paths", func(t *testing.T) {
+		manifest, err := services.ParseJSONManifest([]byte(`[{"path": "es/wtf-is/risc-v"}, {"path": "en/wtf-is/dns", "locale": "en-GB"}]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e := manifest.Posts[0]; e.Path != "wtf-is/risc-v" || e.Locale != "es" {
+			t.Errorf("entry = %+v, want the canonical path in es", e)
+		}
+		if e := manifest.Posts[1]; e.Path != "wtf-is/dns" || e.Locale != "en-GB" {
+			t.Errorf("entry = %+v, want the canonical path in en-GB", e)
+		}
+	})
+
+	t.Run("rejects entries without path", func(t *testing.T) {
+		if _, err := services.ParseJSONManifest([]byte(`[{"title": "x"}]`)); err == nil {
+			t.Error("expected error for entry without path")
+		}
+	})
+
+	t.Run("parses a sitemap", func(t *testing.T) {
+		manifest, err := services.ParseSitemap([]byte(`<?xml version="1.0"?>
+			<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+				<url><loc>https://arkana.blog/en/blog/wtf-is/risc-v</loc></url>
+				<url><loc>https://arkana.blog/es/blog/wtf-is/risc-v</loc></url>
+				<url><loc>https://arkana.blog/en/about</loc></url>
+			</urlset>`), "blog/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(manifest.Posts) != 2 {
+			t.Fatalf("posts = %d, want 2", len(manifest.Posts))
+		}
+		if manifest.Posts[0].Path != "wtf-is/risc-v" || manifest.Posts[0].Locale != "en" {
+			t.Errorf("entry = %+v, want wtf-is/risc-v in en", manifest.Posts[0])
+		}
+	})
+
+	t.Run("loads a content directory with front-matter", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "en", "wtf-is", "risc-v", "index.md"), `---
+title: "WTF is RISC-V"
+date: 2025-01-10
+tags:
+  - hardware
+  - risc-v
+---
+Body`)
+		writeFile(t, filepath.Join(dir, "en", "wtf-is", "draft.md"), "---\ntitle: Draft\ndraft: true\n---\n")
+
+		manifest, err := services.LoadContentDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(manifest.Posts) != 1 {
+			t.Fatalf("posts = %d, want 1", len(manifest.Posts))
+		}
+		entry := manifest.Posts[0]
+		if entry.Path != "wtf-is/risc-v" || entry.Locale != "en" || entry.Title != "WTF is RISC-V" {
+			t.Errorf("entry = %+v", entry)
+		}
+		if !slices.Equal(entry.Tags, []string{"hardware", "risc-v"}) {
+			t.Errorf("tags = %v, want [hardware risc-v]", entry.Tags)
+		}
+	})
+}
+
+func TestSyncManifest(t *testing.T) {
+	db := setupTestDB(t)
+	seriesSvc := services.NewSeriesService(db)
+	postSvc := services.NewPostService(db)
+	syncSvc := services.NewSyncService(db, "", "")
+
+	insertTestPost(t, db, "wtf-is/old-post")
+
+	manifest, _ := services.ParseJSONManifest([]byte(`{
+		"posts": [
+			{"path": "wtf-is/risc-v", "title": "RISC-V", "tags": ["hardware"]},
+			{"path": "wtf-is/the-internet"}
+		],
+		"series": [{"slug": "wtf-is", "title": "WTF is", "parts": ["wtf-is/the-internet", "wtf-is/risc-v"]}]
+	}`))
+
+	t.Run("dry run reports without writing", func(t *testing.T) {
+		report, err := syncSvc.Sync(manifest, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Added) != 2 {
+			t.Errorf("added = %v, want 2 entries", report.Added)
+		}
+		if !slices.Equal(report.Orphaned, []string{"wtf-is/old-post"}) {
+			t.Errorf("orphaned = %v, want [wtf-is/old-post]", report.Orphaned)
+		}
+		if _, err := postSvc.GetByPath("wtf-is/risc-v"); err != services.ErrPostNotFound {
+			t.Errorf("err = %v, want ErrPostNotFound after dry run", err)
+		}
+	})
+
+	t.Run("applies posts and series", func(t *testing.T) {
+		if _, err := syncSvc.Sync(manifest, false); err != nil {
+			t.Fatal(err)
+		}
+
+		detail, err := seriesSvc.GetDetail("wtf-is")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if detail.Title != "WTF is" {
+			t.Errorf("title = %q, want %q", detail.Title, "WTF is")
+		}
+		if len(detail.Parts) != 2 || detail.Parts[0].Path != "wtf-is/the-internet" {
+			t.Errorf("parts = %+v, want the-internet first", detail.Parts)
+		}
+	})
+
+	t.Run("second run is unchanged", func(t *testing.T) {
+		report, err := syncSvc.Sync(manifest, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Added) != 0 || len(report.Changed) != 0 || report.Unchanged != 2 {
+			t.Errorf("report = %+v, want 2 unchanged", report)
+		}
+	})
+
+	t.Run("reports changed fields", func(t *testing.T) {
+		changed, _ := services.ParseJSONManifest([]byte(`[{"path": "wtf-is/risc-v", "title": "RISC-V explained", "tags": ["hardware"]}]`))
+		report, err := syncSvc.Sync(changed, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Changed) != 1 || !slices.Equal(report.Changed[0].Fields, []string{"title"}) {
+			t.Errorf("changed = %+v, want title only", report.Changed)
+		}
+	})
+
+	t.Run("rejects series parts that are not posts of the series", func(t *testing.T) {
+		broken, _ := services.ParseJSONManifest([]byte(`{
+			"posts": [{"path": "wtf-is/dns"}],
+			"series": [{"slug": "wtf-is", "parts": ["wtf-is/dns", "wtf-is/missing"]}]
+		}`))
+		report, err := syncSvc.Sync(broken, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []models.SyncSeriesPart{{Series: "wtf-is", Path: "wtf-is/missing"}}
+		if !slices.Equal(report.InvalidParts, want) {
+			t.Errorf("dry run invalid parts = %+v, want %+v", report.InvalidParts, want)
+		}
+
+		if _, err := syncSvc.Sync(broken, false); !errors.Is(err, services.ErrInvalidSeriesPart) {
+			t.Fatalf("err = %v, want ErrInvalidSeriesPart", err)
+		}
+		if _, err := postSvc.GetByPath("wtf-is/dns"); err != services.ErrPostNotFound {
+			t.Errorf("err = %v, want the post insert rolled back", err)
+		}
+	})
+
+	t.Run("reports former paths of renamed posts as conflicts", func(t *testing.T) {
+		if _, err := postSvc.Rename("wtf-is/old-post", "wtf-is/new-post"); err != nil {
+			t.Fatal(err)
+		}
+		stale, _ := services.ParseJSONManifest([]byte(`[{"path": "wtf-is/old-post", "title": "Old"}]`))
+		report, err := syncSvc.Sync(stale, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []models.SyncConflict{{Path: "wtf-is/old-post", Post: "wtf-is/new-post"}}
+		if !slices.Equal(report.Conflicts, want) || len(report.Added) != 0 {
+			t.Errorf("report = %+v, want the alias as a conflict", report)
+		}
+		if post, _ := postSvc.GetByPath("wtf-is/old-post"); post == nil || post.PathIdentifier != "wtf-is/new-post" {
+			t.Errorf("post = %+v, want the alias to resolve to the renamed post", post)
+		}
+	})
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyncTranslations(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	postSvc.SetSupportedLocales([]string{"en", "es"})
+	syncSvc := services.NewSyncService(db, "", "")
+
+	manifest, _ := services.ParseJSONManifest([]byte(`[
+		{"path": "wtf-is/risc-v", "locale": "en", "title": "WTF is RISC-V"},
+		{"path": "wtf-is/risc-v", "locale": "es", "title": "Qué es RISC-V"}
+	]`))
+
+	report, err := syncSvc.Sync(manifest, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Added) != 1 {
+		t.Fatalf("added = %v, want one post", report.Added)
+	}
+
+	info, err := postSvc.GetPostInfo("es/wtf-is/risc-v", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Title != "Qué es RISC-V" {
+		t.Errorf("title = %q, want the Spanish title", info.Title)
+	}
+	if !slices.Equal(info.Locales, []string{"en", "es"}) {
+		t.Errorf("locales = %v, want [en es]", info.Locales)
+	}
+
+	report, err = syncSvc.Sync(manifest, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Unchanged != 1 {
+		t.Errorf("report = %+v, want unchanged on second run", report)
+	}
+}
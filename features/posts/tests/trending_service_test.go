@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"arkana/features/posts/services"
+	"testing"
+	"time"
+)
+
+func TestTrending(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	svc := services.NewTrendingService(postSvc, 24*time.Hour, time.Hour)
+	w1 := insertTestWallet(t, db, "0xabc")
+	w2 := insertTestWallet(t, db, "0xdef")
+
+	fresh, _ := postSvc.GetOrCreateByPath("wtf-is/risc-v")
+	stale, _ := postSvc.GetOrCreateByPath("wtf-is/the-internet")
+	old, _ := postSvc.GetOrCreateByPath("blockchain-101/genesis")
+
+	ago := func(d time.Duration) string {
+		return time.Now().UTC().Add(-d).Format("2006-01-02 15:04:05")
+	}
+	// Two likes three days ago decay below one like an hour ago
+	db.Exec("INSERT INTO post_likes (post_id, wallet_id, created_at) VALUES (?, ?, ?)", fresh.ID, w1, ago(time.Hour))
+	db.Exec("INSERT INTO post_likes (post_id, wallet_id, created_at) VALUES (?, ?, ?)", stale.ID, w1, ago(72*time.Hour))
+	db.Exec("INSERT INTO post_likes (post_id, wallet_id, created_at) VALUES (?, ?, ?)", stale.ID, w2, ago(72*time.Hour))
+	db.Exec("INSERT INTO post_likes (post_id, wallet_id, created_at) VALUES (?, ?, ?)", old.ID, w1, ago(20*24*time.Hour))
+
+	t.Run("ranks recent engagement first", func(t *testing.T) {
+		resp, err := svc.Trending(7*24*time.Hour, "", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Window != "7d" {
+			t.Errorf("window = %q, want 7d", resp.Window)
+		}
+		if len(resp.Posts) != 2 {
+			t.Fatalf("posts = %v, want 2 inside the window", resp.Posts)
+		}
+		if resp.Posts[0].Path != "wtf-is/risc-v" || resp.Posts[1].RecentLikes != 2 {
+			t.Errorf("posts = %+v, want the fresh post first", resp.Posts)
+		}
+	})
+
+	t.Run("filters by prefix", func(t *testing.T) {
+		resp, err := svc.Trending(30*24*time.Hour, "blockchain-101/", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Posts) != 1 || resp.Posts[0].Path != "blockchain-101/genesis" {
+			t.Errorf("posts = %+v, want only blockchain-101", resp.Posts)
+		}
+	})
+
+	t.Run("serves the cached ranking until refreshed", func(t *testing.T) {
+		db.Exec("INSERT INTO post_likes (post_id, wallet_id, created_at) VALUES (?, ?, ?)", old.ID, w2, ago(time.Minute))
+
+		resp, _ := svc.Trending(7*24*time.Hour, "", 10)
+		if len(resp.Posts) != 2 {
+			t.Errorf("posts = %d, want the cached 2", len(resp.Posts))
+		}
+
+		if err := svc.Refresh(); err != nil {
+			t.Fatal(err)
+		}
+		resp, _ = svc.Trending(7*24*time.Hour, "", 10)
+		if len(resp.Posts) != 3 {
+			t.Errorf("posts = %d, want 3 after refresh", len(resp.Posts))
+		}
+	})
+
+	t.Run("ignores hidden and deleted comments", func(t *testing.T) {
+		moderated, _ := postSvc.GetOrCreateByPath("wtf-is/moderation")
+		db.Exec("INSERT INTO comments (post_id, wallet_id, body, status) VALUES (?, ?, 'spam', 'hidden')", moderated.ID, w1)
+		db.Exec("INSERT INTO comments (post_id, wallet_id, body, deleted_at) VALUES (?, ?, '', CURRENT_TIMESTAMP)", moderated.ID, w2)
+
+		if err := svc.Refresh(); err != nil {
+			t.Fatal(err)
+		}
+		resp, _ := svc.Trending(24*time.Hour, "wtf-is/moderation", 10)
+		if len(resp.Posts) != 0 {
+			t.Errorf("posts = %+v, want none", resp.Posts)
+		}
+	})
+
+	t.Run("rejects unsupported windows", func(t *testing.T) {
+		if _, err := svc.Trending(36*time.Hour, "", 10); err != services.ErrInvalidWindow {
+			t.Errorf("err = %v, want ErrInvalidWindow", err)
+		}
+	})
+}
+
+func TestParseWindow(t *testing.T) {
+	for input, want := range map[string]time.Duration{"24h": 24 * time.Hour, "7d": 7 * 24 * time.Hour, "30d": 30 * 24 * time.Hour} {
+		got, err := services.ParseWindow(input)
+		if err != nil || got != want {
+			t.Errorf("ParseWindow(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "0d", "-1h", "week", "36h", "1d", "90d"} {
+		if _, err := services.ParseWindow(input); err != services.ErrInvalidWindow {
+			t.Errorf("ParseWindow(%q) err = %v, want ErrInvalidWindow", input, err)
+		}
+	}
+}
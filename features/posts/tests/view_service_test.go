@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"arkana/features/posts/services"
+	"testing"
+	"time"
+)
+
+const browserUA = "Mozilla/5.0 (X11; Linux x86_64) Firefox/131.0"
+
+func TestRecordView(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	svc := services.NewViewService(db)
+	postID := insertTestPost(t, db, "test-post")
+
+	t.Run("counts each visitor once per day", func(t *testing.T) {
+		for _, ip := range []string{"203.0.113.1", "203.0.113.1", "203.0.113.2"} {
+			if _, err := svc.Record(postID, ip, browserUA); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		info, _ := postSvc.GetPostInfo("test-post", "", "")
+		if info.ViewCount != 2 {
+			t.Errorf("view_count = %d, want 2", info.ViewCount)
+		}
+	})
+
+	t.Run("ignores bots", func(t *testing.T) {
+		for _, ua := range []string{"", "Googlebot/2.1", "curl/8.4.0", "Slackbot-LinkExpanding 1.0"} {
+			counted, err := svc.Record(postID, "203.0.113.3", ua)
+			if err != nil || counted {
+				t.Errorf("Record(%q) = %v, %v, want not counted", ua, counted, err)
+			}
+		}
+	})
+
+	t.Run("stores no raw identifiers", func(t *testing.T) {
+		var n int
+		db.QueryRow("SELECT COUNT(*) FROM post_view_visitors WHERE visitor_hash LIKE '%203.0.113%'").Scan(&n)
+		if n != 0 {
+			t.Errorf("found %d visitor rows containing the IP", n)
+		}
+	})
+
+	t.Run("rotation discards older salts and hashes", func(t *testing.T) {
+		yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+		db.Exec("INSERT INTO view_salts (day, salt) VALUES (?, x'00')", yesterday)
+		db.Exec("INSERT INTO post_view_visitors (post_id, day, visitor_hash) VALUES (?, ?, 'old')", postID, yesterday)
+		db.Exec("INSERT INTO post_daily_views (post_id, day, views) VALUES (?, ?, 5)", postID, yesterday)
+
+		// A fresh service rotates on first use, as after a restart at midnight
+		if err := services.NewViewService(db).Rotate(); err != nil {
+			t.Fatal(err)
+		}
+
+		var salts, hashes int
+		db.QueryRow("SELECT COUNT(*) FROM view_salts WHERE day = ?", yesterday).Scan(&salts)
+		db.QueryRow("SELECT COUNT(*) FROM post_view_visitors WHERE day = ?", yesterday).Scan(&hashes)
+		if salts != 0 || hashes != 0 {
+			t.Errorf("salts = %d, hashes = %d, want none from yesterday", salts, hashes)
+		}
+
+		info, _ := postSvc.GetPostInfo("test-post", "", "")
+		if info.ViewCount != 7 {
+			t.Errorf("view_count = %d, want counters kept", info.ViewCount)
+		}
+	})
+}
+
+func TestViewStats(t *testing.T) {
+	db := setupTestDB(t)
+	postSvc := services.NewPostService(db)
+	svc := services.NewEngagementService(db)
+	first, _ := postSvc.GetOrCreateByPath("blockchain-101/genesis")
+	second, _ := postSvc.GetOrCreateByPath("blockchain-101/blocks")
+
+	db.Exec("INSERT INTO post_daily_views (post_id, day, views) VALUES (?, '2026-03-02', 3)", first.ID)
+	db.Exec("INSERT INTO post_daily_views (post_id, day, views) VALUES (?, '2026-03-04', 2)", first.ID)
+	db.Exec("INSERT INTO post_daily_views (post_id, day, views) VALUES (?, '2026-03-04', 4)", second.ID)
+
+	from, _ := time.Parse("2006-01-02", "2026-03-02")
+	to, _ := time.Parse("2006-01-02", "2026-03-08")
+
+	stats, err := svc.PostStats(first.ID, from, to, "day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Points[0].Views != 3 || stats.Points[1].Views != 0 || stats.Points[2].Views != 2 {
+		t.Errorf("points = %+v", stats.Points)
+	}
+
+	var seriesID int
+	db.QueryRow("SELECT series_id FROM posts WHERE id = ?", first.ID).Scan(&seriesID)
+	stats, err = svc.SeriesStats(seriesID, from, to, "week")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Points) != 1 || stats.Points[0].Views != 9 {
+		t.Errorf("points = %+v, want 9 views in one week", stats.Points)
+	}
+}
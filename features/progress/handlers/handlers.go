@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	postservices "arkana/features/posts/services"
+	"arkana/features/progress/services"
+	"arkana/features/wallet/middlewares"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterRoutes(router *mux.Router, ps *postservices.PostService, progress *services.ProgressService, auth *middlewares.AuthMiddleware) {
+	progressHandler := NewProgressHandler(ps, progress)
+
+	router.Handle("/api/me/progress", auth.RequireAuth(http.HandlerFunc(progressHandler.GetSummary))).Methods("GET", "OPTIONS")
+	router.Handle("/api/posts/{path:.*}/progress", auth.RequireAuth(http.HandlerFunc(progressHandler.GetProgress))).Methods("GET", "OPTIONS")
+	router.Handle("/api/posts/{path:.*}/progress", auth.RequireAuth(http.HandlerFunc(progressHandler.RecordProgress))).Methods("PUT")
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	postmodels "arkana/features/posts/models"
+	postservices "arkana/features/posts/services"
+	"arkana/features/progress/services"
+	"arkana/features/wallet/middlewares"
+	"arkana/shared/httputil"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type ProgressHandler struct {
+	postService     *postservices.PostService
+	progressService *services.ProgressService
+}
+
+func NewProgressHandler(ps *postservices.PostService, progress *services.ProgressService) *ProgressHandler {
+	return &ProgressHandler{postService: ps, progressService: progress}
+}
+
+// RecordProgress handles PUT /api/posts/{path}/progress, signed with the
+// SAVE_PROGRESS action.
+func (h *ProgressHandler) RecordProgress(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "SAVE_PROGRESS" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		Position  *float64 `json:"position"`
+		Section   string   `json:"section,omitempty"`
+		Completed bool     `json:"completed,omitempty"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	if payload.Position == nil {
+		httputil.WriteError(w, http.StatusBadRequest, "missing position")
+		return
+	}
+
+	post, ok := h.resolvePost(w, r, h.postService.GetOrAutoCreate)
+	if !ok {
+		return
+	}
+
+	progress, err := h.progressService.Record(vr.WalletID, post.ID, services.ProgressUpdate{
+		Position:  *payload.Position,
+		Section:   payload.Section,
+		Completed: payload.Completed,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPosition):
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrSectionTooLong):
+			httputil.WriteError(w, http.StatusBadRequest, fmt.Sprintf("section exceeds maximum length of %d characters", services.MaxSectionLength))
+		default:
+			log.Printf("[Progress] Failed to record progress of wallet %d on post %d: %v", vr.WalletID, post.ID, err)
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to record progress")
+		}
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, progress)
+}
+
+// GetProgress handles GET /api/posts/{path}/progress, signed with the
+// GET_PROGRESS action.
+func (h *ProgressHandler) GetProgress(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "GET_PROGRESS" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	post, ok := h.resolvePost(w, r, h.postService.GetByPath)
+	if !ok {
+		return
+	}
+
+	progress, err := h.progressService.Get(vr.WalletID, post.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrNoProgress) {
+			httputil.WriteError(w, http.StatusNotFound, "no progress recorded")
+			return
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to get progress")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, progress)
+}
+
+// GetSummary handles GET /api/me/progress, signed with the GET_PROGRESS
+// action.
+func (h *ProgressHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if vr.Action != "GET_PROGRESS" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	summary, err := h.progressService.Summary(vr.WalletID)
+	if err != nil {
+		log.Printf("[Progress] Failed to summarize progress of wallet %d: %v", vr.WalletID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to get progress")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, summary)
+}
+
+// resolvePost looks up the post of the request path with find, writing an
+// error response and returning false when it cannot be resolved.
+func (h *ProgressHandler) resolvePost(w http.ResponseWriter, r *http.Request, find func(string) (*postmodels.Post, error)) (*postmodels.Post, bool) {
+	post, err := find(mux.Vars(r)["path"])
+	if err != nil {
+		if errors.Is(err, postservices.ErrPostNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "post not found")
+			return nil, false
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to resolve post")
+		return nil, false
+	}
+	return post, true
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// PostProgress is how far a wallet has read a post. Position is the
+// fraction of the post scrolled through, from 0 to 1, and Section the
+// anchor of the last heading reached.
+type PostProgress struct {
+	Path        string     `json:"path"`
+	Position    float64    `json:"position"`
+	Section     string     `json:"section,omitempty"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// SeriesProgress summarizes a wallet's reading of a series. LastRead is the
+// part read most recently and NextPath the first part not yet completed,
+// empty once the series is finished.
+type SeriesProgress struct {
+	Slug           string       `json:"slug"`
+	Title          string       `json:"title"`
+	TotalParts     int          `json:"total_parts"`
+	CompletedParts int          `json:"completed_parts"`
+	Percent        int          `json:"percent"`
+	LastRead       PostProgress `json:"last_read"`
+	NextPath       string       `json:"next_path,omitempty"`
+}
+
+// ProgressSummary lists the series a wallet has started, most recently
+// read first, and its progress on posts outside any series.
+type ProgressSummary struct {
+	Series []SeriesProgress `json:"series"`
+	Posts  []PostProgress   `json:"posts"`
+}
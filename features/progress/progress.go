@@ -0,0 +1,17 @@
+package progress
+
+import (
+	postservices "arkana/features/posts/services"
+	"arkana/features/progress/handlers"
+	"arkana/features/progress/services"
+	"arkana/features/wallet/middlewares"
+	"database/sql"
+
+	"github.com/gorilla/mux"
+)
+
+func Initialize(router *mux.Router, db *sql.DB, ps *postservices.PostService, auth *middlewares.AuthMiddleware) {
+	progressService := services.NewProgressService(db)
+
+	handlers.RegisterRoutes(router, ps, progressService, auth)
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"arkana/features/progress/models"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+)
+
+// CompletionThreshold is the position from which a post counts as read.
+// Footers and comments mean readers rarely scroll to the very end.
+const CompletionThreshold = 0.9
+
+// MaxSectionLength bounds the section anchor stored with progress.
+const MaxSectionLength = 200
+
+var (
+	ErrNoProgress      = errors.New("no progress recorded")
+	ErrInvalidPosition = errors.New("position must be between 0 and 1")
+	ErrSectionTooLong  = errors.New("section too long")
+)
+
+// ProgressUpdate is a progress report from a reader's device.
+type ProgressUpdate struct {
+	Position  float64
+	Section   string
+	Completed bool
+}
+
+// ProgressService stores reading progress per wallet and post so readers
+// can resume across devices.
+type ProgressService struct {
+	db *sql.DB
+}
+
+func NewProgressService(db *sql.DB) *ProgressService {
+	return &ProgressService{db: db}
+}
+
+// Record stores the latest progress of a wallet on a post. A post is
+// completed when the update says so or reaches CompletionThreshold, and
+// stays completed when it is read again.
+func (s *ProgressService) Record(walletID, postID int, update ProgressUpdate) (*models.PostProgress, error) {
+	if math.IsNaN(update.Position) || update.Position < 0 || update.Position > 1 {
+		return nil, ErrInvalidPosition
+	}
+	if len(update.Section) > MaxSectionLength {
+		return nil, ErrSectionTooLong
+	}
+
+	now := time.Now().UTC()
+	var completedAt *time.Time
+	if update.Completed || update.Position >= CompletionThreshold {
+		completedAt = &now
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO reading_progress (wallet_id, post_id, position, section, completed_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(wallet_id, post_id) DO UPDATE SET
+			position = excluded.position,
+			section = excluded.section,
+			completed_at = COALESCE(completed_at, excluded.completed_at),
+			updated_at = excluded.updated_at
+	`, walletID, postID, update.Position, update.Section, completedAt, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(walletID, postID)
+}
+
+// Get returns the progress of a wallet on a post.
+// Returns ErrNoProgress if the wallet has not read the post.
+func (s *ProgressService) Get(walletID, postID int) (*models.PostProgress, error) {
+	rows, err := s.query("WHERE r.wallet_id = ? AND r.post_id = ?", walletID, postID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrNoProgress
+	}
+	return &rows[0].PostProgress, nil
+}
+
+// progressRow is a progress entry with the series placement of its post.
+type progressRow struct {
+	models.PostProgress
+	seriesID *int
+}
+
+func (s *ProgressService) query(where string, args ...any) ([]progressRow, error) {
+	rows, err := s.db.Query(`
+		SELECT p.path_identifier, p.series_id, r.position, r.section, r.completed_at, r.updated_at
+		FROM reading_progress r
+		JOIN posts p ON p.id = r.post_id
+		`+where+`
+		ORDER BY r.updated_at DESC, p.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []progressRow
+	for rows.Next() {
+		var r progressRow
+		if err := rows.Scan(&r.Path, &r.seriesID, &r.Position, &r.Section, &r.CompletedAt, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		r.Completed = r.CompletedAt != nil
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// Summary returns a wallet's progress grouped by series, with completion
+// percentages over every part of each series.
+func (s *ProgressService) Summary(walletID int) (*models.ProgressSummary, error) {
+	rows, err := s.query("WHERE r.wallet_id = ?", walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.ProgressSummary{
+		Series: []models.SeriesProgress{},
+		Posts:  []models.PostProgress{},
+	}
+	completed := map[string]bool{}
+	seen := map[int]bool{}
+	var seriesOrder []int
+	lastRead := map[int]models.PostProgress{}
+	for _, r := range rows {
+		completed[r.Path] = r.Completed
+		if r.seriesID == nil {
+			summary.Posts = append(summary.Posts, r.PostProgress)
+			continue
+		}
+		// Rows are most recent first, so the first one of a series was read last
+		if !seen[*r.seriesID] {
+			seen[*r.seriesID] = true
+			seriesOrder = append(seriesOrder, *r.seriesID)
+			lastRead[*r.seriesID] = r.PostProgress
+		}
+	}
+
+	for _, seriesID := range seriesOrder {
+		sp := models.SeriesProgress{LastRead: lastRead[seriesID]}
+		err := s.db.QueryRow("SELECT slug, title FROM series WHERE id = ?", seriesID).Scan(&sp.Slug, &sp.Title)
+		if err != nil {
+			return nil, err
+		}
+
+		parts, err := s.parts(seriesID)
+		if err != nil {
+			return nil, err
+		}
+		sp.TotalParts = len(parts)
+		for _, path := range parts {
+			if completed[path] {
+				sp.CompletedParts++
+			} else if sp.NextPath == "" {
+				sp.NextPath = path
+			}
+		}
+		if sp.TotalParts > 0 {
+			sp.Percent = int(math.Round(float64(sp.CompletedParts) * 100 / float64(sp.TotalParts)))
+		}
+
+		summary.Series = append(summary.Series, sp)
+	}
+
+	return summary, nil
+}
+
+// parts returns the paths of a series in reading order, matching the order
+// of the series endpoints.
+func (s *ProgressService) parts(seriesID int) ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT path_identifier FROM posts
+		WHERE series_id = ?
+		ORDER BY series_position IS NULL, series_position, created_at, id
+	`, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"arkana/features/progress/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProgressHandlers(t *testing.T) {
+	db := setupTestDB(t)
+	router := setupRouter(t, db)
+	key, addr := generateTestKey(t)
+	insertTestWallet(t, db, addr)
+	insertSeries(t, db, "elliptic-curves-in-depth", "groups", "fields")
+
+	put := func(path string, payload map[string]any) *httptest.ResponseRecorder {
+		if payload["action"] == nil {
+			payload["action"] = "SAVE_PROGRESS"
+		}
+		req := httptest.NewRequest("PUT", "/api/posts/"+path+"/progress", strings.NewReader(signJWS(t, key, payload)))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	getAs := func(action, url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set("Authorization", "Bearer "+signJWS(t, key, map[string]any{"action": action}))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	get := func(url string) *httptest.ResponseRecorder { return getAs("GET_PROGRESS", url) }
+
+	t.Run("records progress", func(t *testing.T) {
+		rec := put("elliptic-curves-in-depth/groups", map[string]any{"position": 0.5, "section": "cyclic-groups"})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body: %s", rec.Code, rec.Body.String())
+		}
+
+		rec = get("/api/posts/elliptic-curves-in-depth/groups/progress")
+		var p models.PostProgress
+		json.NewDecoder(rec.Body).Decode(&p)
+		if rec.Code != http.StatusOK || p.Position != 0.5 || p.Section != "cyclic-groups" {
+			t.Errorf("status = %d, progress = %+v", rec.Code, p)
+		}
+	})
+
+	t.Run("summarizes by series", func(t *testing.T) {
+		put("elliptic-curves-in-depth/groups", map[string]any{"position": 1})
+
+		rec := get("/api/me/progress")
+		var summary models.ProgressSummary
+		json.NewDecoder(rec.Body).Decode(&summary)
+		if rec.Code != http.StatusOK || len(summary.Series) != 1 || summary.Series[0].Percent != 50 {
+			t.Errorf("status = %d, summary = %+v", rec.Code, summary)
+		}
+	})
+
+	t.Run("rejects invalid progress", func(t *testing.T) {
+		if rec := put("elliptic-curves-in-depth/groups", map[string]any{}); rec.Code != http.StatusBadRequest {
+			t.Errorf("missing position: status = %d, want 400", rec.Code)
+		}
+		if rec := put("elliptic-curves-in-depth/groups", map[string]any{"position": -1}); rec.Code != http.StatusBadRequest {
+			t.Errorf("negative position: status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("returns 404 for unread and unknown posts", func(t *testing.T) {
+		if rec := get("/api/posts/elliptic-curves-in-depth/fields/progress"); rec.Code != http.StatusNotFound {
+			t.Errorf("unread: status = %d, want 404", rec.Code)
+		}
+		if rec := put("missing", map[string]any{"position": 0.1}); rec.Code != http.StatusNotFound {
+			t.Errorf("unknown: status = %d, want 404", rec.Code)
+		}
+	})
+
+	t.Run("rejects other actions", func(t *testing.T) {
+		if rec := put("elliptic-curves-in-depth/groups", map[string]any{"action": "GET_PROGRESS", "position": 0.2}); rec.Code != http.StatusBadRequest {
+			t.Errorf("record: status = %d, want 400", rec.Code)
+		}
+		if rec := getAs("SAVE_PROGRESS", "/api/me/progress"); rec.Code != http.StatusBadRequest {
+			t.Errorf("summary: status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("requires a signature", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/me/progress", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code == http.StatusOK {
+			t.Errorf("status = %d, want an error", rec.Code)
+		}
+	})
+}
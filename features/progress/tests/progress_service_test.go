@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"arkana/features/progress/services"
+	"errors"
+	"testing"
+)
+
+func TestRecordProgress(t *testing.T) {
+	db := setupTestDB(t)
+	svc := services.NewProgressService(db)
+	walletID := insertTestWallet(t, db, "0xabc")
+	postID := insertSeries(t, db, "elliptic-curves-in-depth", "groups")[0]
+
+	t.Run("returns ErrNoProgress before reading", func(t *testing.T) {
+		if _, err := svc.Get(walletID, postID); !errors.Is(err, services.ErrNoProgress) {
+			t.Errorf("err = %v, want ErrNoProgress", err)
+		}
+	})
+
+	t.Run("stores position and section", func(t *testing.T) {
+		p, err := svc.Record(walletID, postID, services.ProgressUpdate{Position: 0.4, Section: "point-addition"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Position != 0.4 || p.Section != "point-addition" || p.Completed {
+			t.Errorf("progress = %+v", p)
+		}
+	})
+
+	t.Run("completes at the threshold and stays completed", func(t *testing.T) {
+		p, _ := svc.Record(walletID, postID, services.ProgressUpdate{Position: 0.95})
+		if !p.Completed || p.CompletedAt == nil {
+			t.Fatalf("progress = %+v, want completed", p)
+		}
+
+		p, _ = svc.Record(walletID, postID, services.ProgressUpdate{Position: 0.1})
+		if !p.Completed || p.Position != 0.1 {
+			t.Errorf("progress = %+v, want completed at the new position", p)
+		}
+	})
+
+	t.Run("rejects invalid updates", func(t *testing.T) {
+		if _, err := svc.Record(walletID, postID, services.ProgressUpdate{Position: 1.5}); !errors.Is(err, services.ErrInvalidPosition) {
+			t.Errorf("err = %v, want ErrInvalidPosition", err)
+		}
+		long := make([]byte, services.MaxSectionLength+1)
+		if _, err := svc.Record(walletID, postID, services.ProgressUpdate{Section: string(long)}); !errors.Is(err, services.ErrSectionTooLong) {
+			t.Errorf("err = %v, want ErrSectionTooLong", err)
+		}
+	})
+}
+
+func TestProgressSummary(t *testing.T) {
+	db := setupTestDB(t)
+	svc := services.NewProgressService(db)
+	walletID := insertTestWallet(t, db, "0xabc")
+	other := insertTestWallet(t, db, "0xdef")
+
+	curves := insertSeries(t, db, "elliptic-curves-in-depth", "groups", "fields", "curves", "pairings")
+	basics := insertSeries(t, db, "blockchain-101", "genesis")
+	insertSeries(t, db, "unread", "intro")
+	result, _ := db.Exec("INSERT INTO posts (path_identifier) VALUES ('essays/alone')")
+	standalone, _ := result.LastInsertId()
+
+	svc.Record(walletID, basics[0], services.ProgressUpdate{Position: 0.2})
+	svc.Record(walletID, int(standalone), services.ProgressUpdate{Position: 0.5})
+	svc.Record(walletID, curves[0], services.ProgressUpdate{Completed: true})
+	svc.Record(walletID, curves[2], services.ProgressUpdate{Position: 0.3, Section: "weierstrass"})
+	svc.Record(other, curves[1], services.ProgressUpdate{Position: 1})
+
+	summary, err := svc.Summary(walletID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(summary.Series) != 2 {
+		t.Fatalf("series = %+v, want the two started series", summary.Series)
+	}
+	s := summary.Series[0]
+	if s.Slug != "elliptic-curves-in-depth" || s.TotalParts != 4 || s.CompletedParts != 1 || s.Percent != 25 {
+		t.Errorf("series = %+v, want 1 of 4 parts of the most recent series", s)
+	}
+	if s.LastRead.Path != "elliptic-curves-in-depth/curves" || s.LastRead.Section != "weierstrass" {
+		t.Errorf("last_read = %+v, want the curves part", s.LastRead)
+	}
+	if s.NextPath != "elliptic-curves-in-depth/fields" {
+		t.Errorf("next_path = %q, want the first unfinished part", s.NextPath)
+	}
+	if summary.Series[1].Slug != "blockchain-101" || summary.Series[1].Percent != 0 {
+		t.Errorf("series = %+v", summary.Series[1])
+	}
+
+	if len(summary.Posts) != 1 || summary.Posts[0].Path != "essays/alone" {
+		t.Errorf("posts = %+v, want the standalone post", summary.Posts)
+	}
+}
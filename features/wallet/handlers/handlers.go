@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"arkana/features/wallet/middlewares"
 	"arkana/features/wallet/services"
+	"net/http"
 
 	"github.com/gorilla/mux"
 )
 
-func RegisterRoutes(router *mux.Router, ws *services.WalletService) {
+func RegisterRoutes(router *mux.Router, ws *services.WalletService, auth *middlewares.AuthMiddleware) {
 	loginHandler := NewLoginHandler(ws)
+	profileHandler := NewProfileHandler(ws)
 
 	router.HandleFunc("/api/login", loginHandler.Login).Methods("POST")
+	router.Handle("/api/wallets/me/profile", auth.RequireAuth(http.HandlerFunc(profileHandler.UpdateProfile))).Methods("PUT", "OPTIONS")
 }
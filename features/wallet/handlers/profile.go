@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"arkana/features/wallet/middlewares"
+	"arkana/features/wallet/models"
+	"arkana/features/wallet/services"
+	"arkana/shared/httputil"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+type ProfileHandler struct {
+	walletService *services.WalletService
+}
+
+func NewProfileHandler(ws *services.WalletService) *ProfileHandler {
+	return &ProfileHandler{walletService: ws}
+}
+
+// UpdateProfile handles PUT /api/wallets/me/profile, signed with the
+// UPDATE_PROFILE action.
+func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	vr, ok := middlewares.GetVerifiedRequest(r.Context())
+	if !ok {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if vr.Action != "UPDATE_PROFILE" {
+		httputil.WriteError(w, http.StatusBadRequest, "unexpected action")
+		return
+	}
+
+	var payload struct {
+		DisplayName *string `json:"display_name,omitempty"`
+		HideLikes   *bool   `json:"hide_likes,omitempty"`
+	}
+	if err := json.Unmarshal(vr.Payload, &payload); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	wallet, err := h.walletService.UpdateProfile(vr.WalletID, payload.DisplayName, payload.HideLikes)
+	if err != nil {
+		if errors.Is(err, services.ErrDisplayNameTooLong) {
+			httputil.WriteError(w, http.StatusBadRequest, fmt.Sprintf("display name exceeds maximum length of %d characters", services.MaxDisplayNameLength))
+			return
+		}
+		log.Printf("[Profile] Failed to update wallet %d: %v", vr.WalletID, err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to update profile")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, models.UpdateProfileResponse{Wallet: *wallet})
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 )
 
 type contextKey string
@@ -18,17 +19,27 @@ type VerifiedRequest struct {
 	WalletID int
 	Address  string
 	System   string
+	Action   string
 	Payload  json.RawMessage
 }
 
 type AuthMiddleware struct {
 	walletService *services.WalletService
+	admins        map[string]bool
 }
 
-func NewAuthMiddleware(ws *services.WalletService) *AuthMiddleware {
-	return &AuthMiddleware{walletService: ws}
+// NewAuthMiddleware creates the auth middleware. Wallets listed in
+// adminAddresses are allowed through RequireAdmin.
+func NewAuthMiddleware(ws *services.WalletService, adminAddresses []string) *AuthMiddleware {
+	admins := make(map[string]bool, len(adminAddresses))
+	for _, addr := range adminAddresses {
+		admins[strings.ToLower(addr)] = true
+	}
+	return &AuthMiddleware{walletService: ws, admins: admins}
 }
 
+// RequireAuth verifies the compact JWS sent as the request body, or in an
+// "Authorization: Bearer <jws>" header for requests without a body (e.g. GET).
 func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		body, err := io.ReadAll(r.Body)
@@ -38,7 +49,12 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 		}
 		defer r.Body.Close()
 
-		envelope, err := services.ParseCompactJWS(string(body))
+		token := string(body)
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && len(body) == 0 {
+			token = strings.TrimSpace(bearer)
+		}
+
+		envelope, err := services.ParseCompactJWS(token)
 		if err != nil {
 			httputil.WriteError(w, http.StatusBadRequest, err.Error())
 			return
@@ -60,12 +76,45 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 			WalletID: wallet.ID,
 			Address:  wallet.Address,
 			System:   verified.Header.System,
+			Action:   verified.Action,
 			Payload:  verified.Payload,
 		})
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// OptionalAuth verifies the "Authorization: Bearer <jws>" header like
+// RequireAuth when it is present, and lets anonymous requests through, so
+// that public reads can tailor their response to the signed-in wallet.
+func (m *AuthMiddleware) OptionalAuth(next http.Handler) http.Handler {
+	required := m.RequireAuth(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		required.ServeHTTP(w, r)
+	})
+}
+
+// RequireAdmin behaves like RequireAuth and additionally rejects wallets
+// that are not configured as admins.
+func (m *AuthMiddleware) RequireAdmin(next http.Handler) http.Handler {
+	return m.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vr, ok := GetVerifiedRequest(r.Context())
+		if !ok || !m.IsAdmin(vr.Address) {
+			httputil.WriteError(w, http.StatusForbidden, "forbidden")
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
+
+// IsAdmin reports whether the address belongs to a configured admin wallet.
+func (m *AuthMiddleware) IsAdmin(address string) bool {
+	return m.admins[strings.ToLower(address)]
+}
+
 // GetVerifiedRequest extracts the verified JWS data from the request context.
 func GetVerifiedRequest(ctx context.Context) (*VerifiedRequest, bool) {
 	vr, ok := ctx.Value(verifiedRequestKey).(*VerifiedRequest)
@@ -3,13 +3,19 @@ package models
 import "time"
 
 type Wallet struct {
-	ID        int       `json:"id"`
-	Address   string    `json:"address"`
-	System    string    `json:"system"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int       `json:"id"`
+	Address     string    `json:"address"`
+	System      string    `json:"system"`
+	DisplayName string    `json:"display_name"`
+	HideLikes   bool      `json:"hide_likes"` // Keeps the wallet out of public like listings
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type LoginResponse struct {
 	Wallet Wallet `json:"wallet"`
 }
+
+type UpdateProfileResponse struct {
+	Wallet Wallet `json:"wallet"`
+}
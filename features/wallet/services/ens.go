@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+var ErrNameNotFound = errors.New("ENS name not found")
+
+// ensRegistry is the address of the ENS registry on mainnet.
+var ensRegistry = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// Selectors of resolver(bytes32) on the registry and addr(bytes32) on
+// resolvers.
+var (
+	selectorResolver = []byte{0x01, 0x78, 0xb8, 0xbf}
+	selectorAddr     = []byte{0x3b, 0x3b, 0x57, 0xde}
+)
+
+// maxENSCacheEntries bounds the number of names kept by an ENSResolver.
+const maxENSCacheEntries = 10000
+
+// ENSResolver resolves ENS names to addresses through an Ethereum JSON-RPC
+// endpoint. Results, including missing names, are cached for ttl, and up to
+// maxENSCacheEntries names are kept.
+type ENSResolver struct {
+	client *ethclient.Client
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]ensEntry
+}
+
+type ensEntry struct {
+	address string
+	expires time.Time
+}
+
+func NewENSResolver(rpcURL string, ttl time.Duration) (*ENSResolver, error) {
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	return &ENSResolver{client: client, ttl: ttl, cache: map[string]ensEntry{}}, nil
+}
+
+// ResolveName returns the lowercased address an ENS name points to.
+// Returns ErrNameNotFound if the name has no address.
+func (r *ENSResolver) ResolveName(ctx context.Context, name string) (string, error) {
+	name = strings.ToLower(name)
+
+	r.mu.Lock()
+	entry, ok := r.cache[name]
+	r.mu.Unlock()
+	if !ok || time.Now().After(entry.expires) {
+		address, err := r.lookup(ctx, name)
+		if err != nil && !errors.Is(err, ErrNameNotFound) {
+			return "", err
+		}
+		entry = ensEntry{address: address, expires: time.Now().Add(r.ttl)}
+		r.mu.Lock()
+		if _, cached := r.cache[name]; !cached && len(r.cache) >= maxENSCacheEntries {
+			r.evict()
+		}
+		r.cache[name] = entry
+		r.mu.Unlock()
+	}
+
+	if entry.address == "" {
+		return "", ErrNameNotFound
+	}
+	return entry.address, nil
+}
+
+// evict drops the expired entries or, when none has expired, the entry
+// closest to expiry. Callers must hold r.mu.
+func (r *ENSResolver) evict() {
+	now := time.Now()
+	var oldest string
+	var oldestExpires time.Time
+	for name, entry := range r.cache {
+		if now.After(entry.expires) {
+			delete(r.cache, name)
+			continue
+		}
+		if oldest == "" || entry.expires.Before(oldestExpires) {
+			oldest, oldestExpires = name, entry.expires
+		}
+	}
+	if len(r.cache) >= maxENSCacheEntries {
+		delete(r.cache, oldest)
+	}
+}
+
+func (r *ENSResolver) lookup(ctx context.Context, name string) (string, error) {
+	node := namehash(name)
+
+	resolver, err := r.call(ctx, ensRegistry, selectorResolver, node)
+	if err != nil {
+		return "", err
+	}
+	if resolver == (common.Address{}) {
+		return "", ErrNameNotFound
+	}
+
+	address, err := r.call(ctx, resolver, selectorAddr, node)
+	if err != nil {
+		return "", err
+	}
+	if address == (common.Address{}) {
+		return "", ErrNameNotFound
+	}
+	return strings.ToLower(address.Hex()), nil
+}
+
+// call invokes a contract function taking a node and returning an address.
+func (r *ENSResolver) call(ctx context.Context, to common.Address, selector []byte, node common.Hash) (common.Address, error) {
+	data := append(append([]byte{}, selector...), node[:]...)
+	out, err := r.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(out) < 32 {
+		return common.Address{}, nil
+	}
+	return common.BytesToAddress(out[12:32]), nil
+}
+
+// namehash implements the ENS name hashing algorithm (EIP-137).
+func namehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := crypto.Keccak256Hash([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node[:], label[:])
+	}
+	return node
+}
@@ -26,6 +26,7 @@ type JWSHeader struct {
 // VerifiedJWS is the result of a successful JWS verification.
 type VerifiedJWS struct {
 	Header  JWSHeader
+	Action  string
 	Address string
 	Payload json.RawMessage
 }
@@ -106,6 +107,7 @@ func VerifyJWS(envelope *JWSEnvelope) (*VerifiedJWS, error) {
 
 	return &VerifiedJWS{
 		Header:  header,
+		Action:  base.Action,
 		Address: strings.ToLower(base.Address),
 		Payload: json.RawMessage(payloadBytes),
 	}, nil
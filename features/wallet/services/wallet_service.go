@@ -3,9 +3,16 @@ package services
 import (
 	"arkana/features/wallet/models"
 	"database/sql"
+	"errors"
 	"strings"
+	"unicode/utf8"
 )
 
+// MaxDisplayNameLength is the maximum number of characters in a display name.
+const MaxDisplayNameLength = 50
+
+var ErrDisplayNameTooLong = errors.New("display name is too long")
+
 type WalletService struct {
 	db *sql.DB
 }
@@ -47,9 +54,9 @@ func (s *WalletService) GetByAddress(address string) (*models.Wallet, error) {
 	address = strings.ToLower(address)
 	var w models.Wallet
 	err := s.db.QueryRow(
-		"SELECT id, address, system, created_at, updated_at FROM wallets WHERE address = ?",
+		"SELECT "+walletColumns+" FROM wallets WHERE address = ?",
 		address,
-	).Scan(&w.ID, &w.Address, &w.System, &w.CreatedAt, &w.UpdatedAt)
+	).Scan(&w.ID, &w.Address, &w.System, &w.DisplayName, &w.HideLikes, &w.CreatedAt, &w.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -60,11 +67,39 @@ func (s *WalletService) GetByAddress(address string) (*models.Wallet, error) {
 func (s *WalletService) GetByID(id int) (*models.Wallet, error) {
 	var w models.Wallet
 	err := s.db.QueryRow(
-		"SELECT id, address, system, created_at, updated_at FROM wallets WHERE id = ?",
+		"SELECT "+walletColumns+" FROM wallets WHERE id = ?",
 		id,
-	).Scan(&w.ID, &w.Address, &w.System, &w.CreatedAt, &w.UpdatedAt)
+	).Scan(&w.ID, &w.Address, &w.System, &w.DisplayName, &w.HideLikes, &w.CreatedAt, &w.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return &w, nil
 }
+
+// walletColumns is the column list scanned into models.Wallet.
+const walletColumns = "id, address, system, display_name, hide_likes, created_at, updated_at"
+
+// UpdateProfile changes the public profile of a wallet. Nil fields are left
+// unchanged.
+func (s *WalletService) UpdateProfile(id int, displayName *string, hideLikes *bool) (*models.Wallet, error) {
+	if displayName != nil {
+		name := strings.TrimSpace(*displayName)
+		if utf8.RuneCountInString(name) > MaxDisplayNameLength {
+			return nil, ErrDisplayNameTooLong
+		}
+		displayName = &name
+	}
+
+	_, err := s.db.Exec(`
+		UPDATE wallets SET
+			display_name = COALESCE(?, display_name),
+			hide_likes = COALESCE(?, hide_likes),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, displayName, hideLikes, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(id)
+}
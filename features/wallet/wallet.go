@@ -9,10 +9,11 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func Initialize(router *mux.Router, db *sql.DB) *middlewares.AuthMiddleware {
+func Initialize(router *mux.Router, db *sql.DB, adminWallets []string) *middlewares.AuthMiddleware {
 	walletService := services.NewWalletService(db)
+	auth := middlewares.NewAuthMiddleware(walletService, adminWallets)
 
-	handlers.RegisterRoutes(router, walletService)
+	handlers.RegisterRoutes(router, walletService, auth)
 
-	return middlewares.NewAuthMiddleware(walletService)
+	return auth
 }
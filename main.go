@@ -16,8 +16,6 @@ import (
 )
 
 func main() {
-	log.Println("Starting server...")
-
 	// Load and validate configuration
 	cfg, err := config.LoadAndValidate()
 	if err != nil {
@@ -37,8 +35,22 @@ func main() {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
+	// Run a one-off command instead of the server if one was given
+	if len(os.Args) > 1 {
+		if err := runCommand(cfg, db, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	log.Println("Starting server...")
+
+	// Background jobs run until shutdown
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
 	// Setup router with all routes
-	r := router.Setup(db, cfg.CORSAllowedOrigin)
+	r := router.Setup(jobsCtx, db, cfg)
 
 	srv := &http.Server{
 		Addr:    ":8082",
@@ -59,6 +71,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE series (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    slug TEXT UNIQUE NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_series_slug ON series(slug);
+
+ALTER TABLE posts ADD COLUMN series_id INTEGER REFERENCES series(id);
+ALTER TABLE posts ADD COLUMN series_position INTEGER;
+CREATE INDEX idx_posts_series ON posts(series_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_posts_series;
+ALTER TABLE posts DROP COLUMN series_position;
+ALTER TABLE posts DROP COLUMN series_id;
+DROP INDEX IF EXISTS idx_series_slug;
+DROP TABLE IF EXISTS series;
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE post_metadata (
+    post_id INTEGER PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    locale TEXT NOT NULL DEFAULT '',
+    published_at TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+);
+
+CREATE TABLE post_tags (
+    post_id INTEGER NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (post_id, tag),
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+);
+CREATE INDEX idx_post_tags_tag ON post_tags(tag);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_post_tags_tag;
+DROP TABLE IF EXISTS post_tags;
+DROP TABLE IF EXISTS post_metadata;
@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE post_metadata ADD COLUMN summary TEXT NOT NULL DEFAULT '';
+ALTER TABLE post_metadata ADD COLUMN updated_at TIMESTAMP;
+ALTER TABLE post_metadata ADD COLUMN reading_time INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX idx_post_metadata_published ON post_metadata(published_at);
+
+CREATE TABLE post_authors (
+    post_id INTEGER NOT NULL,
+    wallet_id INTEGER NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, wallet_id),
+    FOREIGN KEY (post_id) REFERENCES posts(id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+CREATE INDEX idx_post_authors_wallet ON post_authors(wallet_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_post_authors_wallet;
+DROP TABLE IF EXISTS post_authors;
+DROP INDEX IF EXISTS idx_post_metadata_published;
+ALTER TABLE post_metadata DROP COLUMN reading_time;
+ALTER TABLE post_metadata DROP COLUMN updated_at;
+ALTER TABLE post_metadata DROP COLUMN summary;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE post_locales (
+    post_id INTEGER NOT NULL,
+    locale TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, locale),
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+);
+
+INSERT INTO post_locales (post_id, locale, title)
+SELECT post_id, locale, title FROM post_metadata WHERE locale != '';
+
+ALTER TABLE comments ADD COLUMN locale TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE comments DROP COLUMN locale;
+DROP TABLE IF EXISTS post_locales;
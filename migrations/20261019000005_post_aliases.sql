@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE post_aliases (
+    path_identifier TEXT PRIMARY KEY,
+    post_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+);
+CREATE INDEX idx_post_aliases_post ON post_aliases(post_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_post_aliases_post;
+DROP TABLE IF EXISTS post_aliases;
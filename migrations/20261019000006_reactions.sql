@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE post_reactions (
+    post_id INTEGER NOT NULL,
+    wallet_id INTEGER NOT NULL,
+    reaction TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, wallet_id, reaction),
+    FOREIGN KEY (post_id) REFERENCES posts(id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+
+CREATE TABLE post_reaction_counts (
+    post_id INTEGER NOT NULL,
+    reaction TEXT NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, reaction),
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS post_reaction_counts;
+DROP TABLE IF EXISTS post_reactions;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE idempotency_keys (
+    wallet_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    response BLOB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (wallet_id, key),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+CREATE INDEX idx_idempotency_keys_created ON idempotency_keys(created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_idempotency_keys_created;
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE wallets ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE wallets ADD COLUMN hide_likes INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX idx_post_likes_wallet ON post_likes(wallet_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_post_likes_wallet;
+ALTER TABLE wallets DROP COLUMN hide_likes;
+ALTER TABLE wallets DROP COLUMN display_name;
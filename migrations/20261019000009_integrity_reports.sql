@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE integrity_reports (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    checked_at TIMESTAMP NOT NULL,
+    fixed INTEGER NOT NULL DEFAULT 0,
+    discrepancies INTEGER NOT NULL DEFAULT 0,
+    report TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS integrity_reports;
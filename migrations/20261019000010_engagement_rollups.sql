@@ -0,0 +1,44 @@
+-- +goose Up
+CREATE TABLE post_like_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    post_id INTEGER NOT NULL,
+    wallet_id INTEGER NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('like', 'unlike')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+CREATE INDEX idx_post_like_events_created ON post_like_events(created_at);
+
+INSERT INTO post_like_events (post_id, wallet_id, kind, created_at)
+SELECT post_id, wallet_id, 'like', created_at FROM post_likes;
+
+CREATE TABLE post_engagement_rollups (
+    post_id INTEGER NOT NULL,
+    interval TEXT NOT NULL,
+    bucket TEXT NOT NULL,
+    likes INTEGER NOT NULL DEFAULT 0,
+    unlikes INTEGER NOT NULL DEFAULT 0,
+    comments INTEGER NOT NULL DEFAULT 0,
+    engaged_wallets INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, interval, bucket),
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+);
+
+CREATE TABLE series_engagement_rollups (
+    series_id INTEGER NOT NULL,
+    interval TEXT NOT NULL,
+    bucket TEXT NOT NULL,
+    likes INTEGER NOT NULL DEFAULT 0,
+    unlikes INTEGER NOT NULL DEFAULT 0,
+    comments INTEGER NOT NULL DEFAULT 0,
+    engaged_wallets INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (series_id, interval, bucket),
+    FOREIGN KEY (series_id) REFERENCES series(id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS series_engagement_rollups;
+DROP TABLE IF EXISTS post_engagement_rollups;
+DROP INDEX IF EXISTS idx_post_like_events_created;
+DROP TABLE IF EXISTS post_like_events;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE post_related (
+    post_id INTEGER NOT NULL,
+    related_post_id INTEGER NOT NULL,
+    rank INTEGER NOT NULL,
+    score REAL NOT NULL,
+    support INTEGER NOT NULL,
+    PRIMARY KEY (post_id, related_post_id),
+    FOREIGN KEY (post_id) REFERENCES posts(id),
+    FOREIGN KEY (related_post_id) REFERENCES posts(id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS post_related;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- view_salts holds the salt of the current day only; older salts are
+-- deleted so that visitor hashes cannot be recomputed afterwards
+CREATE TABLE view_salts (
+    day TEXT PRIMARY KEY,
+    salt BLOB NOT NULL
+);
+
+CREATE TABLE post_view_visitors (
+    post_id INTEGER NOT NULL,
+    day TEXT NOT NULL,
+    visitor_hash TEXT NOT NULL,
+    PRIMARY KEY (post_id, day, visitor_hash),
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+);
+
+CREATE TABLE post_daily_views (
+    post_id INTEGER NOT NULL,
+    day TEXT NOT NULL,
+    views INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, day),
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS post_daily_views;
+DROP TABLE IF EXISTS post_view_visitors;
+DROP TABLE IF EXISTS view_salts;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE reading_progress (
+    wallet_id INTEGER NOT NULL,
+    post_id INTEGER NOT NULL,
+    position REAL NOT NULL DEFAULT 0,
+    section TEXT NOT NULL DEFAULT '',
+    completed_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (wallet_id, post_id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id),
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+);
+CREATE INDEX idx_reading_progress_post ON reading_progress(post_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS reading_progress;
@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE bookmarks (
+    wallet_id INTEGER NOT NULL,
+    post_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (wallet_id, post_id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id),
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+);
+CREATE INDEX idx_bookmarks_post ON bookmarks(post_id);
+
+CREATE TABLE reading_lists (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    wallet_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    share_token TEXT UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (wallet_id, name),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+
+CREATE TABLE reading_list_items (
+    list_id INTEGER NOT NULL,
+    post_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (list_id, post_id),
+    FOREIGN KEY (list_id) REFERENCES reading_lists(id),
+    FOREIGN KEY (post_id) REFERENCES posts(id)
+);
+CREATE INDEX idx_reading_list_items_post ON reading_list_items(post_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS reading_list_items;
+DROP TABLE IF EXISTS reading_lists;
+DROP TABLE IF EXISTS bookmarks;
@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE comments ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE comments ADD COLUMN edited_at TIMESTAMP;
+
+-- comment_revisions keeps every replaced body of a comment; the current
+-- body stays on the comment itself
+CREATE TABLE comment_revisions (
+    comment_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (comment_id, revision),
+    FOREIGN KEY (comment_id) REFERENCES comments(id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS comment_revisions;
+ALTER TABLE comments DROP COLUMN edited_at;
+ALTER TABLE comments DROP COLUMN revision;
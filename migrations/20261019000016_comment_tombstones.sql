@@ -0,0 +1,10 @@
+-- +goose Up
+-- A deleted comment keeps its row, with an empty body, while it has replies
+ALTER TABLE comments ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE comments ADD COLUMN deleted_by TEXT CHECK (deleted_by IN ('author', 'admin'));
+CREATE INDEX idx_comments_parent ON comments(parent_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_comments_parent;
+ALTER TABLE comments DROP COLUMN deleted_by;
+ALTER TABLE comments DROP COLUMN deleted_at;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- body_html caches the sanitized rendering of the Markdown body. Rows whose
+-- render_version is older than the renderer's are re-rendered at startup.
+ALTER TABLE comments ADD COLUMN body_html TEXT NOT NULL DEFAULT '';
+ALTER TABLE comments ADD COLUMN render_version INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE comments DROP COLUMN render_version;
+ALTER TABLE comments DROP COLUMN body_html;
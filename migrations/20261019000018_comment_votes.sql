@@ -0,0 +1,24 @@
+-- +goose Up
+-- One vote per wallet and comment; value is 1 for up and -1 for down
+CREATE TABLE comment_votes (
+    comment_id INTEGER NOT NULL,
+    wallet_id INTEGER NOT NULL,
+    value INTEGER NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (comment_id, wallet_id),
+    FOREIGN KEY (comment_id) REFERENCES comments(id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+
+-- Denormalized vote counts; score is the Wilson lower bound of the share
+-- of upvotes, computed by the application
+ALTER TABLE comments ADD COLUMN upvotes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN downvotes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN score REAL NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE comments DROP COLUMN score;
+ALTER TABLE comments DROP COLUMN downvotes;
+ALTER TABLE comments DROP COLUMN upvotes;
+DROP TABLE IF EXISTS comment_votes;
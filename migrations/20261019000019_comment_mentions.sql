@@ -0,0 +1,17 @@
+-- +goose Up
+-- Wallets mentioned in comment bodies; position and length locate the
+-- mention in the Markdown body, in bytes
+CREATE TABLE comment_mentions (
+    comment_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    length INTEGER NOT NULL,
+    wallet_id INTEGER NOT NULL,
+    PRIMARY KEY (comment_id, position),
+    FOREIGN KEY (comment_id) REFERENCES comments(id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+CREATE INDEX idx_comment_mentions_wallet ON comment_mentions(wallet_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_comment_mentions_wallet;
+DROP TABLE IF EXISTS comment_mentions;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- One row per event and recipient. Repeating an event, e.g. upvoting a
+-- comment again after withdrawing the vote, doesn't notify twice.
+CREATE TABLE notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    wallet_id INTEGER NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('reply', 'mention', 'reaction')),
+    actor_id INTEGER NOT NULL,
+    comment_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    read_at TIMESTAMP,
+    UNIQUE (wallet_id, kind, actor_id, comment_id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id),
+    FOREIGN KEY (actor_id) REFERENCES wallets(id),
+    FOREIGN KEY (comment_id) REFERENCES comments(id)
+);
+CREATE INDEX idx_notifications_wallet ON notifications(wallet_id, id);
+CREATE INDEX idx_notifications_comment ON notifications(comment_id);
+
+-- Kinds a wallet turned off; every kind is on by default
+CREATE TABLE notification_preferences (
+    wallet_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    enabled INTEGER NOT NULL,
+    PRIMARY KEY (wallet_id, kind),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_preferences;
+DROP INDEX IF EXISTS idx_notifications_comment;
+DROP INDEX IF EXISTS idx_notifications_wallet;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,51 @@
+-- +goose Up
+-- Hidden comments are left out of listings for everyone but their author
+-- and moderators
+ALTER TABLE comments ADD COLUMN status TEXT NOT NULL DEFAULT 'visible' CHECK (status IN ('visible', 'hidden'));
+
+-- One report per wallet and comment. Reports are resolved by the next
+-- moderator action on the comment; open reports count towards hiding it.
+CREATE TABLE comment_reports (
+    comment_id INTEGER NOT NULL,
+    wallet_id INTEGER NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('spam', 'harassment', 'hate', 'misinformation', 'off_topic', 'other')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP,
+    PRIMARY KEY (comment_id, wallet_id),
+    FOREIGN KEY (comment_id) REFERENCES comments(id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+CREATE INDEX idx_comment_reports_open ON comment_reports(resolved_at, comment_id);
+
+-- The moderation log outlives purged comments, so comment_id is not a
+-- foreign key. moderator_id is NULL for comments hidden by reports.
+CREATE TABLE comment_moderation_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    comment_id INTEGER NOT NULL,
+    author_id INTEGER NOT NULL,
+    moderator_id INTEGER,
+    action TEXT NOT NULL CHECK (action IN ('hide', 'restore', 'delete', 'ban')),
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (author_id) REFERENCES wallets(id),
+    FOREIGN KEY (moderator_id) REFERENCES wallets(id)
+);
+CREATE INDEX idx_comment_moderation_log_comment ON comment_moderation_log(comment_id);
+
+-- Banned wallets can no longer comment, vote on or report comments
+CREATE TABLE comment_bans (
+    wallet_id INTEGER PRIMARY KEY,
+    moderator_id INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id),
+    FOREIGN KEY (moderator_id) REFERENCES wallets(id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS comment_bans;
+DROP INDEX IF EXISTS idx_comment_moderation_log_comment;
+DROP TABLE IF EXISTS comment_moderation_log;
+DROP INDEX IF EXISTS idx_comment_reports_open;
+DROP TABLE IF EXISTS comment_reports;
+ALTER TABLE comments DROP COLUMN status;
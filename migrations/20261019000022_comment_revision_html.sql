@@ -0,0 +1,9 @@
+-- +goose Up
+-- Revisions cache their rendering like comments do, so that the history is
+-- not re-rendered on every request. Existing rows are rendered at startup.
+ALTER TABLE comment_revisions ADD COLUMN body_html TEXT NOT NULL DEFAULT '';
+ALTER TABLE comment_revisions ADD COLUMN render_version INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE comment_revisions DROP COLUMN render_version;
+ALTER TABLE comment_revisions DROP COLUMN body_html;
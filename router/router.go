@@ -1,24 +1,39 @@
 package router
 
 import (
+	"arkana/config"
+	"arkana/features/bookmarks"
+	"arkana/features/notifications"
 	"arkana/features/posts"
+	"arkana/features/progress"
 	"arkana/features/wallet"
+	"context"
 	"database/sql"
 
 	"github.com/gorilla/mux"
 )
 
-// Setup initializes the router and registers all routes
-func Setup(db *sql.DB, corsOrigin string) *mux.Router {
+// Setup initializes the router and registers all routes. Background jobs
+// started by the modules stop when ctx is cancelled.
+func Setup(ctx context.Context, db *sql.DB, cfg *config.Config) *mux.Router {
 	router := mux.NewRouter()
 
-	router.Use(CORSMiddleware(corsOrigin))
+	router.Use(CORSMiddleware(cfg.CORSAllowedOrigin))
 
 	// Initialize wallet module (returns auth middleware for other modules)
-	auth := wallet.Initialize(router, db)
+	auth := wallet.Initialize(router, db, cfg.AdminWallets)
 
-	// Initialize posts module
-	posts.Initialize(router, db, auth)
+	// Initialize posts module (returns the post and comment services for other modules)
+	postService, commentService := posts.Initialize(ctx, router, db, cfg, auth)
+
+	// Initialize reading progress module
+	progress.Initialize(router, db, postService, auth)
+
+	// Initialize bookmarks and reading lists module
+	bookmarks.Initialize(router, db, postService, auth)
+
+	// Initialize notifications module
+	notifications.Initialize(router, db, commentService, auth)
 
 	return router
 }
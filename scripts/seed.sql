@@ -1,5 +1,6 @@
 -- Seed script: creates all posts from content folder
 -- Run with: make seed
+-- Prefer `make sync MANIFEST=<source>`, which also records titles, tags and series
 
 -- blockchain-101
 INSERT OR IGNORE INTO posts (path_identifier, like_count) VALUES
@@ -0,0 +1,33 @@
+package httputil
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+var ErrInvalidPagination = errors.New("invalid pagination parameters")
+
+// ParsePagination reads the limit and offset query parameters. A missing
+// limit falls back to defaultLimit and larger limits are capped at maxLimit.
+func ParsePagination(r *http.Request, defaultLimit, maxLimit int) (limit, offset int, err error) {
+	limit = defaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return 0, 0, ErrInvalidPagination
+		}
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	if v := r.URL.Query().Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return 0, 0, ErrInvalidPagination
+		}
+	}
+
+	return limit, offset, nil
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Every runs fn in the background once right away, then every interval
+// until ctx is cancelled. Failures are logged and do not stop the schedule.
+// A non-positive interval disables the job.
+func Every(ctx context.Context, name string, interval time.Duration, fn func() error) {
+	if interval <= 0 {
+		log.Printf("[Jobs] %s disabled", name)
+		return
+	}
+
+	log.Printf("[Jobs] %s scheduled every %s", name, interval)
+
+	run := func() {
+		if err := fn(); err != nil {
+			log.Printf("[Jobs] %s failed: %v", name, err)
+		}
+	}
+
+	go func() {
+		run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}